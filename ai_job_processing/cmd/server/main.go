@@ -0,0 +1,1254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"ai_job_processing/internal/api"
+	"ai_job_processing/internal/batch"
+	"ai_job_processing/internal/config"
+	"ai_job_processing/internal/db"
+	"ai_job_processing/internal/gemini"
+	"ai_job_processing/internal/logger"
+	"ai_job_processing/internal/models"
+	"ai_job_processing/internal/processor"
+	"ai_job_processing/internal/store"
+	"ai_job_processing/internal/worker"
+	"llmkit/llm"
+	"llmkit/llmcache"
+	"llmkit/llmusage"
+)
+
+var (
+	version = "1.0.0"
+	commit  = "dev"
+)
+
+func main() {
+	// Load .env file if present
+	godotenv.Load()
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Initialize logger
+	log := logger.New(&logger.Config{
+		Level:  logger.Level(cfg.LogLevel),
+		Format: logger.Format(cfg.LogFormat),
+	})
+	log.SetDefault()
+
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "serve":
+		runServe(cfg, os.Args[2:])
+	case "migrate":
+		runMigrate(cfg, os.Args[2:])
+	case "worker":
+		runWorker(cfg, os.Args[2:])
+	case "queue":
+		runQueue(cfg, os.Args[2:])
+	case "batch":
+		runBatch(cfg, os.Args[2:])
+	case "extract":
+		runExtract(cfg, os.Args[2:])
+	case "embed":
+		runEmbed(cfg, os.Args[2:])
+	case "cache":
+		runCache(cfg, os.Args[2:])
+	case "usage":
+		runUsage(cfg, os.Args[2:])
+	case "reviews":
+		runReviews(cfg, os.Args[2:])
+	case "glossary":
+		runGlossary(cfg, os.Args[2:])
+	case "eval":
+		runEval(cfg, os.Args[2:])
+	case "version":
+		fmt.Printf("ai_job_processing %s (%s)\n", version, commit)
+	case "help", "--help", "-h":
+		printUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown command '%s'\n\n", os.Args[1])
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println(`AI Job Processing - normalize and translate job descriptions with an LLM
+
+Usage:
+  server <command> [options]
+
+Commands:
+  serve     Start the HTTP API server
+  migrate   Run database migrations
+  worker    Drain the AI job queue filled by the scrapper (daemon)
+  queue     Show queue status and dead letters, or requeue dead letters
+  batch     Process jobs that were never normalized
+  extract   Extract skills and requirements of jobs without facts
+  embed     Embed normalized descriptions without a current embedding
+  cache     Show or clear the LLM answer cache
+  usage     Summarize the AI usage and cost of all services
+  reviews   List translations flagged for review, or resolve them
+  glossary  List, add or delete glossary terms enforced in translations
+  eval      Compare two versions of a prompt on a fixture set of jobs (offline)
+  version   Show version information
+  help      Show this help message
+
+Run 'server <command> --help' for command options.
+
+Environment Variables:
+  DATABASE_URL               PostgreSQL connection string (required)
+  GEMINI_API_KEY             Google Gemini API key (required for the gemini provider)
+  GEMINI_MODEL               Gemini model (default: gemini-2.0-flash)
+  LLM_PROVIDER               gemini, openai or fake (default: gemini)
+  LLM_BASE_URL               openai: endpoint base URL (default: https://api.openai.com/v1)
+  LLM_API_KEY                openai: bearer token (optional for local servers)
+  LLM_MODEL                  Model name (required for openai)
+  LLM_REPAIR_ATTEMPTS        Repairs requested for invalid model output (default: 2)
+  LLM_CACHE_TTL_HOURS        Hours validated answers stay cached (default: 720, 0 = no cache)
+  LLM_USER_DAILY_TOKENS      Tokens a user may use per day in all services (default: 0 = unlimited)
+  LLM_SERVICE_DAILY_TOKENS   Tokens this service may use per day (default: 0 = unlimited)
+  LLM_PRICE_PROMPT           USD per million prompt tokens, for cost reports (default: 0)
+  LLM_PRICE_COMPLETION       USD per million completion tokens, for cost reports (default: 0)
+  PROMPTS_DIR                Directory with prompt template versions besides the built-in ones
+  PROMPT_VERSIONS            Pinned prompt versions, e.g. translation=1 (default: newest)
+  LLM_EMBEDDING_MODEL        Embedding model (gemini default: text-embedding-004)
+  EMBEDDINGS_ENABLED         Embed descriptions of processed jobs (default: true)
+  METRICS_ADDR               worker: serve Prometheus metrics on this address
+  TARGET_LANGUAGES           Translation languages (default: de,fr,it,en)
+  GLOSSARY_TENANT            Tenant whose glossary terms apply besides the shared ones
+  LOG_LEVEL                  DEBUG, INFO, WARN, ERROR (default: INFO)
+  LOG_FORMAT                 text or json (default: json)
+  QUEUE_WORKERS              Queue items processed concurrently (default: 4)
+  QUEUE_POLL_SECONDS         Wait between polls of an empty queue (default: 5)
+  QUEUE_VISIBILITY_SECONDS   Claim timeout before an item is retried elsewhere (default: 600)
+  QUEUE_RETRY_BASE_SECONDS   First retry delay, doubled per attempt (default: 30)
+  QUEUE_RETENTION_DAYS       Days to keep done items (default: 7, 0 = keep)
+  BATCH_CONCURRENCY          Jobs processed concurrently per batch (default: 4)
+  HOST                       serve: listen host (default: 0.0.0.0)
+  PORT                       serve: listen port (default: 8081)`)
+}
+
+func runServe(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", net.JoinHostPort(cfg.Host, cfg.Port), "Listen address")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 60*time.Second, "Time to finish requests, batches and model calls in progress on shutdown")
+
+	fs.Usage = func() {
+		fmt.Println(`Usage: server serve [options]
+
+Serves the processing API, /health (liveness), /ready (database and LLM provider
+reachable) and /metrics. Run 'server migrate' first. On SIGTERM or Ctrl+C the server
+stops accepting requests and waits for requests, batches and model calls in progress
+before it exits.
+
+Options:`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	database, err := db.NewDB(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	geminiClient, err := newGeminiClient(ctx, cfg, database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer geminiClient.Close()
+
+	proc := processor.NewProcessor(geminiClient, store.NewStore(database), cfg.TargetLanguages, cfg.EmbeddingsEnabled, cfg.GlossaryTenant)
+	batches := batch.NewManager(proc, cfg.BatchConcurrency)
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to listen on %s: %v\n", *addr, err)
+		os.Exit(1)
+	}
+
+	srv := &http.Server{
+		Handler:           api.NewRouter(proc, batches, llmusage.New(database, cfg.Usage), cfg, version),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      5 * time.Minute, // Processing a job takes a model call per language
+		IdleTimeout:       60 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+
+	slog.Info("starting AI job processing server",
+		"addr", ln.Addr().String(),
+		"version", version,
+		"llm_provider", geminiClient.Provider().Name(),
+		"llm_model", geminiClient.Provider().Model(),
+		"target_languages", cfg.TargetLanguages,
+	)
+
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	stop() // A second Ctrl+C exits immediately
+
+	slog.Info("shutting down server",
+		"timeout", shutdownTimeout.String(),
+		"llm_calls_in_flight", geminiClient.InFlight(),
+	)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+
+	// Stop accepting requests and wait for handlers in progress, then stop the running batch
+	// and wait for its jobs, then for model calls that outlived their request
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("requests still in progress at shutdown", "error", err)
+	}
+	if err := batches.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("batch jobs still in progress at shutdown", "error", err)
+	}
+	if err := geminiClient.Drain(shutdownCtx); err != nil {
+		slog.Warn("model calls still in progress at shutdown", "error", err)
+	}
+
+	slog.Info("server stopped")
+}
+
+func runMigrate(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	direction := fs.String("direction", "up", "Migration direction: up, down")
+	steps := fs.Int("steps", 0, "Number of migrations to run (0 = all)")
+	force := fs.Int("force", -1, "Force migration version (for recovery)")
+
+	fs.Usage = func() {
+		fmt.Println(`Usage: server migrate [options]
+
+Applies the migrations in ./migrations. They extend the scrapper's tables, so run the
+scrapper's migrations first. Applied versions are recorded in their own table,
+ai_job_processing_migrations, since the other services share schema_migrations.
+
+Options:`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	if *direction != "up" && *direction != "down" {
+		fmt.Fprintf(os.Stderr, "Error: Invalid direction '%s'. Use 'up' or 'down'.\n", *direction)
+		os.Exit(1)
+	}
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: DATABASE_URL is required. Set via environment variable or --database flag.")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Running migrations (%s)...\n", *direction)
+
+	migrator, err := db.NewMigrator(*databaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create migrator: %v\n", err)
+		os.Exit(1)
+	}
+	defer migrator.Close()
+
+	// Force version if specified
+	if *force >= 0 {
+		fmt.Printf("Forcing migration version to %d...\n", *force)
+		if err := migrator.Force(*force); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to force migration version: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Migration version forced successfully.")
+		return
+	}
+
+	// Run migrations
+	if *steps != 0 {
+		n := *steps
+		if *direction == "down" {
+			n = -n
+		}
+		if err := migrator.Steps(n); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Migration failed: %v\n", err)
+			os.Exit(1)
+		}
+	} else if *direction == "down" {
+		if err := migrator.Down(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Migration down failed: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		if err := migrator.Up(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Migration up failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Show current version
+	ver, dirty, err := migrator.Version()
+	if err != nil {
+		fmt.Printf("Migrations applied successfully.\n")
+	} else {
+		dirtyStr := ""
+		if dirty {
+			dirtyStr = " (dirty)"
+		}
+		fmt.Printf("Migrations applied successfully. Current version: %d%s\n", ver, dirtyStr)
+	}
+}
+
+func runWorker(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("worker", flag.ExitOnError)
+	workers := fs.Int("workers", cfg.QueueWorkers, "Queue items processed concurrently")
+	poll := fs.Duration("poll", time.Duration(cfg.QueuePollSeconds)*time.Second, "Wait between polls of an empty queue")
+	visibility := fs.Duration("visibility", time.Duration(cfg.QueueVisibilitySeconds)*time.Second, "Claim timeout before an item is retried by another worker")
+	id := fs.String("id", "", "Worker ID recorded on claimed items (default: hostname-pid)")
+	metricsAddr := fs.String("metrics-addr", cfg.MetricsAddr, "Serve Prometheus metrics on this address, e.g. :9090 (empty = disabled)")
+
+	fs.Usage = func() {
+		fmt.Println(`Usage: server worker [options]
+
+Claims jobs the scrapper queued in ai_job_queue and processes them in the queued mode
+(process, normalize or translate). Any number of workers can share the queue. Failed items
+are retried with exponential backoff and dead-lettered after their last attempt.
+Stop with Ctrl+C; items in progress are finished first.
+
+Options:`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	database, err := db.NewDB(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	geminiClient, err := newGeminiClient(ctx, cfg, database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer geminiClient.Close()
+
+	if *metricsAddr != "" {
+		if err := serveMetrics(ctx, *metricsAddr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	st := store.NewStore(database)
+	proc := processor.NewProcessor(geminiClient, st, cfg.TargetLanguages, cfg.EmbeddingsEnabled, cfg.GlossaryTenant)
+
+	w := worker.New(proc, st, worker.Config{
+		ID:           *id,
+		Concurrency:  *workers,
+		PollInterval: *poll,
+		Visibility:   *visibility,
+		RetryBase:    time.Duration(cfg.QueueRetryBaseSeconds) * time.Second,
+		Retention:    time.Duration(cfg.QueueRetentionDays) * 24 * time.Hour,
+	})
+
+	slog.Info("starting queue worker",
+		"version", version,
+		"llm_provider", geminiClient.Provider().Name(),
+		"llm_model", geminiClient.Provider().Model(),
+		"target_languages", cfg.TargetLanguages,
+	)
+	if err := w.Run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runQueue(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("queue", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	limit := fs.Int("limit", 20, "Number of dead letters to list")
+	requeue := fs.String("requeue", "", "Comma-separated dead letter IDs to requeue, or 'all'")
+
+	fs.Usage = func() {
+		fmt.Println(`Usage: server queue [options]
+
+Shows how many queue items are pending, processing, done and dead, and lists the latest
+dead letters with their last error. --requeue gives dead letters a fresh set of attempts.
+
+Options:`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	var ids []int64
+	if *requeue != "" && *requeue != "all" {
+		for _, part := range strings.Split(*requeue, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: Invalid queue item ID '%s'\n", part)
+				os.Exit(1)
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	ctx := context.Background()
+
+	database, err := db.NewDB(ctx, *databaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	st := store.NewStore(database)
+
+	if *requeue != "" {
+		n, err := st.RequeueDeadLetters(ctx, ids)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Requeued %d dead letters\n", n)
+		return
+	}
+
+	stats, err := st.GetQueueStats(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Pending: %d, processing: %d, done: %d, dead: %d\n", stats.Pending, stats.Processing, stats.Done, stats.Dead)
+	if stats.OldestDue != nil {
+		fmt.Printf("Oldest pending item due: %s\n", stats.OldestDue.Format(time.RFC3339))
+	}
+	if stats.Dead == 0 {
+		return
+	}
+
+	items, err := st.ListDeadLetters(ctx, *limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tJOB\tMODE\tATTEMPTS\tDEAD SINCE\tLAST ERROR")
+	for _, item := range items {
+		lastError := ""
+		if item.LastError != nil {
+			lastError = *item.LastError
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\n", item.ID, item.JobID, item.Mode, item.Attempts, item.UpdatedAt.Format(time.RFC3339), lastError)
+	}
+	w.Flush()
+}
+
+func runBatch(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	limit := fs.Int("limit", 0, "Max jobs to process, newest first (0 = all pending)")
+	concurrency := fs.Int("concurrency", cfg.BatchConcurrency, fmt.Sprintf("Jobs processed concurrently (max %d)", batch.MaxConcurrency))
+	force := fs.Bool("force", false, "Reprocess already translated languages")
+	progress := fs.Duration("progress", 30*time.Second, "Interval of progress reports")
+
+	fs.Usage = func() {
+		fmt.Println(`Usage: server batch [options]
+
+Normalizes and translates the jobs that are pending normalization, e.g. because the AI
+service was down while they were scraped. Jobs waiting in the AI queue are left to the
+queue worker. Stop with Ctrl+C; jobs in progress are finished first.
+
+Options:`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	if *limit < 0 || *concurrency < 1 || *concurrency > batch.MaxConcurrency {
+		fmt.Fprintf(os.Stderr, "Error: --limit must not be negative and --concurrency must be between 1 and %d\n", batch.MaxConcurrency)
+		os.Exit(1)
+	}
+	if *progress <= 0 {
+		*progress = 30 * time.Second
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	database, err := db.NewDB(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	geminiClient, err := newGeminiClient(ctx, cfg, database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer geminiClient.Close()
+
+	st := store.NewStore(database)
+	proc := processor.NewProcessor(geminiClient, st, cfg.TargetLanguages, cfg.EmbeddingsEnabled, cfg.GlossaryTenant)
+	batches := batch.NewManager(proc, cfg.BatchConcurrency)
+
+	b, err := batches.Start(ctx, &models.BatchRequest{
+		Limit:       *limit,
+		Concurrency: *concurrency,
+		Force:       *force,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	status := b.Status()
+	fmt.Printf("Processing %d pending jobs (concurrency %d)\n", status.Total, status.Concurrency)
+
+	ticker := time.NewTicker(*progress)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			stop() // A second Ctrl+C exits immediately
+			fmt.Println("Stopping, finishing jobs in progress...")
+			batches.Shutdown(context.Background())
+			printBatchStatus(b.Status())
+			return
+		case <-ticker.C:
+			printBatchStatus(b.Status())
+		case <-b.Done():
+			printBatchStatus(b.Status())
+			return
+		}
+	}
+}
+
+func printBatchStatus(status models.BatchStatus) {
+	fmt.Printf("%s: %d/%d done (%d skipped), %d failed", status.Status, status.Done, status.Total, status.Skipped, status.Failed)
+	if status.ETA != nil {
+		fmt.Printf(", ETA %s", status.ETA.Format(time.RFC3339))
+	}
+	fmt.Println()
+
+	if status.FinishedAt != nil {
+		for _, e := range status.Errors {
+			fmt.Printf("  %s: %s\n", e.JobID, e.Error)
+		}
+	}
+}
+
+func runExtract(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	limit := fs.Int("limit", 0, "Max jobs to extract, newest first (0 = all)")
+
+	fs.Usage = func() {
+		fmt.Println(`Usage: server extract [options]
+
+Extracts skills, experience, education, languages, remote policy, salary and seniority
+of the jobs that have no facts yet or facts of an older prompt version, e.g. jobs
+processed before fact extraction existed. Newly processed jobs get their facts from the
+worker. Stop with Ctrl+C; the job in progress is finished first.
+
+Options:`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	if *limit < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --limit must not be negative")
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	database, err := db.NewDB(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	geminiClient, err := newGeminiClient(ctx, cfg, database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer geminiClient.Close()
+
+	proc := processor.NewProcessor(geminiClient, store.NewStore(database), cfg.TargetLanguages, cfg.EmbeddingsEnabled, cfg.GlossaryTenant)
+
+	ids, err := proc.GetJobIDsWithoutFacts(ctx, *limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Extracting facts of %d jobs\n", len(ids))
+
+	done, failed := 0, 0
+	for i, id := range ids {
+		// Finish the job in progress on Ctrl+C, but start no new one
+		if ctx.Err() != nil {
+			fmt.Println("Stopped")
+			break
+		}
+
+		_, err := proc.ExtractByID(context.WithoutCancel(ctx), &models.ExtractByIDRequest{JobID: id, Force: true})
+		if err != nil {
+			failed++
+			fmt.Printf("  %s: %v\n", id, err)
+			if errors.Is(err, llm.ErrQuota) {
+				fmt.Println("Out of model quota, stopping")
+				break
+			}
+			continue
+		}
+		done++
+
+		if (i+1)%25 == 0 {
+			fmt.Printf("%d/%d done, %d failed\n", done, len(ids), failed)
+		}
+	}
+
+	fmt.Printf("Extracted facts of %d jobs, %d failed\n", done, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func runEmbed(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("embed", flag.ExitOnError)
+	limit := fs.Int("limit", 0, "Max jobs to embed, newest first (0 = all)")
+	jobID := fs.String("job", "", "Embed only this job")
+	force := fs.Bool("force", false, "With --job: re-embed descriptions with a current embedding")
+
+	fs.Usage = func() {
+		fmt.Println(`Usage: server embed [options]
+
+Embeds the normalized descriptions of jobs into job_embeddings when they have no
+embedding, the embedding was made with another provider or embedding model (see
+LLM_EMBEDDING_MODEL), or the description changed since. Run it after changing the
+embedding model to backfill all jobs; newly processed jobs are embedded by the worker.
+Stop with Ctrl+C; the job in progress is finished first.
+
+Options:`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	if *limit < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --limit must not be negative")
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	database, err := db.NewDB(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	geminiClient, err := newGeminiClient(ctx, cfg, database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer geminiClient.Close()
+
+	proc := processor.NewProcessor(geminiClient, store.NewStore(database), cfg.TargetLanguages, true, cfg.GlossaryTenant)
+
+	if *jobID != "" {
+		n, err := proc.EmbedByID(ctx, *jobID, *force)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Embedded %d descriptions of %s\n", n, *jobID)
+		return
+	}
+
+	ids, err := proc.GetStaleEmbeddingJobIDs(ctx, *limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	provider := geminiClient.Provider()
+	fmt.Printf("Embedding %d jobs with %s/%s\n", len(ids), provider.Name(), provider.EmbeddingModel())
+
+	done, embedded, failed := 0, 0, 0
+	for i, id := range ids {
+		// Finish the job in progress on Ctrl+C, but start no new one
+		if ctx.Err() != nil {
+			fmt.Println("Stopped")
+			break
+		}
+
+		n, err := proc.EmbedByID(context.WithoutCancel(ctx), id, false)
+		embedded += n
+		if err != nil {
+			failed++
+			fmt.Printf("  %s: %v\n", id, err)
+			if errors.Is(err, llm.ErrQuota) {
+				fmt.Println("Out of model quota, stopping")
+				break
+			}
+			continue
+		}
+		done++
+
+		if (i+1)%100 == 0 {
+			fmt.Printf("%d/%d done, %d failed\n", done, len(ids), failed)
+		}
+	}
+
+	fmt.Printf("Embedded %d descriptions of %d jobs, %d failed\n", embedded, done, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func runCache(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("cache", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	clear := fs.Bool("clear", false, "Delete cached answers")
+	prompt := fs.String("prompt", "", "Only clear answers of this prompt, e.g. normalization")
+
+	fs.Usage = func() {
+		fmt.Println(`Usage: server cache [options]
+
+Shows the cached LLM answers per prompt version with their hit counts. Answers of older
+prompt versions are deleted automatically when a worker or batch starts; --clear deletes
+the answers of one prompt or all of them, e.g. after changing a prompt without bumping
+its version.
+
+Options:`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	ctx := context.Background()
+
+	database, err := db.NewDB(ctx, *databaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	cache := llmcache.New(database, 0)
+
+	if *clear {
+		n, err := cache.Clear(ctx, *prompt)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Deleted %d cached answers\n", n)
+		return
+	}
+
+	stats, err := cache.Stats(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(stats) == 0 {
+		fmt.Println("The cache is empty")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROMPT\tVERSION\tENTRIES\tEXPIRED\tHITS\tLAST HIT")
+	for _, s := range stats {
+		lastHit := "-"
+		if s.LastHit != nil {
+			lastHit = s.LastHit.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\n", s.Prompt, s.Version, s.Entries, s.Expired, s.Hits, lastHit)
+	}
+	w.Flush()
+}
+
+func runUsage(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("usage", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	by := fs.String("by", "service", "Group by service, user, model, operation or day")
+	days := fs.Int("days", 7, "Days to summarize, today included")
+	service := fs.String("service", "", "Only calls of this service, e.g. auth_service")
+	user := fs.String("user", "", "Only calls made for this user ID")
+
+	fs.Usage = func() {
+		fmt.Println(`Usage: server usage [options]
+
+Summarizes the model calls all services recorded in ai_usage: calls, failures, prompt and
+completion tokens, cost at each service's LLM_PRICE_* settings and average latency.
+Token counts that providers did not report are estimated from the text length. Days
+start at midnight UTC, like the LLM_*_DAILY_TOKENS budgets.
+
+Options:`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	if *days < 1 || !llmusage.ValidGrouping(*by) {
+		fmt.Fprintln(os.Stderr, "Error: --days must be positive and --by one of service, user, model, operation or day")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	database, err := db.NewDB(ctx, *databaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	groups, err := llmusage.New(database, llmusage.Config{}).Summarize(ctx, *by, llmusage.Filter{
+		Since:   llmusage.Since(*days),
+		Service: *service,
+		UserID:  *user,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(groups) == 0 {
+		fmt.Println("No AI usage recorded in this period")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tCALLS\tFAILED\tPROMPT TOKENS\tCOMPLETION TOKENS\tCOST (USD)\tAVG LATENCY\n", strings.ToUpper(*by))
+	for _, g := range groups {
+		key := g.Key
+		if key == "" {
+			key = "-"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%.4f\t%dms\n",
+			key, g.Calls, g.Failed, g.PromptTokens, g.CompletionTokens, g.CostUSD, g.AvgLatencyMs)
+	}
+	w.Flush()
+}
+
+func runReviews(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("reviews", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	limit := fs.Int("limit", 20, "Number of reviews to list")
+	resolve := fs.String("resolve", "", "Comma-separated review IDs to resolve, or 'all'")
+
+	fs.Usage = func() {
+		fmt.Println(`Usage: server reviews [options]
+
+Lists the open reviews of translations that failed their quality checks: wrong section
+counts, missing numbers, email addresses or URLs, implausible length or ignored glossary
+terms. KEPT translations were saved with their problems, the others were dropped. A review
+is resolved when the language is translated again without problems, or with --resolve.
+
+Options:`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	var ids []int64
+	if *resolve != "" && *resolve != "all" {
+		for _, part := range strings.Split(*resolve, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: Invalid review ID '%s'\n", part)
+				os.Exit(1)
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	ctx := context.Background()
+
+	database, err := db.NewDB(ctx, *databaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	st := store.NewStore(database)
+
+	if *resolve != "" {
+		n, err := st.ResolveReviews(ctx, ids)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Resolved %d reviews\n", n)
+		return
+	}
+
+	total, err := st.CountOpenReviews(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Open reviews: %d\n", total)
+	if total == 0 {
+		return
+	}
+
+	reviews, err := st.ListOpenReviews(ctx, *limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tJOB\tLANGUAGE\tKEPT\tFLAGGED\tPROBLEMS")
+	for _, r := range reviews {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%s\t%s\n", r.ID, r.JobID, r.Language, r.Kept, r.CreatedAt.Format(time.RFC3339), strings.Join(r.Problems, "; "))
+	}
+	w.Flush()
+}
+
+func runGlossary(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("glossary", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	tenant := fs.String("tenant", cfg.GlossaryTenant, "Tenant of the terms (empty = all tenants)")
+	add := fs.String("add", "", "Term to add, e.g. Lehre")
+	lang := fs.String("lang", "", "Target language of --translation, e.g. en")
+	translation := fs.String("translation", "", "Translation of --add into --lang (empty = never translate the term)")
+	deleteID := fs.Int64("delete", 0, "ID of a term to delete")
+
+	fs.Usage = func() {
+		fmt.Println(`Usage: server glossary [options]
+
+Lists the glossary terms of a tenant and of all tenants. Terms that occur in a job are
+enforced in its translations: a term with a translation must be translated that way, one
+without must be kept as written in every language. Company names are always kept.
+
+Examples:
+  server glossary --add Lehre --lang en --translation apprenticeship
+  server glossary --add "Migros Bank"
+  server glossary --delete 12
+
+Options:`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	if (*lang == "") != (*translation == "") {
+		fmt.Fprintln(os.Stderr, "Error: --lang and --translation must be given together")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	database, err := db.NewDB(ctx, *databaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	st := store.NewStore(database)
+
+	switch {
+	case *deleteID != 0:
+		found, err := st.DeleteGlossaryTerm(ctx, *deleteID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !found {
+			fmt.Fprintf(os.Stderr, "Error: Glossary term %d not found\n", *deleteID)
+			os.Exit(1)
+		}
+		fmt.Printf("Deleted glossary term %d\n", *deleteID)
+		return
+	case *add != "":
+		term := models.GlossaryTerm{Tenant: *tenant, Term: *add, TargetLanguage: strings.ToLower(*lang), Translation: *translation}
+		if err := st.AddGlossaryTerm(ctx, &term); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Saved glossary term %d\n", term.ID)
+		return
+	}
+
+	terms, err := st.GetGlossary(ctx, *tenant)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(terms) == 0 {
+		fmt.Println("The glossary is empty")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTENANT\tTERM\tLANGUAGE\tTRANSLATION")
+	for _, t := range terms {
+		tenant, language, translation := t.Tenant, t.TargetLanguage, t.Translation
+		if tenant == "" {
+			tenant = "*"
+		}
+		if translation == "" {
+			language, translation = "*", "(keep)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", t.ID, tenant, t.Term, language, translation)
+	}
+	w.Flush()
+}
+
+func runEval(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	prompt := fs.String("prompt", gemini.PromptTranslation, "Prompt to evaluate: normalization, translation or raw_translation")
+	versionA := fs.Int("a", 0, "Baseline version (default: the one before --b)")
+	versionB := fs.Int("b", 0, "Candidate version (default: the newest)")
+	fixtures := fs.String("fixtures", "eval/jobs.json", "JSON file with the fixture jobs")
+	lang := fs.String("lang", "en", "Target language of translations")
+	promptsDir := fs.String("prompts-dir", cfg.PromptsDir, "Directory with prompt template versions besides the built-in ones")
+	details := fs.Bool("details", false, "List the result of every job")
+	asJSON := fs.Bool("json", false, "Print the reports as JSON")
+
+	fs.Usage = func() {
+		fmt.Println(`Usage: server eval [options]
+
+Runs two versions of a prompt template over a fixture set of jobs and compares how often
+the first answer passes output validation (no repairs), how long the answers are relative
+to their input and how many of the expected sections they fill. Translations also count
+answers with quality issues (see 'Translation Quality' in the README). Answers are not
+cached and nothing is written to the database.
+
+Add a candidate version as <prompt>.v<N>.tmpl to internal/gemini/prompts or --prompts-dir.
+
+Options:`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	templates, err := gemini.LoadTemplates(*promptsDir, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	versions := templates.Versions(*prompt)
+	if len(versions) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: Unknown prompt '%s'\n", *prompt)
+		os.Exit(1)
+	}
+	if *versionB == 0 {
+		*versionB = versions[len(versions)-1]
+	}
+	if *versionA == 0 {
+		for _, v := range versions {
+			if v < *versionB {
+				*versionA = v
+			}
+		}
+		if *versionA == 0 {
+			fmt.Fprintf(os.Stderr, "Error: Prompt %s has no version before %d, set --a\n", *prompt, *versionB)
+			os.Exit(1)
+		}
+	}
+
+	data, err := os.ReadFile(*fixtures)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var jobs []gemini.EvalJob
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid fixtures in %s: %v\n", *fixtures, err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	provider, err := llm.New(ctx, cfg.LLM)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer provider.Close()
+
+	client := gemini.NewClient(provider, nil, templates, cfg.LLMRepairAttempts)
+
+	if err := client.PrepareEval(ctx, *prompt, jobs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var reports []*gemini.EvalReport
+	for _, version := range []int{*versionA, *versionB} {
+		report, err := client.Evaluate(ctx, *prompt, version, jobs, *lang)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		reports = append(reports, report)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(reports)
+		return
+	}
+
+	a, b := reports[0], reports[1]
+	fmt.Printf("Prompt %s on %d jobs (%s), model %s\n\n", *prompt, a.Jobs, *fixtures, provider.Model())
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "METRIC\t%s\t%s\n", a.Prompt, b.Prompt)
+	fmt.Fprintf(w, "Valid answers\t%d/%d\t%d/%d\n", a.Valid, a.Jobs, b.Valid, b.Jobs)
+	if *prompt != gemini.PromptNormalization {
+		fmt.Fprintf(w, "With quality issues\t%d\t%d\n", a.WithIssues, b.WithIssues)
+	}
+	fmt.Fprintf(w, "Mean length ratio\t%.2f\t%.2f\n", a.MeanLength, b.MeanLength)
+	fmt.Fprintf(w, "Mean section coverage\t%.0f%%\t%.0f%%\n", a.MeanCoverage*100, b.MeanCoverage*100)
+	fmt.Fprintf(w, "Provider errors\t%d\t%d\n", a.Errors, b.Errors)
+	fmt.Fprintf(w, "Mean latency\t%s\t%s\n", a.MeanDuration.Round(time.Millisecond), b.MeanDuration.Round(time.Millisecond))
+	w.Flush()
+
+	if !*details {
+		return
+	}
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROMPT\tJOB\tVALID\tLENGTH\tCOVERAGE\tPROBLEMS")
+	for _, report := range reports {
+		for _, r := range report.Results {
+			problems := strings.Join(append(r.Problems, r.Issues...), "; ")
+			fmt.Fprintf(w, "%s\t%s\t%t\t%.2f\t%.0f%%\t%s\n", report.Prompt, r.JobID, r.Valid, r.Length, r.Coverage*100, problems)
+		}
+	}
+	w.Flush()
+}
+
+// newGeminiClient creates the LLM provider and the client on top of it, with the prompt
+// templates of PROMPTS_DIR and PROMPT_VERSIONS. Model calls are recorded in ai_usage and
+// checked against the LLM_*_DAILY_TOKENS budgets. Unless LLM_CACHE_TTL_HOURS is 0, validated
+// answers are cached in the database and answers of other prompt versions are deleted.
+func newGeminiClient(ctx context.Context, cfg *config.Config, database *sqlx.DB) (*gemini.Client, error) {
+	pinned, err := gemini.ParsePromptVersions(cfg.PromptVersions)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := gemini.LoadTemplates(cfg.PromptsDir, pinned)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Usage.HasBudget() {
+		if err := llmusage.CheckTable(ctx, database); err != nil {
+			return nil, err
+		}
+	}
+
+	provider, err := llm.New(ctx, cfg.LLM)
+	if err != nil {
+		return nil, err
+	}
+	provider = llm.Metered(provider, "ai_job_processing", llmusage.New(database, cfg.Usage))
+
+	var cache llm.Cache
+	if cfg.LLMCacheTTLHours > 0 {
+		c := llmcache.New(database, time.Duration(cfg.LLMCacheTTLHours)*time.Hour)
+		n, err := c.Invalidate(ctx, templates.Current())
+		if err != nil {
+			slog.Warn("failed to invalidate outdated cached answers", "error", err)
+		} else if n > 0 {
+			slog.Info("invalidated cached answers of outdated prompt versions", "count", n)
+		}
+		cache = c
+	}
+
+	return gemini.NewClient(provider, cache, templates, cfg.LLMRepairAttempts), nil
+}
+
+// serveMetrics serves Prometheus metrics on addr until ctx is done.
+func serveMetrics(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics listener failed", "addr", addr, "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("metrics listener started", "addr", ln.Addr().String())
+	return nil
+}
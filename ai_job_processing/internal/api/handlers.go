@@ -1,27 +1,42 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"ai_job_processing/internal/batch"
 	"ai_job_processing/internal/config"
 	"ai_job_processing/internal/models"
 	"ai_job_processing/internal/processor"
+	"ai_job_processing/internal/store"
+	"llmkit/llm"
+	"llmkit/llmusage"
 )
 
+// readinessTimeout bounds the dependency checks of a readiness probe
+const readinessTimeout = 5 * time.Second
+
 // Handler holds API handler dependencies.
 type Handler struct {
 	processor *processor.Processor
+	batches   *batch.Manager
+	usage     *llmusage.Recorder
 	config    *config.Config
 	version   string
 }
 
 // NewHandler creates a new Handler.
-func NewHandler(proc *processor.Processor, cfg *config.Config, version string) *Handler {
+func NewHandler(proc *processor.Processor, batches *batch.Manager, usage *llmusage.Recorder, cfg *config.Config, version string) *Handler {
 	return &Handler{
 		processor: proc,
+		batches:   batches,
+		usage:     usage,
 		config:    cfg,
 		version:   version,
 	}
@@ -39,6 +54,38 @@ func (h *Handler) HealthCheck(c *gin.Context) {
 	})
 }
 
+// Readiness handles GET /ready
+// Reports 503 unless the database and the LLM provider are reachable.
+func (h *Handler) Readiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	checks := map[string]func(context.Context) error{
+		"database": h.processor.CheckDatabase,
+		"llm":      h.processor.CheckLLM,
+	}
+
+	resp := models.ReadinessResponse{
+		Status:    "ready",
+		Checks:    make(map[string]string, len(checks)),
+		Timestamp: time.Now(),
+	}
+	for name, check := range checks {
+		if err := check(ctx); err != nil {
+			resp.Status = "unavailable"
+			resp.Checks[name] = err.Error()
+			continue
+		}
+		resp.Checks[name] = "ok"
+	}
+
+	status := http.StatusOK
+	if resp.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, resp)
+}
+
 // GetLanguages handles GET /api/v1/languages
 func (h *Handler) GetLanguages(c *gin.Context) {
 	languages := h.processor.GetTargetLanguages()
@@ -72,9 +119,10 @@ func (h *Handler) Process(c *gin.Context) {
 
 	resp, err := h.processor.Process(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		statusCode, code := modelErrorStatus(err, "PROCESSING_ERROR")
+		c.JSON(statusCode, models.ErrorResponse{
 			Error:   "Processing failed",
-			Code:    "PROCESSING_ERROR",
+			Code:    code,
 			Details: err.Error(),
 		})
 		return
@@ -104,8 +152,7 @@ func (h *Handler) ProcessByID(c *gin.Context) {
 
 	resp, err := h.processor.ProcessByID(c.Request.Context(), &req)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		code := "PROCESSING_ERROR"
+		statusCode, code := modelErrorStatus(err, "PROCESSING_ERROR")
 
 		if err.Error() == "job not found: "+jobID ||
 			err.Error() == "failed to load job: job not found: "+jobID {
@@ -145,8 +192,7 @@ func (h *Handler) TranslateByID(c *gin.Context) {
 
 	resp, err := h.processor.TranslateByID(c.Request.Context(), &req)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		code := "TRANSLATION_ERROR"
+		statusCode, code := modelErrorStatus(err, "TRANSLATION_ERROR")
 
 		if err.Error() == "job not found: "+jobID ||
 			err.Error() == "failed to load job: job not found: "+jobID {
@@ -186,8 +232,7 @@ func (h *Handler) NormalizeByID(c *gin.Context) {
 
 	resp, err := h.processor.NormalizeByID(c.Request.Context(), &req)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		code := "NORMALIZATION_ERROR"
+		statusCode, code := modelErrorStatus(err, "NORMALIZATION_ERROR")
 
 		if err.Error() == "job not found: "+jobID ||
 			err.Error() == "failed to load job: job not found: "+jobID {
@@ -221,9 +266,10 @@ func (h *Handler) Normalize(c *gin.Context) {
 
 	resp, err := h.processor.Normalize(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		statusCode, code := modelErrorStatus(err, "NORMALIZATION_ERROR")
+		c.JSON(statusCode, models.ErrorResponse{
 			Error:   "Normalization failed",
-			Code:    "NORMALIZATION_ERROR",
+			Code:    code,
 			Details: err.Error(),
 		})
 		return
@@ -247,9 +293,76 @@ func (h *Handler) Translate(c *gin.Context) {
 
 	resp, err := h.processor.Translate(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		statusCode, code := modelErrorStatus(err, "TRANSLATION_ERROR")
+		c.JSON(statusCode, models.ErrorResponse{
 			Error:   "Translation failed",
-			Code:    "TRANSLATION_ERROR",
+			Code:    code,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Extract handles POST /api/v1/extract
+// Extracts skills, requirements and conditions from raw job data. Does not use database.
+func (h *Handler) Extract(c *gin.Context) {
+	var req models.ExtractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.processor.Extract(c.Request.Context(), &req)
+	if err != nil {
+		statusCode, code := modelErrorStatus(err, "EXTRACTION_ERROR")
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:   "Extraction failed",
+			Code:    code,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ExtractByID handles POST /api/v1/extract/:id
+// Extracts the facts of a job from database, saves them to job_facts.
+// Skips if facts of the current prompt version exist (unless force=true).
+func (h *Handler) ExtractByID(c *gin.Context) {
+	jobID := c.Param("id")
+	if jobID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Job ID is required",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	var req models.ExtractByIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		req = models.ExtractByIDRequest{}
+	}
+	req.JobID = jobID
+
+	resp, err := h.processor.ExtractByID(c.Request.Context(), &req)
+	if err != nil {
+		statusCode, code := modelErrorStatus(err, "EXTRACTION_ERROR")
+
+		if errors.Is(err, store.ErrJobNotFound) {
+			statusCode = http.StatusNotFound
+			code = "JOB_NOT_FOUND"
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:   "Extraction failed",
+			Code:    code,
 			Details: err.Error(),
 		})
 		return
@@ -280,3 +393,151 @@ func (h *Handler) GetPendingJobs(c *gin.Context) {
 		"total": count,
 	})
 }
+
+// StartBatch handles POST /api/v1/batches
+// Processes the currently pending jobs in the background. Returns 202 with the batch status.
+func (h *Handler) StartBatch(c *gin.Context) {
+	var req models.BatchRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid request body",
+				Code:    "INVALID_REQUEST",
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+	if req.Limit < 0 || req.Concurrency < 0 || req.Concurrency > batch.MaxConcurrency {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("limit must not be negative and concurrency must be between 0 and %d", batch.MaxConcurrency),
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	b, err := h.batches.Start(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, batch.ErrRunning) {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error: "A batch is already running",
+				Code:  "BATCH_RUNNING",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to start batch",
+			Code:    "DATABASE_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	status := b.Status()
+	c.Header("Location", "/api/v1/batches/"+status.ID)
+	c.JSON(http.StatusAccepted, status)
+}
+
+// ListBatches handles GET /api/v1/batches
+// Lists the running and recently finished batches, newest first.
+func (h *Handler) ListBatches(c *gin.Context) {
+	batches := h.batches.List()
+
+	c.JSON(http.StatusOK, gin.H{
+		"batches": batches,
+		"count":   len(batches),
+	})
+}
+
+// GetBatch handles GET /api/v1/batches/:id
+// Reports total, done, failed and the estimated end of a batch.
+func (h *Handler) GetBatch(c *gin.Context) {
+	status, err := h.batches.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Batch not found",
+			Code:  "BATCH_NOT_FOUND",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// CancelBatch handles DELETE /api/v1/batches/:id
+// Stops a batch from starting further jobs; jobs in progress are finished.
+func (h *Handler) CancelBatch(c *gin.Context) {
+	status, err := h.batches.Cancel(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Batch not found",
+			Code:  "BATCH_NOT_FOUND",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, status)
+}
+
+// GetUsage handles GET /api/v1/usage
+// Summarizes the AI usage of all services over the last days (default 7, today included),
+// grouped by service, user, model, operation or day (default service).
+func (h *Handler) GetUsage(c *gin.Context) {
+	by := c.DefaultQuery("by", "service")
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days < 1 || days > 366 || !llmusage.ValidGrouping(by) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "days must be between 1 and 366 and by one of service, user, model, operation or day",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	filter := llmusage.Filter{
+		Since:   llmusage.Since(days),
+		Service: c.Query("service"),
+		UserID:  c.Query("user_id"),
+	}
+	groups, err := h.usage.Summarize(c.Request.Context(), by, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to summarize AI usage",
+			Code:    "DATABASE_ERROR",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	total := llmusage.Summary{Key: "total"}
+	for _, g := range groups {
+		total.Calls += g.Calls
+		total.Failed += g.Failed
+		total.PromptTokens += g.PromptTokens
+		total.CompletionTokens += g.CompletionTokens
+		total.CostUSD += g.CostUSD
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"by":     by,
+		"since":  filter.Since,
+		"groups": groups,
+		"total":  total,
+	})
+}
+
+// modelErrorStatus maps the model error classes to their HTTP status and error code. Other
+// errors get 500 and fallbackCode.
+func modelErrorStatus(err error, fallbackCode string) (int, string) {
+	switch {
+	case errors.Is(err, llm.ErrBudgetExceeded):
+		return http.StatusTooManyRequests, "BUDGET_EXCEEDED"
+	case errors.Is(err, llm.ErrQuota):
+		return http.StatusTooManyRequests, "QUOTA_EXCEEDED"
+	case errors.Is(err, llm.ErrRefused):
+		return http.StatusUnprocessableEntity, "MODEL_REFUSED"
+	case errors.Is(err, llm.ErrInvalidOutput):
+		return http.StatusBadGateway, "INVALID_MODEL_OUTPUT"
+	default:
+		return http.StatusInternalServerError, fallbackCode
+	}
+}
@@ -2,13 +2,16 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"ai_job_processing/internal/batch"
 	"ai_job_processing/internal/config"
 	"ai_job_processing/internal/processor"
+	"llmkit/llmusage"
 )
 
 // NewRouter creates and configures the Gin router.
-func NewRouter(proc *processor.Processor, cfg *config.Config, version string) *gin.Engine {
+func NewRouter(proc *processor.Processor, batches *batch.Manager, usage *llmusage.Recorder, cfg *config.Config, version string) *gin.Engine {
 	if cfg.LogLevel == "DEBUG" {
 		gin.SetMode(gin.DebugMode)
 	} else {
@@ -21,10 +24,14 @@ func NewRouter(proc *processor.Processor, cfg *config.Config, version string) *g
 	router.Use(RequestLogger())
 	router.Use(CORS())
 
-	handler := NewHandler(proc, cfg, version)
+	handler := NewHandler(proc, batches, usage, cfg, version)
 
-	// Health check
+	// Liveness and readiness (database and LLM provider reachable)
 	router.GET("/health", handler.HealthCheck)
+	router.GET("/ready", handler.Readiness)
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
@@ -39,11 +46,22 @@ func NewRouter(proc *processor.Processor, cfg *config.Config, version string) *g
 		v1.POST("/process", handler.Process)     // Normalize + translate
 		v1.POST("/normalize", handler.Normalize) // Normalize only
 		v1.POST("/translate", handler.Translate) // Translate only
+		v1.POST("/extract", handler.Extract)     // Extract skills and requirements
 
 		// Database processing by job ID
 		v1.POST("/process/:id", handler.ProcessByID)     // Normalize + translate, save to DB
 		v1.POST("/normalize/:id", handler.NormalizeByID) // Normalize only, save to DB
 		v1.POST("/translate/:id", handler.TranslateByID) // Translate only, save to DB
+		v1.POST("/extract/:id", handler.ExtractByID)     // Extract skills and requirements, save to DB
+
+		// Batch processing of pending jobs
+		v1.POST("/batches", handler.StartBatch)
+		v1.GET("/batches", handler.ListBatches)
+		v1.GET("/batches/:id", handler.GetBatch)
+		v1.DELETE("/batches/:id", handler.CancelBatch)
+
+		// AI usage of all services (ai_usage)
+		v1.GET("/usage", handler.GetUsage)
 	}
 
 	return router
@@ -0,0 +1,305 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ai_job_processing/internal/models"
+	"ai_job_processing/internal/processor"
+	"llmkit/llm"
+)
+
+const (
+	// MaxConcurrency caps the jobs a batch processes at the same time
+	MaxConcurrency = 16
+
+	// jobTimeout bounds the processing of one job
+	jobTimeout = 10 * time.Minute
+
+	// maxErrors is the number of job failures kept per batch
+	maxErrors = 20
+
+	// maxFinished is the number of finished batches kept for status requests
+	maxFinished = 20
+)
+
+var (
+	// ErrRunning is returned when a batch is started while another one is running.
+	ErrRunning = errors.New("a batch is already running")
+
+	// ErrNotFound is returned for an unknown batch ID.
+	ErrNotFound = errors.New("batch not found")
+)
+
+// Manager runs batches of pending jobs in the background, one at a time so that
+// batches do not compete for the Gemini quota. Batch status is kept in memory.
+type Manager struct {
+	processor          *processor.Processor
+	defaultConcurrency int
+
+	mu      sync.Mutex
+	batches map[string]*Batch
+	order   []string // Batch IDs, oldest first
+	running *Batch
+	wg      sync.WaitGroup
+}
+
+// NewManager creates a new Manager.
+func NewManager(proc *processor.Processor, defaultConcurrency int) *Manager {
+	return &Manager{
+		processor:          proc,
+		defaultConcurrency: defaultConcurrency,
+		batches:            make(map[string]*Batch),
+	}
+}
+
+// Batch is one run over the jobs that were pending when it started.
+type Batch struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	status models.BatchStatus
+}
+
+// Start loads the pending jobs and processes them in the background. The batch outlives ctx;
+// stop it with Cancel or Shutdown.
+func (m *Manager) Start(ctx context.Context, req *models.BatchRequest) (*Batch, error) {
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = m.defaultConcurrency
+	}
+	concurrency = min(max(concurrency, 1), MaxConcurrency)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running != nil {
+		return nil, ErrRunning
+	}
+
+	ids, err := m.processor.GetPendingJobIDs(ctx, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	b := &Batch{
+		cancel: cancel,
+		done:   make(chan struct{}),
+		status: models.BatchStatus{
+			ID:          uuid.New().String(),
+			Status:      models.BatchStatusRunning,
+			Total:       len(ids),
+			Concurrency: concurrency,
+			Force:       req.Force,
+			StartedAt:   time.Now(),
+		},
+	}
+	m.batches[b.status.ID] = b
+	m.order = append(m.order, b.status.ID)
+	m.running = b
+	m.prune()
+
+	slog.Info("batch started",
+		"batch_id", b.status.ID,
+		"jobs", len(ids),
+		"concurrency", concurrency,
+		"force", req.Force,
+	)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		b.run(runCtx, m.processor, ids)
+
+		m.mu.Lock()
+		m.running = nil
+		m.mu.Unlock()
+	}()
+
+	return b, nil
+}
+
+// Get returns the status of a batch.
+func (m *Manager) Get(id string) (models.BatchStatus, error) {
+	m.mu.Lock()
+	b, ok := m.batches[id]
+	m.mu.Unlock()
+	if !ok {
+		return models.BatchStatus{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return b.Status(), nil
+}
+
+// List returns the status of the running and recently finished batches, newest first.
+func (m *Manager) List() []models.BatchStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]models.BatchStatus, 0, len(m.order))
+	for i := len(m.order) - 1; i >= 0; i-- {
+		statuses = append(statuses, m.batches[m.order[i]].Status())
+	}
+	return statuses
+}
+
+// Cancel stops a batch from starting further jobs. Jobs in progress are finished.
+func (m *Manager) Cancel(id string) (models.BatchStatus, error) {
+	m.mu.Lock()
+	b, ok := m.batches[id]
+	m.mu.Unlock()
+	if !ok {
+		return models.BatchStatus{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	b.cancel()
+	return b.Status(), nil
+}
+
+// Shutdown cancels the running batch and waits for its jobs in progress, or until ctx is done.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.running != nil {
+		m.running.cancel()
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// prune drops the oldest finished batches beyond maxFinished. Callers hold m.mu.
+func (m *Manager) prune() {
+	for len(m.order) > maxFinished+1 {
+		id := m.order[0]
+		if m.batches[id] == m.running {
+			return
+		}
+		delete(m.batches, id)
+		m.order = m.order[1:]
+	}
+}
+
+// Status returns a snapshot of the batch progress with its estimated end.
+func (b *Batch) Status() models.BatchStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := b.status
+	status.Errors = append([]models.BatchJobError(nil), b.status.Errors...)
+
+	attempted := status.Done + status.Failed
+	if status.Status == models.BatchStatusRunning && attempted > 0 {
+		elapsed := time.Since(status.StartedAt)
+		remaining := time.Duration(float64(elapsed) / float64(attempted) * float64(status.Total-attempted))
+		eta := time.Now().Add(remaining)
+		status.ETA = &eta
+	}
+	return status
+}
+
+// Done is closed when the batch has finished.
+func (b *Batch) Done() <-chan struct{} {
+	return b.done
+}
+
+// run processes the jobs with bounded concurrency until all were attempted or ctx is cancelled.
+func (b *Batch) run(ctx context.Context, proc *processor.Processor, ids []string) {
+	defer close(b.done)
+
+	var (
+		wg    sync.WaitGroup
+		slots = make(chan struct{}, b.status.Concurrency)
+	)
+
+dispatch:
+	for _, id := range ids {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case slots <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(jobID string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			b.process(ctx, proc, jobID)
+		}(id)
+	}
+	wg.Wait()
+
+	b.mu.Lock()
+	now := time.Now()
+	b.status.FinishedAt = &now
+	b.status.Status = models.BatchStatusCompleted
+	if b.status.Done+b.status.Failed < b.status.Total {
+		b.status.Status = models.BatchStatusCancelled
+	}
+	status := b.status
+	b.mu.Unlock()
+
+	slog.Info("batch finished",
+		"batch_id", status.ID,
+		"status", status.Status,
+		"total", status.Total,
+		"done", status.Done,
+		"skipped", status.Skipped,
+		"failed", status.Failed,
+		"duration_ms", now.Sub(status.StartedAt).Milliseconds(),
+	)
+}
+
+// process runs one job through ProcessByID and records the outcome. Cancelling the batch
+// does not abort a job in progress, so its AI calls are not wasted.
+func (b *Batch) process(ctx context.Context, proc *processor.Processor, jobID string) {
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jobTimeout)
+	defer cancel()
+
+	resp, err := proc.ProcessByID(jobCtx, &models.ProcessByIDRequest{
+		JobID: jobID,
+		Force: b.status.Force,
+	})
+	if err == nil && !resp.Skipped && !resp.SavedToDB {
+		err = fmt.Errorf("results were not saved")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil {
+		b.status.Failed++
+		b.status.Errors = append(b.status.Errors, models.BatchJobError{JobID: jobID, Error: err.Error()})
+		if len(b.status.Errors) > maxErrors {
+			b.status.Errors = b.status.Errors[1:]
+		}
+		slog.Warn("batch job failed", "batch_id", b.status.ID, "job_id", jobID, "error", err)
+
+		// The remaining jobs would fail the same way until the quota recovers
+		if errors.Is(err, llm.ErrQuota) {
+			slog.Error("model quota exceeded, cancelling batch", "batch_id", b.status.ID)
+			b.cancel()
+		}
+		return
+	}
+
+	b.status.Done++
+	if resp.Skipped {
+		b.status.Skipped++
+	}
+}
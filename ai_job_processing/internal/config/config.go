@@ -4,6 +4,9 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	"llmkit/llm"
+	"llmkit/llmusage"
 )
 
 // Config holds all application configuration.
@@ -15,21 +18,48 @@ type Config struct {
 	// Database
 	DatabaseURL string
 
-	// Gemini AI
-	GeminiAPIKey      string
-	GeminiModel       string
-	GeminiTemperature float32
+	// LLM provider (gemini, openai or fake); GEMINI_* configure the gemini provider
+	LLM               llm.Config
+	LLMRepairAttempts int // Times an invalid answer is sent back to the model for repair
+	LLMCacheTTLHours  int // Hours validated answers stay in llm_cache (0 = no caching)
+
+	// AI usage accounting in ai_usage: daily token budgets (0 = unlimited) and prices in
+	// USD per million tokens
+	Usage llmusage.Config
+
+	// Prompt templates: extra template versions and pinned versions, e.g. translation=1
+	PromptsDir     string
+	PromptVersions string
+
+	// Embeddings of normalized descriptions in job_embeddings
+	EmbeddingsEnabled bool
 
 	// Languages
 	TargetLanguages []string
 	SourceLanguage  string
 
+	// Glossary enforced in translations: terms of this tenant and of all tenants
+	GlossaryTenant string
+
 	// Logging
 	LogLevel  string
 	LogFormat string
 
 	// Rate limiting
 	RateLimitRPM int
+
+	// Metrics
+	MetricsAddr string // Prometheus listen address of the worker (empty = disabled)
+
+	// Queue worker (drains ai_job_queue filled by the scrapper)
+	QueueWorkers           int // Items processed concurrently
+	QueuePollSeconds       int // Wait between polls when the queue is empty
+	QueueVisibilitySeconds int // How long a claimed item is hidden from other workers; also the per-item timeout
+	QueueRetryBaseSeconds  int // First retry delay, doubled per attempt
+	QueueRetentionDays     int // Done items older than this are purged (0 = keep)
+
+	// Batch processing of pending jobs
+	BatchConcurrency int // Default jobs processed concurrently per batch
 }
 
 // Load loads configuration from environment variables.
@@ -39,17 +69,49 @@ func Load() *Config {
 	languages := parseLanguages(targetLangs)
 
 	return &Config{
-		Port:              GetEnv("PORT", "8081"),
-		Host:              GetEnv("HOST", "0.0.0.0"),
-		DatabaseURL:       GetEnv("DATABASE_URL", ""),
-		GeminiAPIKey:      GetEnv("GEMINI_API_KEY", ""),
-		GeminiModel:       GetEnv("GEMINI_MODEL", "gemini-2.0-flash"),
-		GeminiTemperature: GetEnvFloat32("GEMINI_TEMPERATURE", 0.3),
+		Port:        GetEnv("PORT", "8081"),
+		Host:        GetEnv("HOST", "0.0.0.0"),
+		DatabaseURL: GetEnv("DATABASE_URL", ""),
+		LLM: llm.Config{
+			Provider:       GetEnv("LLM_PROVIDER", llm.ProviderGemini),
+			APIKey:         GetEnv("LLM_API_KEY", ""),
+			BaseURL:        GetEnv("LLM_BASE_URL", ""),
+			Model:          GetEnv("LLM_MODEL", ""),
+			EmbeddingModel: GetEnv("LLM_EMBEDDING_MODEL", ""),
+			Temperature:    GetEnvFloat32("GEMINI_TEMPERATURE", 0.3),
+		}.WithGeminiDefaults(
+			GetEnv("GEMINI_API_KEY", ""),
+			GetEnv("GEMINI_MODEL", "gemini-2.0-flash"),
+			"",
+		),
+		LLMRepairAttempts: GetEnvInt("LLM_REPAIR_ATTEMPTS", 2),
+		LLMCacheTTLHours:  GetEnvInt("LLM_CACHE_TTL_HOURS", 720),
+
+		Usage: llmusage.Config{
+			UserDailyTokens:    GetEnvInt("LLM_USER_DAILY_TOKENS", 0),
+			ServiceDailyTokens: GetEnvInt("LLM_SERVICE_DAILY_TOKENS", 0),
+			PromptPrice:        GetEnvFloat64("LLM_PRICE_PROMPT", 0),
+			CompletionPrice:    GetEnvFloat64("LLM_PRICE_COMPLETION", 0),
+		},
+
+		PromptsDir:        GetEnv("PROMPTS_DIR", ""),
+		PromptVersions:    GetEnv("PROMPT_VERSIONS", ""),
+		EmbeddingsEnabled: GetEnvBool("EMBEDDINGS_ENABLED", true),
 		TargetLanguages:   languages,
 		SourceLanguage:    GetEnv("SOURCE_LANGUAGE", ""),
+		GlossaryTenant:    GetEnv("GLOSSARY_TENANT", ""),
 		LogLevel:          GetEnv("LOG_LEVEL", "INFO"),
 		LogFormat:         GetEnv("LOG_FORMAT", "json"),
 		RateLimitRPM:      GetEnvInt("RATE_LIMIT_RPM", 60),
+		MetricsAddr:       GetEnv("METRICS_ADDR", ""),
+
+		QueueWorkers:           GetEnvInt("QUEUE_WORKERS", 4),
+		QueuePollSeconds:       GetEnvInt("QUEUE_POLL_SECONDS", 5),
+		QueueVisibilitySeconds: GetEnvInt("QUEUE_VISIBILITY_SECONDS", 600),
+		QueueRetryBaseSeconds:  GetEnvInt("QUEUE_RETRY_BASE_SECONDS", 30),
+		QueueRetentionDays:     GetEnvInt("QUEUE_RETENTION_DAYS", 7),
+
+		BatchConcurrency: GetEnvInt("BATCH_CONCURRENCY", 4),
 	}
 }
 
@@ -98,6 +160,26 @@ func GetEnvFloat32(key string, defaultValue float32) float32 {
 	return defaultValue
 }
 
+// GetEnvFloat64 returns the float64 value of an environment variable or a default value.
+func GetEnvFloat64(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
+// GetEnvBool returns the boolean value of an environment variable or a default value.
+func GetEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return defaultValue
+}
+
 // LanguageNames maps ISO codes to human-readable names.
 var LanguageNames = map[string]string{
 	"de": "German",
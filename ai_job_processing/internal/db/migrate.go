@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 
@@ -10,6 +11,11 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
+// MigrationsTable records the applied migrations of this service. The scrapper and the other
+// services keep their own versions in schema_migrations of the same database, and
+// golang-migrate fails on versions missing from its source, so they must not share a table.
+const MigrationsTable = "ai_job_processing_migrations"
+
 // Migrator handles database migrations.
 type Migrator struct {
 	m   *migrate.Migrate
@@ -27,8 +33,16 @@ func NewMigrator(databaseURL string) (*Migrator, error) {
 		return nil, fmt.Errorf("failed to find migrations directory: %w", err)
 	}
 
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("x-migrations-table", MigrationsTable)
+	u.RawQuery = q.Encode()
+
 	sourceURL := fmt.Sprintf("file://%s", filepath.ToSlash(migrationsPath))
-	m, err := migrate.New(sourceURL, databaseURL)
+	m, err := migrate.New(sourceURL, u.String())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create migrator: %w", err)
 	}
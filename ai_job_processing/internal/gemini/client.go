@@ -2,144 +2,156 @@ package gemini
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
-
 	"ai_job_processing/internal/models"
+	"llmkit/llm"
 )
 
-// ClientConfig holds Gemini client configuration.
-type ClientConfig struct {
-	APIKey      string
-	Model       string
-	Temperature float32
-}
-
-// Client wraps the Gemini API client.
+// Client runs the job normalization, translation and fact extraction prompts on an LLM provider
+// (Gemini by default, see LLM_PROVIDER).
 type Client struct {
-	client *genai.Client
-	model  *genai.GenerativeModel
-	config ClientConfig
+	llm            llm.Provider
+	cache          llm.Cache
+	templates      *Templates
+	repairAttempts int
+
+	inFlight atomic.Int64 // Prompts and embeddings in progress
+
+	pingMu   sync.Mutex
+	pingedAt time.Time // Last successful Ping
 }
 
-// NewClient creates a new Gemini client.
-func NewClient(ctx context.Context, cfg ClientConfig) (*Client, error) {
-	if cfg.APIKey == "" {
-		return nil, fmt.Errorf("GEMINI_API_KEY is required")
-	}
+const (
+	// pingTTL is how long a successful Ping is reused, so readiness probes cost at most one
+	// provider request per interval
+	pingTTL = 30 * time.Second
 
-	if cfg.Model == "" {
-		cfg.Model = "gemini-2.0-flash"
-	}
+	// drainPollInterval is how often Drain checks for calls in flight
+	drainPollInterval = 100 * time.Millisecond
+)
 
-	client, err := genai.NewClient(ctx, option.WithAPIKey(cfg.APIKey))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
-	}
+// NewClient creates a new Client on top of provider that renders its prompts from the
+// templates in use (see LoadTemplates). Validated answers are kept in cache (nil = no caching).
+// Answers that fail validation are sent back for repair up to repairAttempts times.
+func NewClient(provider llm.Provider, cache llm.Cache, templates *Templates, repairAttempts int) *Client {
+	return &Client{llm: provider, cache: cache, templates: templates, repairAttempts: max(repairAttempts, 0)}
+}
 
-	model := client.GenerativeModel(cfg.Model)
-	model.SetTemperature(cfg.Temperature)
+// Prompts returns the prompt versions in use, for invalidating cached answers of other versions.
+func (c *Client) Prompts() []llm.Prompt {
+	return c.templates.Current()
+}
 
-	// Configure for structured output
-	model.ResponseMIMEType = "application/json"
+// FactsPromptVersion returns the version of the fact extraction prompt in use. Facts of older
+// versions are due for re-extraction.
+func (c *Client) FactsPromptVersion() int {
+	t, _ := c.templates.Get(PromptFacts, 0)
+	return t.Prompt.Version
+}
 
-	return &Client{
-		client: client,
-		model:  model,
-		config: cfg,
-	}, nil
+// Provider returns the underlying LLM provider.
+func (c *Client) Provider() llm.Provider {
+	return c.llm
 }
 
-// Close closes the Gemini client.
+// Close closes the underlying LLM provider. Call Drain first to let calls in flight finish.
 func (c *Client) Close() error {
-	return c.client.Close()
+	return c.llm.Close()
 }
 
-// NormalizeJobDescription extracts structured sections from a job description.
-func (c *Client) NormalizeJobDescription(ctx context.Context, title, description, sourceLanguage string) (*models.NormalizedContent, error) {
-	start := time.Now()
-
-	prompt := buildNormalizationPrompt(title, description, sourceLanguage)
+// Ping checks that the LLM provider is reachable. A successful check is reused for pingTTL.
+func (c *Client) Ping(ctx context.Context) error {
+	c.pingMu.Lock()
+	defer c.pingMu.Unlock()
 
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
-	if err != nil {
-		return nil, fmt.Errorf("Gemini API error: %w", err)
+	if time.Since(c.pingedAt) < pingTTL {
+		return nil
+	}
+	if err := c.llm.Ping(ctx); err != nil {
+		return err
 	}
+	c.pingedAt = time.Now()
+	return nil
+}
+
+// InFlight returns the number of prompts and embeddings in progress.
+func (c *Client) InFlight() int {
+	return int(c.inFlight.Load())
+}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("empty response from Gemini")
+// Drain waits until no prompts or embeddings are in progress, or until ctx is done. It does
+// not stop new calls; stop their callers first.
+func (c *Client) Drain(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for c.inFlight.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d model calls still in progress: %w", c.inFlight.Load(), ctx.Err())
+		case <-ticker.C:
+		}
 	}
+	return nil
+}
 
-	text := extractText(resp.Candidates[0].Content.Parts)
+// NormalizeJobDescription extracts structured sections from a job description.
+func (c *Client) NormalizeJobDescription(ctx context.Context, title, description, sourceLanguage string) (*models.NormalizedContent, error) {
+	start := time.Now()
 
-	var result struct {
-		Tasks        string `json:"tasks"`
-		Requirements string `json:"requirements"`
-		Offer        string `json:"offer"`
+	p, prompt, err := c.render(PromptNormalization, 0, PromptData{Title: title, Description: description, SourceLanguage: sourceLanguage})
+	if err != nil {
+		return nil, err
 	}
 
-	if err := json.Unmarshal([]byte(text), &result); err != nil {
-		text = extractJSONFromMarkdown(text)
-		if err := json.Unmarshal([]byte(text), &result); err != nil {
-			return nil, fmt.Errorf("failed to parse Gemini response: %w", err)
-		}
+	values, _, err := c.generateFields(ctx, p, prompt, normalizationSchema, func(values map[string]string) []string {
+		return checkNormalized(normalizedFrom(values), description)
+	}, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	slog.Debug("normalization completed",
 		"duration_ms", time.Since(start).Milliseconds(),
 	)
 
-	return &models.NormalizedContent{
-		Tasks:        strings.TrimSpace(result.Tasks),
-		Requirements: strings.TrimSpace(result.Requirements),
-		Offer:        strings.TrimSpace(result.Offer),
-	}, nil
+	normalized := normalizedFrom(values)
+	normalized.PromptVersion = p.Version
+	return normalized, nil
 }
 
-// TranslateNormalizedContent translates normalized content to a target language.
-func (c *Client) TranslateNormalizedContent(ctx context.Context, title string, normalized *models.NormalizedContent, sourceLanguage, targetLanguage string) (*models.TranslatedContent, error) {
+// TranslateNormalizedContent translates normalized content to a target language, following the
+// glossary terms that occur in it. A translation that fails the quality checks of
+// reviewTranslated after all repairs is returned with its Issues set.
+func (c *Client) TranslateNormalizedContent(ctx context.Context, title string, normalized *models.NormalizedContent, sourceLanguage, targetLanguage string, glossary []models.GlossaryTerm) (*models.TranslatedContent, error) {
 	start := time.Now()
 
-	prompt := buildTranslationPrompt(title, normalized, sourceLanguage, targetLanguage)
-
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
+	sourceText := title + "\n" + normalized.Tasks + "\n" + normalized.Requirements + "\n" + normalized.Offer
+	terms := glossaryFor(glossary, sourceText, targetLanguage)
+	p, prompt, err := c.render(PromptTranslation, 0, translationData(title, normalized, sourceLanguage, targetLanguage, terms))
 	if err != nil {
-		return nil, fmt.Errorf("Gemini API error: %w", err)
-	}
-
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("empty response from Gemini")
+		return nil, err
 	}
 
-	text := extractText(resp.Candidates[0].Content.Parts)
-
-	var result struct {
-		Title        string `json:"title"`
-		Tasks        string `json:"tasks"`
-		Requirements string `json:"requirements"`
-		Offer        string `json:"offer"`
-	}
-
-	if err := json.Unmarshal([]byte(text), &result); err != nil {
-		text = extractJSONFromMarkdown(text)
-		if err := json.Unmarshal([]byte(text), &result); err != nil {
-			return nil, fmt.Errorf("failed to parse translation response: %w", err)
-		}
+	values, issues, err := c.generateFields(ctx, p, prompt, translationSchema, func(values map[string]string) []string {
+		return checkTranslated(translatedFrom(values, targetLanguage), normalized, targetLanguage)
+	}, func(values map[string]string) []string {
+		return reviewTranslated(translatedFrom(values, targetLanguage), title, normalized, "", terms)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	translated := &models.TranslatedContent{
-		Language:     targetLanguage,
-		Title:        strings.TrimSpace(result.Title),
-		Tasks:        strings.TrimSpace(result.Tasks),
-		Requirements: strings.TrimSpace(result.Requirements),
-		Offer:        strings.TrimSpace(result.Offer),
-	}
+	translated := translatedFrom(values, targetLanguage)
+	translated.Issues = issues
+	translated.Prompt, translated.PromptVersion = p.Name, p.Version
 
 	// Build combined description using translated sections
 	translatedNormalized := &models.NormalizedContent{
@@ -157,50 +169,188 @@ func (c *Client) TranslateNormalizedContent(ctx context.Context, title string, n
 	return translated, nil
 }
 
-// TranslateRawDescription translates a raw job description (without normalization).
-func (c *Client) TranslateRawDescription(ctx context.Context, title, description, sourceLanguage, targetLanguage string) (*models.TranslatedContent, error) {
+// TranslateRawDescription translates a raw job description (without normalization), following
+// the glossary terms that occur in it. See TranslateNormalizedContent for Issues.
+func (c *Client) TranslateRawDescription(ctx context.Context, title, description, sourceLanguage, targetLanguage string, glossary []models.GlossaryTerm) (*models.TranslatedContent, error) {
 	start := time.Now()
 
-	prompt := buildRawTranslationPrompt(title, description, sourceLanguage, targetLanguage)
+	terms := glossaryFor(glossary, title+"\n"+description, targetLanguage)
+	p, prompt, err := c.render(PromptRawTranslation, 0, rawTranslationData(title, description, sourceLanguage, targetLanguage, terms))
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
+	values, issues, err := c.generateFields(ctx, p, prompt, rawTranslationSchema, func(values map[string]string) []string {
+		return checkTranslated(translatedFrom(values, targetLanguage), nil, targetLanguage)
+	}, func(values map[string]string) []string {
+		return reviewTranslated(translatedFrom(values, targetLanguage), title, nil, description, terms)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("Gemini API error: %w", err)
+		return nil, err
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("empty response from Gemini")
+	slog.Debug("raw translation completed",
+		"target_language", targetLanguage,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
+	translated := translatedFrom(values, targetLanguage)
+	translated.Issues = issues
+	translated.Prompt, translated.PromptVersion = p.Name, p.Version
+	return translated, nil
+}
+
+// GenerateEmbedding generates a vector embedding for text with the provider's embedding model.
+func (c *Client) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+
+	ctx = llm.WithOperation(ctx, "embedding")
+	start := time.Now()
+
+	embedding, err := c.llm.Embed(ctx, text)
+	if err != nil {
+		return nil, fmt.Errorf("embedding error: %w", err)
 	}
 
-	text := extractText(resp.Candidates[0].Content.Parts)
+	slog.Debug("embedding generated",
+		"duration_ms", time.Since(start).Milliseconds(),
+		"dimensions", len(embedding),
+	)
 
-	var result struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
+	return embedding, nil
+}
+
+// generateFields runs a JSON prompt whose answer is an object of string fields, validated
+// against s and check and, if not nil, reviewed by review. See generate.
+func (c *Client) generateFields(ctx context.Context, p llm.Prompt, prompt string, s schema, check, review func(map[string]string) []string) (map[string]string, []string, error) {
+	var reviewText func(string) []string
+	if review != nil {
+		reviewText = func(text string) []string {
+			values, _ := s.decode(text)
+			return review(values)
+		}
 	}
 
-	if err := json.Unmarshal([]byte(text), &result); err != nil {
-		text = extractJSONFromMarkdown(text)
-		if err := json.Unmarshal([]byte(text), &result); err != nil {
-			return nil, fmt.Errorf("failed to parse translation response: %w", err)
+	text, issues, err := c.generate(ctx, p, prompt, s.name, s.describe(), func(text string) []string {
+		values, problems := s.decode(text)
+		if len(problems) == 0 {
+			problems = check(values)
 		}
+		return problems
+	}, reviewText)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	slog.Debug("raw translation completed",
-		"target_language", targetLanguage,
-		"duration_ms", time.Since(start).Milliseconds(),
-	)
+	values, _ := s.decode(text)
+	return values, issues, nil
+}
+
+// generate runs a JSON prompt and returns the first answer that validate finds no problems
+// with. A cached answer is used if it still passes validation. A rejected answer is sent back
+// to the model with the problems found and the expected format, up to c.repairAttempts times;
+// after that the *ValidationError of the last answer is returned. Provider errors such as
+// llm.ErrRefused and llm.ErrQuota are returned right away.
+//
+// review, if not nil, runs quality checks on valid answers. Its issues are sent back for
+// repair like problems, but when no repair gets rid of them, the last valid answer is returned
+// with its issues instead of an error. Only answers without problems or issues are cached,
+// under the key of the original prompt.
+func (c *Client) generate(ctx context.Context, p llm.Prompt, prompt, name, format string, validate, review func(text string) []string) (string, []string, error) {
+	c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+
+	ctx = llm.WithOperation(ctx, p.Name)
+
+	var key string
+	if c.cache != nil {
+		key = llm.CacheKey(c.llm, p, prompt)
+		if text, ok := c.cache.Get(ctx, p, key); ok && len(validate(text)) == 0 && (review == nil || len(review(text)) == 0) {
+			return text, nil, nil
+		}
+	}
+
+	current := prompt
+	var verr *ValidationError
+
+	// Last valid answer with review issues, returned if no repair succeeds
+	var flagged string
+	var flaggedIssues []string
+
+	for attempt := 0; attempt <= c.repairAttempts; attempt++ {
+		text, err := c.llm.GenerateJSON(ctx, current)
+
+		var problems []string
+		switch {
+		case errors.Is(err, llm.ErrEmptyResponse):
+			problems = []string{"the answer was empty"}
+		case err != nil:
+			return "", nil, err
+		default:
+			problems = validate(text)
+		}
+
+		if len(problems) == 0 && review != nil {
+			if issues := review(text); len(issues) > 0 {
+				flagged, flaggedIssues = text, issues
+				problems = issues
+			}
+		}
+
+		if len(problems) == 0 {
+			if attempt > 0 {
+				slog.Info("model output repaired", "schema", name, "repairs", attempt)
+			}
+			if c.cache != nil {
+				c.cache.Put(ctx, p, key, text)
+			}
+			return text, nil, nil
+		}
+
+		verr = &ValidationError{Schema: name, Problems: problems}
+		slog.Warn("model output rejected",
+			"schema", name,
+			"attempt", attempt+1,
+			"problems", problems,
+		)
+		current = buildRepairPrompt(prompt, text, format, problems)
+	}
+
+	if flagged != "" {
+		slog.Warn("model output accepted with issues", "schema", name, "issues", flaggedIssues)
+		return flagged, flaggedIssues, nil
+	}
+	return "", nil, verr
+}
 
+// normalizedFrom builds normalized content from decoded normalization fields.
+func normalizedFrom(values map[string]string) *models.NormalizedContent {
+	return &models.NormalizedContent{
+		Tasks:        values["tasks"],
+		Requirements: values["requirements"],
+		Offer:        values["offer"],
+	}
+}
+
+// translatedFrom builds translated content from decoded translation fields.
+func translatedFrom(values map[string]string, targetLanguage string) *models.TranslatedContent {
 	return &models.TranslatedContent{
-		Language:    targetLanguage,
-		Title:       strings.TrimSpace(result.Title),
-		Description: strings.TrimSpace(result.Description),
-	}, nil
+		Language:     targetLanguage,
+		Title:        values["title"],
+		Description:  values["description"],
+		Tasks:        values["tasks"],
+		Requirements: values["requirements"],
+		Offer:        values["offer"],
+	}
 }
 
-// TranslateMultipleNormalized translates normalized content to multiple languages.
-func (c *Client) TranslateMultipleNormalized(ctx context.Context, title string, normalized *models.NormalizedContent, sourceLanguage string, targetLanguages []string) ([]models.TranslatedContent, error) {
+// TranslateMultipleNormalized translates normalized content to multiple languages. Languages
+// whose translation failed or has quality issues are returned as reviews: failed ones are
+// left out of the results, ones with issues are kept.
+func (c *Client) TranslateMultipleNormalized(ctx context.Context, title string, normalized *models.NormalizedContent, sourceLanguage string, targetLanguages []string, glossary []models.GlossaryTerm) ([]models.TranslatedContent, []models.TranslationReview, error) {
 	results := make([]models.TranslatedContent, 0, len(targetLanguages))
+	var reviews []models.TranslationReview
 
 	for _, lang := range targetLanguages {
 		// Skip if target is same as source
@@ -216,24 +366,34 @@ func (c *Client) TranslateMultipleNormalized(ctx context.Context, title string,
 			continue
 		}
 
-		translated, err := c.TranslateNormalizedContent(ctx, title, normalized, sourceLanguage, lang)
+		translated, err := c.TranslateNormalizedContent(ctx, title, normalized, sourceLanguage, lang, glossary)
 		if err != nil {
+			// Out of quota, the remaining languages would fail the same way
+			if errors.Is(err, llm.ErrQuota) || ctx.Err() != nil {
+				return nil, nil, err
+			}
 			slog.Error("translation failed",
 				"target_language", lang,
 				"error", err,
 			)
+			reviews = append(reviews, c.review(lang, failureProblems(err), false))
 			continue
 		}
 
+		if len(translated.Issues) > 0 {
+			reviews = append(reviews, c.review(lang, translated.Issues, true))
+		}
 		results = append(results, *translated)
 	}
 
-	return results, nil
+	return results, reviews, nil
 }
 
-// TranslateMultipleRaw translates raw description to multiple languages.
-func (c *Client) TranslateMultipleRaw(ctx context.Context, title, description, sourceLanguage string, targetLanguages []string) ([]models.TranslatedContent, error) {
+// TranslateMultipleRaw translates raw description to multiple languages. Reviews are returned
+// as by TranslateMultipleNormalized.
+func (c *Client) TranslateMultipleRaw(ctx context.Context, title, description, sourceLanguage string, targetLanguages []string, glossary []models.GlossaryTerm) ([]models.TranslatedContent, []models.TranslationReview, error) {
 	results := make([]models.TranslatedContent, 0, len(targetLanguages))
+	var reviews []models.TranslationReview
 
 	for _, lang := range targetLanguages {
 		// Skip if target is same as source
@@ -246,125 +406,89 @@ func (c *Client) TranslateMultipleRaw(ctx context.Context, title, description, s
 			continue
 		}
 
-		translated, err := c.TranslateRawDescription(ctx, title, description, sourceLanguage, lang)
+		translated, err := c.TranslateRawDescription(ctx, title, description, sourceLanguage, lang, glossary)
 		if err != nil {
+			// Out of quota, the remaining languages would fail the same way
+			if errors.Is(err, llm.ErrQuota) || ctx.Err() != nil {
+				return nil, nil, err
+			}
 			slog.Error("translation failed",
 				"target_language", lang,
 				"error", err,
 			)
+			reviews = append(reviews, c.review(lang, failureProblems(err), false))
 			continue
 		}
 
+		if len(translated.Issues) > 0 {
+			reviews = append(reviews, c.review(lang, translated.Issues, true))
+		}
 		results = append(results, *translated)
 	}
 
-	return results, nil
+	return results, reviews, nil
 }
 
-// buildNormalizationPrompt creates the prompt for job description normalization.
-func buildNormalizationPrompt(title, description, sourceLanguage string) string {
-	langHint := ""
-	if sourceLanguage != "" {
-		langHint = fmt.Sprintf("The job description is in %s. ", sourceLanguage)
+// review flags the translation into lang for review.
+func (c *Client) review(lang string, problems []string, kept bool) models.TranslationReview {
+	return models.TranslationReview{
+		Language:  lang,
+		Problems:  problems,
+		Kept:      kept,
+		Model:     c.llm.Model(),
+		CreatedAt: time.Now(),
 	}
-
-	return fmt.Sprintf(`%sAnalyze this job posting and extract the content into three distinct sections.
-
-Job Title: %s
-
-Job Description:
-%s
-
-Extract and categorize the content into:
-
-1. TASKS: What the employee will actually do day-to-day. Include responsibilities, activities, and work tasks.
-
-2. REQUIREMENTS: Skills, experience, qualifications, education, and competencies needed. Include both hard and soft skills.
-
-3. OFFER: What the company offers - salary, benefits, perks, work environment, career opportunities, work-life balance.
-
-Rules:
-- Remove marketing fluff and generic company descriptions
-- Use bullet points (- item) for each point
-- Be concise but complete
-- Keep the output in the same language as the original
-- If a section has no content, return an empty string
-
-Return as JSON:
-{
-  "tasks": "- task 1\n- task 2...",
-  "requirements": "- requirement 1\n- requirement 2...",
-  "offer": "- benefit 1\n- benefit 2..."
-}`, langHint, title, description)
 }
 
-// buildTranslationPrompt creates the prompt for translating normalized content.
-func buildTranslationPrompt(title string, normalized *models.NormalizedContent, sourceLanguage, targetLanguage string) string {
-	sourceName := getLanguageName(sourceLanguage)
-	targetName := getLanguageName(targetLanguage)
-
-	return fmt.Sprintf(`Translate this job content from %s to %s.
-
-Title: %s
-
-Tasks:
-%s
-
-Requirements:
-%s
-
-Offer:
-%s
-
-Rules:
-- Maintain the bullet point format
-- Preserve professional tone
-- Keep technical terms where appropriate
-- Translate section content accurately
-
-Return as JSON:
-{
-  "title": "translated title",
-  "tasks": "translated tasks with bullet points",
-  "requirements": "translated requirements with bullet points",
-  "offer": "translated offer with bullet points"
-}`, sourceName, targetName, title, normalized.Tasks, normalized.Requirements, normalized.Offer)
+// failureProblems returns the problems of a failed translation: the validation problems of its
+// last answer, or the error itself.
+func failureProblems(err error) []string {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr.Problems
+	}
+	return []string{err.Error()}
 }
 
-// buildRawTranslationPrompt creates the prompt for translating raw descriptions.
-func buildRawTranslationPrompt(title, description, sourceLanguage, targetLanguage string) string {
-	sourceName := getLanguageName(sourceLanguage)
-	targetName := getLanguageName(targetLanguage)
-
-	return fmt.Sprintf(`Translate this job posting from %s to %s.
-
-Title: %s
-
-Description:
-%s
-
-Rules:
-- Maintain the original formatting (bullet points, paragraphs, etc.)
-- Preserve professional tone
-- Keep technical terms where appropriate
-- Translate accurately while keeping natural flow
+// render renders a version of a prompt template (0 = the one in use).
+func (c *Client) render(name string, version int, data PromptData) (llm.Prompt, string, error) {
+	t, err := c.templates.Get(name, version)
+	if err != nil {
+		return llm.Prompt{}, "", err
+	}
+	prompt, err := t.Render(data)
+	if err != nil {
+		return llm.Prompt{}, "", err
+	}
+	return t.Prompt, prompt, nil
+}
 
-Return as JSON:
-{
-  "title": "translated title",
-  "description": "translated description"
-}`, sourceName, targetName, title, description)
+// translationData is the input of the translation prompt.
+func translationData(title string, normalized *models.NormalizedContent, sourceLanguage, targetLanguage string, terms []models.GlossaryTerm) PromptData {
+	return PromptData{
+		Title:          title,
+		Tasks:          normalized.Tasks,
+		Requirements:   normalized.Requirements,
+		Offer:          normalized.Offer,
+		SourceLanguage: sourceLanguage,
+		SourceName:     getLanguageName(sourceLanguage),
+		TargetLanguage: targetLanguage,
+		TargetName:     getLanguageName(targetLanguage),
+		Glossary:       terms,
+	}
 }
 
-// extractText extracts text from Gemini response parts.
-func extractText(parts []genai.Part) string {
-	var texts []string
-	for _, part := range parts {
-		if text, ok := part.(genai.Text); ok {
-			texts = append(texts, string(text))
-		}
+// rawTranslationData is the input of the raw translation prompt.
+func rawTranslationData(title, description, sourceLanguage, targetLanguage string, terms []models.GlossaryTerm) PromptData {
+	return PromptData{
+		Title:          title,
+		Description:    description,
+		SourceLanguage: sourceLanguage,
+		SourceName:     getLanguageName(sourceLanguage),
+		TargetLanguage: targetLanguage,
+		TargetName:     getLanguageName(targetLanguage),
+		Glossary:       terms,
 	}
-	return strings.Join(texts, "")
 }
 
 // extractJSONFromMarkdown extracts JSON from markdown code blocks.
@@ -0,0 +1,154 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"llmkit/llm"
+)
+
+// scriptedProvider answers JSON prompts with its answers in order and records the prompts.
+type scriptedProvider struct {
+	llm.Provider
+	answers []string
+	prompts []string
+}
+
+func newScriptedProvider(answers ...string) *scriptedProvider {
+	return &scriptedProvider{Provider: llm.NewFake(llm.Config{}), answers: answers}
+}
+
+func (p *scriptedProvider) GenerateJSON(ctx context.Context, prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	if len(p.answers) == 0 {
+		return "", errors.New("no scripted answer left")
+	}
+	answer := p.answers[0]
+	p.answers = p.answers[1:]
+	return answer, nil
+}
+
+// mapCache is an llm.Cache in memory.
+type mapCache map[string]string
+
+func (c mapCache) Get(ctx context.Context, p llm.Prompt, key string) (string, bool) {
+	answer, ok := c[key]
+	return answer, ok
+}
+
+func (c mapCache) Put(ctx context.Context, p llm.Prompt, key, answer string) {
+	c[key] = answer
+}
+
+var testPrompt = llm.Prompt{Name: PromptLanguage, Version: 1}
+
+const (
+	validLanguage   = `{"language": "de", "confidence": 0.9}`
+	invalidLanguage = `{"language": "German", "confidence": 0.9}`
+)
+
+// validateLanguage is the validation of DetectLanguage.
+func validateLanguage(text string) []string {
+	_, problems := decodeLanguage(text)
+	return problems
+}
+
+func TestGenerateRepairsInvalidAnswer(t *testing.T) {
+	provider := newScriptedProvider(invalidLanguage, validLanguage)
+	cache := mapCache{}
+	client := &Client{llm: provider, cache: cache, repairAttempts: 2}
+
+	text, issues, err := client.generate(context.Background(), testPrompt, "prompt", "language", languageFormat, validateLanguage, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if text != validLanguage || len(issues) > 0 {
+		t.Errorf("generate = %q, %v, want the repaired answer without issues", text, issues)
+	}
+	if len(provider.prompts) != 2 {
+		t.Fatalf("got %d calls, want 2", len(provider.prompts))
+	}
+	if repair := provider.prompts[1]; !strings.Contains(repair, invalidLanguage) || !strings.Contains(repair, "two-letter ISO 639-1 code") {
+		t.Errorf("repair prompt lacks the rejected answer or its problem:\n%s", repair)
+	}
+	if cache[llm.CacheKey(provider, testPrompt, "prompt")] != validLanguage {
+		t.Errorf("repaired answer not cached under the original prompt: %v", cache)
+	}
+}
+
+func TestGenerateReturnsValidationErrorAfterRepairs(t *testing.T) {
+	provider := newScriptedProvider(invalidLanguage, invalidLanguage, validLanguage)
+	cache := mapCache{}
+	client := &Client{llm: provider, cache: cache, repairAttempts: 1}
+
+	_, _, err := client.generate(context.Background(), testPrompt, "prompt", "language", languageFormat, validateLanguage, nil)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("generate error = %v, want a *ValidationError", err)
+	}
+	if verr.Schema != "language" || len(verr.Problems) == 0 {
+		t.Errorf("validation error = %+v, want the problems of the last answer", verr)
+	}
+	if !errors.Is(err, llm.ErrInvalidOutput) {
+		t.Errorf("generate error %v does not match llm.ErrInvalidOutput", err)
+	}
+	if len(provider.prompts) != 2 {
+		t.Errorf("got %d calls, want 2 (1 repair)", len(provider.prompts))
+	}
+	if len(cache) > 0 {
+		t.Errorf("rejected answer cached: %v", cache)
+	}
+}
+
+func TestGenerateIgnoresStaleCachedAnswer(t *testing.T) {
+	provider := newScriptedProvider(validLanguage)
+	key := llm.CacheKey(provider, testPrompt, "prompt")
+	cache := mapCache{key: invalidLanguage}
+	client := &Client{llm: provider, cache: cache}
+
+	text, _, err := client.generate(context.Background(), testPrompt, "prompt", "language", languageFormat, validateLanguage, nil)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if text != validLanguage || len(provider.prompts) != 1 {
+		t.Errorf("generate = %q after %d calls, want a fresh answer", text, len(provider.prompts))
+	}
+	if cache[key] != validLanguage {
+		t.Errorf("cached answer = %q, want it replaced by the fresh one", cache[key])
+	}
+
+	// The valid answer now comes from the cache
+	text, _, err = client.generate(context.Background(), testPrompt, "prompt", "language", languageFormat, validateLanguage, nil)
+	if err != nil || text != validLanguage || len(provider.prompts) != 1 {
+		t.Errorf("generate = %q, %v after %d calls, want the cached answer", text, err, len(provider.prompts))
+	}
+}
+
+func TestGenerateKeepsAnswerWithReviewIssues(t *testing.T) {
+	first := `{"language": "de", "confidence": 0.5}`
+	provider := newScriptedProvider(first, validLanguage)
+	cache := mapCache{}
+	client := &Client{llm: provider, cache: cache, repairAttempts: 1}
+
+	review := func(text string) []string {
+		return []string{"the confidence is a guess"}
+	}
+	text, issues, err := client.generate(context.Background(), testPrompt, "prompt", "language", languageFormat, validateLanguage, review)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if text != validLanguage {
+		t.Errorf("generate = %q, want the last valid answer", text)
+	}
+	if len(issues) != 1 || issues[0] != "the confidence is a guess" {
+		t.Errorf("issues = %v, want the review issue", issues)
+	}
+	if len(provider.prompts) != 2 || !strings.Contains(provider.prompts[1], "the confidence is a guess") {
+		t.Errorf("review issue not sent back for repair in %d calls", len(provider.prompts))
+	}
+	if len(cache) > 0 {
+		t.Errorf("answer with review issues cached: %v", cache)
+	}
+}
@@ -0,0 +1,217 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"ai_job_processing/internal/models"
+	"llmkit/llm"
+)
+
+// EvalJob is a fixture job for comparing prompt versions (see Evaluate).
+type EvalJob struct {
+	ID             string                    `json:"id"`
+	Title          string                    `json:"title"`
+	Description    string                    `json:"description"`
+	SourceLanguage string                    `json:"source_language,omitempty"` // Detected if empty
+	Normalized     *models.NormalizedContent `json:"normalized,omitempty"`      // Input of the translation prompt; normalized if empty
+}
+
+// EvalResult is the outcome of one prompt version on one fixture job.
+type EvalResult struct {
+	JobID    string        `json:"job_id"`
+	Valid    bool          `json:"valid"`              // The first answer passed output validation, without repairs
+	Problems []string      `json:"problems,omitempty"` // Validation problems, or the provider error
+	Issues   []string      `json:"issues,omitempty"`   // Quality issues of a valid translation
+	Length   float64       `json:"length"`             // Length of the answer's content relative to its input
+	Coverage float64       `json:"coverage"`           // Share of the expected sections that have content
+	Duration time.Duration `json:"duration"`
+	Error    bool          `json:"error,omitempty"` // The provider failed; Problems holds the error
+}
+
+// EvalReport summarizes one prompt version over a fixture set. Means are over valid answers.
+type EvalReport struct {
+	Prompt       string        `json:"prompt"`
+	Jobs         int           `json:"jobs"`
+	Valid        int           `json:"valid"`
+	Errors       int           `json:"errors"`
+	WithIssues   int           `json:"with_issues"`
+	MeanLength   float64       `json:"mean_length"`
+	MeanCoverage float64       `json:"mean_coverage"`
+	MeanDuration time.Duration `json:"mean_duration"`
+	Results      []EvalResult  `json:"results"`
+}
+
+// PrepareEval fills in what a prompt needs from the fixture jobs with the prompts in use: the
+// source language of jobs without one and, for the translation prompt, the normalized content.
+// Preparing once keeps the input of the compared versions the same.
+func (c *Client) PrepareEval(ctx context.Context, name string, jobs []EvalJob) error {
+	for i := range jobs {
+		job := &jobs[i]
+		if job.SourceLanguage == "" {
+			detection, err := c.DetectLanguage(ctx, job.Title, job.Description)
+			if err != nil {
+				return fmt.Errorf("failed to detect the language of %s: %w", job.ID, err)
+			}
+			job.SourceLanguage = detection.Language
+		}
+		if name == PromptTranslation && job.Normalized == nil {
+			normalized, err := c.NormalizeJobDescription(ctx, job.Title, job.Description, job.SourceLanguage)
+			if err != nil {
+				return fmt.Errorf("failed to normalize %s: %w", job.ID, err)
+			}
+			job.Normalized = normalized
+		}
+	}
+	return nil
+}
+
+// Evaluate runs a version of the normalization, translation or raw translation prompt over the
+// fixture jobs and reports schema validity, length and section coverage of the first answers.
+// Answers are neither cached nor repaired. Translations go into targetLanguage; jobs already
+// in it are left out. Quota errors and cancellation stop the evaluation.
+func (c *Client) Evaluate(ctx context.Context, name string, version int, jobs []EvalJob, targetLanguage string) (*EvalReport, error) {
+	if name != PromptNormalization && name != PromptTranslation && name != PromptRawTranslation {
+		return nil, fmt.Errorf("prompt %q cannot be evaluated, use %s, %s or %s", name, PromptNormalization, PromptTranslation, PromptRawTranslation)
+	}
+	t, err := c.templates.Get(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &EvalReport{Prompt: t.Prompt.String()}
+	var totalDuration time.Duration
+	for _, job := range jobs {
+		if name != PromptNormalization && job.SourceLanguage == targetLanguage {
+			continue
+		}
+
+		result, err := c.evaluate(ctx, t, job, targetLanguage)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Jobs++
+		totalDuration += result.Duration
+		switch {
+		case result.Error:
+			report.Errors++
+		case result.Valid:
+			report.Valid++
+			report.MeanLength += result.Length
+			report.MeanCoverage += result.Coverage
+			if len(result.Issues) > 0 {
+				report.WithIssues++
+			}
+		}
+		report.Results = append(report.Results, *result)
+	}
+
+	if report.Valid > 0 {
+		report.MeanLength /= float64(report.Valid)
+		report.MeanCoverage /= float64(report.Valid)
+	}
+	if report.Jobs > 0 {
+		report.MeanDuration = totalDuration / time.Duration(report.Jobs)
+	}
+	return report, nil
+}
+
+// evaluate runs a prompt template on one job.
+func (c *Client) evaluate(ctx context.Context, t *Template, job EvalJob, targetLanguage string) (*EvalResult, error) {
+	var data PromptData
+	var s schema
+	switch t.Prompt.Name {
+	case PromptNormalization:
+		data, s = PromptData{Title: job.Title, Description: job.Description, SourceLanguage: job.SourceLanguage}, normalizationSchema
+	case PromptTranslation:
+		if job.Normalized == nil {
+			return nil, fmt.Errorf("job %s has no normalized content to translate", job.ID)
+		}
+		data, s = translationData(job.Title, job.Normalized, job.SourceLanguage, targetLanguage, nil), translationSchema
+	default:
+		data, s = rawTranslationData(job.Title, job.Description, job.SourceLanguage, targetLanguage, nil), rawTranslationSchema
+	}
+
+	prompt, err := t.Render(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EvalResult{JobID: job.ID}
+	start := time.Now()
+	text, err := c.llm.GenerateJSON(ctx, prompt)
+	result.Duration = time.Since(start)
+
+	switch {
+	case errors.Is(err, llm.ErrQuota) || ctx.Err() != nil:
+		return nil, err
+	case errors.Is(err, llm.ErrEmptyResponse):
+		result.Problems = []string{"the answer was empty"}
+		return result, nil
+	case err != nil:
+		result.Error, result.Problems = true, []string{err.Error()}
+		return result, nil
+	}
+
+	values, problems := s.decode(text)
+	if len(problems) > 0 {
+		result.Problems = problems
+		return result, nil
+	}
+
+	switch t.Prompt.Name {
+	case PromptNormalization:
+		n := normalizedFrom(values)
+		result.Problems = checkNormalized(n, job.Description)
+		result.Length = lengthRatio(n.Tasks+n.Requirements+n.Offer, job.Description)
+		result.Coverage = float64(countFilled(n.Tasks, n.Requirements, n.Offer)) / 3
+	case PromptTranslation:
+		tr := translatedFrom(values, targetLanguage)
+		src := job.Normalized
+		result.Problems = checkTranslated(tr, src, targetLanguage)
+		result.Issues = reviewTranslated(tr, job.Title, src, "", nil)
+		result.Length = lengthRatio(tr.Tasks+tr.Requirements+tr.Offer, src.Tasks+src.Requirements+src.Offer)
+		result.Coverage = 1
+		if want := countFilled(src.Tasks, src.Requirements, src.Offer); want > 0 {
+			kept := 0
+			for _, pair := range [][2]string{{src.Tasks, tr.Tasks}, {src.Requirements, tr.Requirements}, {src.Offer, tr.Offer}} {
+				if pair[0] != "" && pair[1] != "" {
+					kept++
+				}
+			}
+			result.Coverage = float64(kept) / float64(want)
+		}
+	default:
+		tr := translatedFrom(values, targetLanguage)
+		result.Problems = checkTranslated(tr, nil, targetLanguage)
+		result.Issues = reviewTranslated(tr, job.Title, nil, job.Description, nil)
+		result.Length = lengthRatio(tr.Description, job.Description)
+		result.Coverage = float64(countFilled(tr.Title, tr.Description)) / 2
+	}
+	result.Valid = len(result.Problems) == 0
+	return result, nil
+}
+
+// lengthRatio returns the length of text relative to source, in characters.
+func lengthRatio(text, source string) float64 {
+	n := utf8.RuneCountInString(source)
+	if n == 0 {
+		return 0
+	}
+	return float64(utf8.RuneCountInString(text)) / float64(n)
+}
+
+// countFilled counts the non-empty values.
+func countFilled(values ...string) int {
+	n := 0
+	for _, v := range values {
+		if v != "" {
+			n++
+		}
+	}
+	return n
+}
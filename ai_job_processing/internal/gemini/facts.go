@@ -0,0 +1,296 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"ai_job_processing/internal/models"
+	"ai_job_processing/internal/skills"
+)
+
+const (
+	// maxSkills bounds the skills kept per list; longer lists are mostly noise
+	maxSkills = 30
+
+	// maxYearsExperience is the highest plausible experience requirement
+	maxYearsExperience = 40
+)
+
+// factsFormat describes the facts answer for repair prompts.
+const factsFormat = `a JSON object with exactly these fields: "required_skills" and "nice_to_have_skills" (lists of strings), "years_of_experience" (integer or null), "education_level", "remote_policy" and "seniority" (string or null), "languages" (list of {"language", "level", "required"}) and "salary" ({"min", "max", "currency", "period"} or null)`
+
+var (
+	educationLevels = enum(models.EducationNone, models.EducationApprenticeship, models.EducationHigherVocational,
+		models.EducationBachelor, models.EducationMaster, models.EducationDoctorate)
+	remotePolicies = enum(models.RemoteOnsite, models.RemoteHybrid, models.RemoteFull)
+	seniorities    = enum(models.SeniorityIntern, models.SeniorityJunior, models.SeniorityMid,
+		models.SenioritySenior, models.SeniorityLead, models.SeniorityExecutive)
+	cefrLevels    = enum("A1", "A2", "B1", "B2", "C1", "C2", "native")
+	salaryPeriods = enum("year", "month", "hour")
+)
+
+// factsFields are the top-level fields of the facts answer. All are required.
+var factsFields = []string{
+	"required_skills", "nice_to_have_skills", "years_of_experience", "education_level",
+	"languages", "remote_policy", "salary", "seniority",
+}
+
+// factsAnswer is the facts answer as the model returns it.
+type factsAnswer struct {
+	RequiredSkills    []string `json:"required_skills"`
+	NiceToHaveSkills  []string `json:"nice_to_have_skills"`
+	YearsOfExperience *float64 `json:"years_of_experience"`
+	EducationLevel    *string  `json:"education_level"`
+	Languages         []struct {
+		Language string  `json:"language"`
+		Level    *string `json:"level"`
+		Required *bool   `json:"required"`
+	} `json:"languages"`
+	RemotePolicy *string `json:"remote_policy"`
+	Salary       *struct {
+		Min      *float64 `json:"min"`
+		Max      *float64 `json:"max"`
+		Currency string   `json:"currency"`
+		Period   string   `json:"period"`
+	} `json:"salary"`
+	Seniority *string `json:"seniority"`
+}
+
+// ExtractFacts extracts skills, experience, education, languages, remote policy, salary and
+// seniority from a job description. Skills are canonicalized (see skills.Canonicalize).
+func (c *Client) ExtractFacts(ctx context.Context, title, description, sourceLanguage string) (*models.JobFacts, error) {
+	start := time.Now()
+
+	data := PromptData{Title: title, Description: description, SourceLanguage: sourceLanguage}
+	if sourceLanguage != "" {
+		data.SourceName = getLanguageName(sourceLanguage)
+	}
+	p, prompt, err := c.render(PromptFacts, 0, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var facts *models.JobFacts
+	_, _, err = c.generate(ctx, p, prompt, p.Name, factsFormat, func(text string) []string {
+		var problems []string
+		facts, problems = decodeFacts(text)
+		return problems
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	facts.Model = c.llm.Model()
+	facts.PromptVersion = p.Version
+	facts.ExtractedAt = time.Now()
+
+	slog.Debug("fact extraction completed",
+		"skills", len(facts.Skills),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
+	return facts, nil
+}
+
+// decodeFacts parses and validates a facts answer. Enum values are matched case-insensitively,
+// skills are canonicalized and deduplicated (a skill both required and nice to have counts as
+// required) and placeholder values count as null.
+func decodeFacts(text string) (*models.JobFacts, []string) {
+	raw, err := decodeObject(text)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("the answer is not a valid JSON object (%v)", err)}
+	}
+
+	var problems []string
+	for _, name := range factsFields {
+		if _, ok := raw[name]; !ok {
+			problems = append(problems, fmt.Sprintf("field %q is missing", name))
+		}
+	}
+	var unknown []string
+	for name := range raw {
+		if !containsString(factsFields, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		problems = append(problems, fmt.Sprintf("field %q is not allowed", name))
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	var a factsAnswer
+	dec := json.NewDecoder(bytes.NewReader(mustMarshal(raw)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&a); err != nil {
+		return nil, []string{fmt.Sprintf("the answer does not match the expected types (%v)", err)}
+	}
+
+	facts := &models.JobFacts{
+		Skills:    make([]models.JobSkill, 0),
+		Languages: make([]models.JobLanguage, 0),
+	}
+
+	// Skills: required first, so a duplicate nice-to-have entry is dropped
+	seen := make(map[string]bool)
+	for _, list := range []struct {
+		names    []string
+		required bool
+	}{{a.RequiredSkills, true}, {a.NiceToHaveSkills, false}} {
+		kept := 0
+		for _, name := range list.names {
+			if kept == maxSkills {
+				break
+			}
+			name = skills.Canonicalize(name)
+			key := strings.ToLower(name)
+			if name == "" || placeholders[key] || seen[key] {
+				continue
+			}
+			seen[key] = true
+			kept++
+			facts.Skills = append(facts.Skills, models.JobSkill{Name: name, Required: list.required})
+		}
+	}
+
+	if a.YearsOfExperience != nil {
+		years := *a.YearsOfExperience
+		if years < 0 || years > maxYearsExperience {
+			problems = append(problems, fmt.Sprintf("field \"years_of_experience\" must be between 0 and %d", maxYearsExperience))
+		} else {
+			n := int(math.Round(years))
+			facts.MinYearsExperience = &n
+		}
+	}
+
+	var p string
+	facts.EducationLevel, p = enumValue("education_level", a.EducationLevel, educationLevels)
+	problems = appendProblem(problems, p)
+	facts.RemotePolicy, p = enumValue("remote_policy", a.RemotePolicy, remotePolicies)
+	problems = appendProblem(problems, p)
+	facts.Seniority, p = enumValue("seniority", a.Seniority, seniorities)
+	problems = appendProblem(problems, p)
+
+	languages := make(map[string]bool)
+	for i, l := range a.Languages {
+		code := strings.ToLower(strings.TrimSpace(l.Language))
+		if len(code) != 2 || strings.Trim(code, "abcdefghijklmnopqrstuvwxyz") != "" {
+			problems = append(problems, fmt.Sprintf("field \"languages[%d].language\" must be a two-letter ISO 639-1 code, got %q", i, l.Language))
+			continue
+		}
+		level, p := enumValue(fmt.Sprintf("languages[%d].level", i), l.Level, cefrLevels)
+		if p != "" {
+			problems = append(problems, p)
+			continue
+		}
+		if l.Required == nil {
+			problems = append(problems, fmt.Sprintf("field \"languages[%d].required\" must be true or false", i))
+			continue
+		}
+		if languages[code] {
+			continue
+		}
+		languages[code] = true
+		facts.Languages = append(facts.Languages, models.JobLanguage{Language: code, Level: level, Required: *l.Required})
+	}
+
+	if s := a.Salary; s != nil && (s.Min != nil || s.Max != nil) {
+		salary := &models.Salary{
+			Currency: strings.ToUpper(strings.TrimSpace(s.Currency)),
+			Period:   strings.ToLower(strings.TrimSpace(s.Period)),
+		}
+		if len(salary.Currency) != 3 || strings.Trim(salary.Currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			problems = append(problems, fmt.Sprintf("field \"salary.currency\" must be a three-letter ISO 4217 code, got %q", s.Currency))
+		}
+		if !salaryPeriods[salary.Period] {
+			problems = append(problems, fmt.Sprintf("field \"salary.period\" must be one of year, month or hour, got %q", s.Period))
+		}
+		for _, bound := range []struct {
+			name  string
+			value *float64
+			dst   **int
+		}{{"min", s.Min, &salary.Min}, {"max", s.Max, &salary.Max}} {
+			if bound.value == nil {
+				continue
+			}
+			if *bound.value <= 0 {
+				problems = append(problems, fmt.Sprintf("field \"salary.%s\" must be positive", bound.name))
+				continue
+			}
+			n := int(math.Round(*bound.value))
+			*bound.dst = &n
+		}
+		if salary.Min != nil && salary.Max != nil && *salary.Min > *salary.Max {
+			problems = append(problems, `field "salary.min" must not be greater than "salary.max"`)
+		}
+		facts.Salary = salary
+	}
+
+	return facts, problems
+}
+
+// enum builds a set of allowed values.
+func enum(values ...string) map[string]bool {
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[v] = true
+	}
+	return m
+}
+
+// enumValue validates an optional enum field. Values are matched case-insensitively (CEFR
+// levels are returned in upper case); null and placeholders give "".
+func enumValue(name string, value *string, allowed map[string]bool) (string, string) {
+	if value == nil {
+		return "", ""
+	}
+	v := strings.TrimSpace(*value)
+	if v == "" || placeholders[strings.ToLower(v)] {
+		return "", ""
+	}
+	for a := range allowed {
+		if strings.EqualFold(a, v) {
+			return a, ""
+		}
+	}
+	options := make([]string, 0, len(allowed))
+	for a := range allowed {
+		options = append(options, a)
+	}
+	sort.Strings(options)
+	return "", fmt.Sprintf("field %q must be one of %s or null, got %q", name, strings.Join(options, ", "), v)
+}
+
+// appendProblem appends p unless it is empty.
+func appendProblem(problems []string, p string) []string {
+	if p == "" {
+		return problems
+	}
+	return append(problems, p)
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mustMarshal re-encodes a decoded JSON object for strict decoding. It cannot fail for raw
+// messages.
+func mustMarshal(raw map[string]json.RawMessage) []byte {
+	b, _ := json.Marshal(raw)
+	return b
+}
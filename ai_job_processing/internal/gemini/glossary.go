@@ -0,0 +1,67 @@
+package gemini
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"ai_job_processing/internal/models"
+)
+
+// glossaryFor returns the glossary terms that occur in text and apply to targetLanguage:
+// terms kept as is in every language and terms with a translation into targetLanguage.
+func glossaryFor(glossary []models.GlossaryTerm, text, targetLanguage string) []models.GlossaryTerm {
+	var terms []models.GlossaryTerm
+	for _, t := range glossary {
+		if t.Translation != "" && t.TargetLanguage != targetLanguage {
+			continue
+		}
+		if containsTerm(text, t.Term) {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// checkGlossary reports the glossary terms that the translation does not use.
+func checkGlossary(terms []models.GlossaryTerm, translated string) []string {
+	var problems []string
+	for _, t := range terms {
+		switch {
+		case t.Translation == "" && !containsTerm(translated, t.Term):
+			problems = append(problems, fmt.Sprintf("glossary term %q must be kept unchanged", t.Term))
+		case t.Translation != "" && !containsTerm(translated, t.Translation):
+			problems = append(problems, fmt.Sprintf("glossary term %q must be translated as %q", t.Term, t.Translation))
+		}
+	}
+	return problems
+}
+
+// containsTerm reports whether text contains term as a whole word or phrase, ignoring case.
+func containsTerm(text, term string) bool {
+	text, term = strings.ToLower(text), strings.ToLower(strings.TrimSpace(term))
+	if term == "" {
+		return false
+	}
+
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(term)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		offset = end
+	}
+	return false
+}
+
+// isWordRune reports whether r is part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,121 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"ai_job_processing/internal/language"
+	"ai_job_processing/internal/models"
+)
+
+// maxDetectionText bounds how much of a description is sent to the model for language
+// detection; the opening paragraphs are enough
+const maxDetectionText = 1500
+
+// languageFormat describes the language answer for repair prompts.
+const languageFormat = `a JSON object with exactly these fields: "language" (ISO 639-1 code) and "confidence" (number between 0 and 1)`
+
+// languageFields are the fields of the language answer. Both are required.
+var languageFields = []string{"language", "confidence"}
+
+// DetectLanguage detects the language a job posting is written in. The local detector (see
+// language.Detect) decides when enough of the text's function words agree; short, mixed or
+// unsupported texts go to the model.
+func (c *Client) DetectLanguage(ctx context.Context, title, description string) (*models.LanguageDetection, error) {
+	if lang, confidence := language.Detect(title + "\n" + description); lang != "" && confidence >= minLanguageConfidence {
+		return &models.LanguageDetection{
+			Language:   lang,
+			Confidence: math.Round(confidence*100) / 100,
+			Method:     models.DetectionText,
+			DetectedAt: time.Now(),
+		}, nil
+	}
+
+	start := time.Now()
+
+	p, prompt, err := c.render(PromptLanguage, 0, PromptData{Title: title, Description: detectionText(description)})
+	if err != nil {
+		return nil, err
+	}
+
+	var detection *models.LanguageDetection
+	_, _, err = c.generate(ctx, p, prompt, p.Name, languageFormat, func(text string) []string {
+		var problems []string
+		detection, problems = decodeLanguage(text)
+		return problems
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	detection.Method = models.DetectionLLM
+	detection.DetectedAt = time.Now()
+
+	slog.Debug("language detection completed",
+		"language", detection.Language,
+		"confidence", detection.Confidence,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
+	return detection, nil
+}
+
+// decodeLanguage parses and validates a language answer.
+func decodeLanguage(text string) (*models.LanguageDetection, []string) {
+	raw, err := decodeObject(text)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("the answer is not a valid JSON object (%v)", err)}
+	}
+
+	var problems []string
+	for _, name := range languageFields {
+		if _, ok := raw[name]; !ok {
+			problems = append(problems, fmt.Sprintf("field %q is missing", name))
+		}
+	}
+	var unknown []string
+	for name := range raw {
+		if !containsString(languageFields, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		problems = append(problems, fmt.Sprintf("field %q is not allowed", name))
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	detection := &models.LanguageDetection{}
+
+	code, ok := decodeString(raw["language"])
+	code = strings.ToLower(code)
+	if !ok || len(code) != 2 || strings.Trim(code, "abcdefghijklmnopqrstuvwxyz") != "" {
+		problems = append(problems, fmt.Sprintf("field \"language\" must be a two-letter ISO 639-1 code, got %s", raw["language"]))
+	}
+	detection.Language = code
+
+	var confidence *float64
+	if err := json.Unmarshal(raw["confidence"], &confidence); err != nil || confidence == nil || *confidence < 0 || *confidence > 1 {
+		problems = append(problems, fmt.Sprintf("field \"confidence\" must be a number between 0 and 1, got %s", raw["confidence"]))
+	} else {
+		detection.Confidence = math.Round(*confidence*100) / 100
+	}
+
+	return detection, problems
+}
+
+// detectionText shortens a description to the part sent for language detection.
+func detectionText(description string) string {
+	if len(description) > maxDetectionText {
+		return strings.ToValidUTF8(description[:maxDetectionText], "") + "..."
+	}
+	return description
+}
@@ -0,0 +1,45 @@
+package gemini
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeLanguage(t *testing.T) {
+	tests := []struct {
+		name           string
+		text           string
+		wantLanguage   string
+		wantConfidence float64
+		wantProblem    string // Substring of the only problem ("" = valid)
+	}{
+		{"valid", `{"language": "fr", "confidence": 0.876}`, "fr", 0.88, ""},
+		{"upper case code", `{"language": "DE", "confidence": 1}`, "de", 1, ""},
+		{"markdown fence", "```json\n{\"language\": \"it\", \"confidence\": 0.7}\n```", "it", 0.7, ""},
+		{"not json", `German`, "", 0, "not a valid JSON object"},
+		{"missing confidence", `{"language": "de"}`, "", 0, `field "confidence" is missing`},
+		{"unknown field", `{"language": "de", "confidence": 0.9, "reason": "umlauts"}`, "", 0, `field "reason" is not allowed`},
+		{"language name", `{"language": "German", "confidence": 0.9}`, "", 0, "two-letter ISO 639-1 code"},
+		{"three-letter code", `{"language": "deu", "confidence": 0.9}`, "", 0, "two-letter ISO 639-1 code"},
+		{"confidence above 1", `{"language": "de", "confidence": 90}`, "", 0, "between 0 and 1"},
+		{"confidence null", `{"language": "de", "confidence": null}`, "", 0, "between 0 and 1"},
+		{"confidence string", `{"language": "de", "confidence": "high"}`, "", 0, "between 0 and 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detection, problems := decodeLanguage(tt.text)
+			if tt.wantProblem == "" {
+				if len(problems) > 0 {
+					t.Fatalf("problems = %v, want none", problems)
+				}
+				if detection.Language != tt.wantLanguage || detection.Confidence != tt.wantConfidence {
+					t.Errorf("detection = %s %.2f, want %s %.2f", detection.Language, detection.Confidence, tt.wantLanguage, tt.wantConfidence)
+				}
+				return
+			}
+			if len(problems) != 1 || !strings.Contains(problems[0], tt.wantProblem) {
+				t.Errorf("problems = %v, want one containing %q", problems, tt.wantProblem)
+			}
+		})
+	}
+}
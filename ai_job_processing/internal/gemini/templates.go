@@ -0,0 +1,227 @@
+package gemini
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"ai_job_processing/internal/models"
+	"llmkit/llm"
+)
+
+//go:embed prompts/*.tmpl
+var embeddedPrompts embed.FS
+
+// Names of the prompt templates. Each needs at least one version.
+const (
+	PromptNormalization  = "normalization"
+	PromptTranslation    = "translation"
+	PromptRawTranslation = "raw_translation"
+	PromptFacts          = "facts"
+	PromptLanguage       = "language"
+)
+
+var promptNames = []string{PromptNormalization, PromptTranslation, PromptRawTranslation, PromptFacts, PromptLanguage}
+
+// versionedFile matches the file name of a template version, e.g. translation.v2.tmpl. Other
+// .tmpl files hold shared {{define}} blocks such as the glossary rules.
+var versionedFile = regexp.MustCompile(`^([a-z_]+)\.v([1-9][0-9]*)\.tmpl$`)
+
+// PromptData is the input rendered into a prompt template. Each template uses the fields of its
+// prompt.
+type PromptData struct {
+	Title          string
+	Description    string
+	Tasks          string
+	Requirements   string
+	Offer          string
+	SourceLanguage string // ISO 639-1 code, "" if unknown
+	SourceName     string // English name of the source language, "" if unknown
+	TargetLanguage string
+	TargetName     string
+	Glossary       []models.GlossaryTerm // Terms occurring in the text (translations)
+}
+
+// Template is one version of a prompt template.
+type Template struct {
+	Prompt llm.Prompt
+	text   *template.Template
+}
+
+// Render renders the template with data.
+func (t *Template) Render(data PromptData) (string, error) {
+	var b strings.Builder
+	if err := t.text.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", t.Prompt, err)
+	}
+	return b.String(), nil
+}
+
+// Templates holds the versions of each prompt template and the version in use.
+type Templates struct {
+	versions map[string]map[int]*Template
+	current  map[string]int
+}
+
+// LoadTemplates loads the prompt templates built into the binary (internal/gemini/prompts) and,
+// if dir is not empty, the ones in dir, which add versions or replace built-in ones. The newest
+// version of each prompt is used unless pinned (e.g. {"translation": 1}).
+func LoadTemplates(dir string, pinned map[string]int) (*Templates, error) {
+	files := make(map[string]string)
+
+	sub, err := fs.Sub(embeddedPrompts, "prompts")
+	if err != nil {
+		return nil, err
+	}
+	if err := readTemplates(sub, files); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := readTemplates(os.DirFS(dir), files); err != nil {
+			return nil, fmt.Errorf("failed to load prompt templates from %s: %w", dir, err)
+		}
+	}
+
+	// Shared blocks are parsed into every version
+	var shared []string
+	for name := range files {
+		if !versionedFile.MatchString(name) {
+			shared = append(shared, name)
+		}
+	}
+	sort.Strings(shared)
+
+	t := &Templates{versions: make(map[string]map[int]*Template), current: make(map[string]int)}
+	for file, text := range files {
+		m := versionedFile.FindStringSubmatch(file)
+		if m == nil {
+			continue
+		}
+		name := m[1]
+		version, _ := strconv.Atoi(m[2])
+		if !containsString(promptNames, name) {
+			return nil, fmt.Errorf("unknown prompt template %s (expected one of %s)", file, strings.Join(promptNames, ", "))
+		}
+
+		tmpl := template.New(file)
+		for _, s := range shared {
+			if _, err := tmpl.New(s).Parse(files[s]); err != nil {
+				return nil, fmt.Errorf("failed to parse prompt template %s: %w", s, err)
+			}
+		}
+		if _, err := tmpl.Parse(text); err != nil {
+			return nil, fmt.Errorf("failed to parse prompt template %s: %w", file, err)
+		}
+
+		v := &Template{Prompt: llm.Prompt{Name: name, Version: version}, text: tmpl}
+		// Catch unknown fields now rather than on the first job
+		if _, err := v.Render(PromptData{Glossary: []models.GlossaryTerm{{Term: "x"}, {Term: "y", Translation: "z"}}}); err != nil {
+			return nil, err
+		}
+
+		if t.versions[name] == nil {
+			t.versions[name] = make(map[int]*Template)
+		}
+		t.versions[name][version] = v
+		t.current[name] = max(t.current[name], version)
+	}
+
+	for _, name := range promptNames {
+		if len(t.versions[name]) == 0 {
+			return nil, fmt.Errorf("prompt template %s has no versions", name)
+		}
+	}
+	for name, version := range pinned {
+		if _, err := t.Get(name, version); err != nil {
+			return nil, err
+		}
+		t.current[name] = version
+	}
+
+	return t, nil
+}
+
+// readTemplates adds the .tmpl files of fsys to files by name.
+func readTemplates(fsys fs.FS, files map[string]string) error {
+	names, err := fs.Glob(fsys, "*.tmpl")
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		// The trailing newline of the file is not part of the prompt
+		files[name] = strings.TrimSuffix(string(b), "\n")
+	}
+	return nil
+}
+
+// Get returns a version of a prompt template; version 0 is the one in use.
+func (t *Templates) Get(name string, version int) (*Template, error) {
+	versions, ok := t.versions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown prompt %q (expected one of %s)", name, strings.Join(promptNames, ", "))
+	}
+	if version == 0 {
+		version = t.current[name]
+	}
+	v, ok := versions[version]
+	if !ok {
+		return nil, fmt.Errorf("prompt %s has no version %d (available: %s)", name, version, joinInts(t.Versions(name)))
+	}
+	return v, nil
+}
+
+// Current returns the prompt versions in use, for invalidating cached answers of others.
+func (t *Templates) Current() []llm.Prompt {
+	prompts := make([]llm.Prompt, len(promptNames))
+	for i, name := range promptNames {
+		prompts[i] = t.versions[name][t.current[name]].Prompt
+	}
+	return prompts
+}
+
+// Versions returns the available versions of a prompt in ascending order.
+func (t *Templates) Versions(name string) []int {
+	versions := make([]int, 0, len(t.versions[name]))
+	for v := range t.versions[name] {
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// ParsePromptVersions parses pinned prompt versions such as "translation=1,facts=2".
+func ParsePromptVersions(s string) (map[string]int, error) {
+	pinned := make(map[string]int)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		version, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(value), "v"))
+		if !ok || err != nil || version < 1 {
+			return nil, fmt.Errorf("invalid prompt version %q, expected name=version", part)
+		}
+		pinned[strings.TrimSpace(name)] = version
+	}
+	return pinned, nil
+}
+
+// joinInts formats numbers as a comma-separated list.
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,250 @@
+package gemini
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"ai_job_processing/internal/language"
+	"ai_job_processing/internal/models"
+	"llmkit/llm"
+)
+
+const (
+	// minLanguageConfidence is the share of function words a detected language needs
+	// before an answer is rejected for being in the wrong language
+	minLanguageConfidence = 0.6
+
+	// maxEchoedAnswer bounds how much of an invalid answer is sent back in a repair prompt
+	maxEchoedAnswer = 4000
+)
+
+// placeholders are answers models give for a section without content. They count as empty.
+var placeholders = map[string]bool{
+	"-": true, "n/a": true, "na": true, "none": true, "null": true, "not specified": true,
+	"not mentioned": true, "keine": true, "keine angaben": true, "nicht angegeben": true,
+	"k.a.": true, "aucun": true, "aucune": true, "non spécifié": true, "non précisé": true,
+	"nessuno": true, "nessuna": true, "non specificato": true, "non indicato": true,
+}
+
+// ValidationError describes why a model answer was rejected. It matches llm.ErrInvalidOutput.
+type ValidationError struct {
+	Schema   string   // Name of the expected answer, e.g. "normalization"
+	Problems []string // One entry per violated rule
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s answer: %s", llm.ErrInvalidOutput, e.Schema, strings.Join(e.Problems, "; "))
+}
+
+// Is makes errors.Is(err, llm.ErrInvalidOutput) true.
+func (e *ValidationError) Is(target error) bool {
+	return target == llm.ErrInvalidOutput
+}
+
+// field is one string property of an expected JSON answer.
+type field struct {
+	name     string
+	nonEmpty bool // The value must not be empty
+}
+
+// schema describes the JSON object a prompt asks for. All fields are required.
+type schema struct {
+	name   string
+	fields []field
+}
+
+var (
+	normalizationSchema = schema{
+		name:   "normalization",
+		fields: []field{{name: "tasks"}, {name: "requirements"}, {name: "offer"}},
+	}
+	translationSchema = schema{
+		name:   "translation",
+		fields: []field{{name: "title", nonEmpty: true}, {name: "tasks"}, {name: "requirements"}, {name: "offer"}},
+	}
+	rawTranslationSchema = schema{
+		name:   "translation",
+		fields: []field{{name: "title", nonEmpty: true}, {name: "description", nonEmpty: true}},
+	}
+)
+
+// decode parses the answer into the schema's fields. Markdown fences and prose around the
+// object are tolerated, as are lists (joined as bullet points) and null (empty); missing,
+// unknown and non-string fields are not. Values are trimmed and placeholders emptied.
+func (s schema) decode(text string) (map[string]string, []string) {
+	raw, err := decodeObject(text)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("the answer is not a valid JSON object (%v)", err)}
+	}
+
+	var problems []string
+	values := make(map[string]string, len(s.fields))
+	for _, f := range s.fields {
+		msg, ok := raw[f.name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("field %q is missing", f.name))
+			continue
+		}
+		value, ok := decodeString(msg)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("field %q must be a string", f.name))
+			continue
+		}
+		if placeholders[strings.ToLower(value)] {
+			value = ""
+		}
+		if f.nonEmpty && value == "" {
+			problems = append(problems, fmt.Sprintf("field %q must not be empty", f.name))
+		}
+		values[f.name] = value
+	}
+
+	var unknown []string
+	for name := range raw {
+		if !s.has(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		problems = append(problems, fmt.Sprintf("field %q is not allowed", name))
+	}
+
+	return values, problems
+}
+
+// has reports whether the schema defines the field.
+func (s schema) has(name string) bool {
+	for _, f := range s.fields {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// describe states the expected format for a repair prompt.
+func (s schema) describe() string {
+	names := make([]string, len(s.fields))
+	for i, f := range s.fields {
+		names[i] = fmt.Sprintf("%q", f.name)
+	}
+	return "a JSON object with exactly these string fields: " + strings.Join(names, ", ")
+}
+
+// decodeObject parses text as a JSON object, falling back to the object inside markdown
+// fences or prose.
+func decodeObject(text string) (map[string]json.RawMessage, error) {
+	var raw map[string]json.RawMessage
+	err := json.Unmarshal([]byte(text), &raw)
+	if err != nil {
+		err = json.Unmarshal([]byte(extractJSONFromMarkdown(text)), &raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("got null")
+	}
+	return raw, nil
+}
+
+// decodeString decodes a string, null or list of strings field value.
+func decodeString(msg json.RawMessage) (string, bool) {
+	if bytes.Equal(bytes.TrimSpace(msg), []byte("null")) {
+		return "", true
+	}
+
+	var s string
+	if err := json.Unmarshal(msg, &s); err == nil {
+		return strings.TrimSpace(s), true
+	}
+
+	var items []string
+	if err := json.Unmarshal(msg, &items); err != nil {
+		return "", false
+	}
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(item), "-•*"))
+		if item != "" {
+			lines = append(lines, "- "+item)
+		}
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// checkNormalized rejects a normalization without any content and one that is not in the
+// language of the original description.
+func checkNormalized(n *models.NormalizedContent, description string) []string {
+	var problems []string
+	if n.Tasks == "" && n.Requirements == "" && n.Offer == "" {
+		problems = append(problems, "all sections are empty, but the job description has content")
+	}
+	if lang, _ := language.Detect(description); lang != "" {
+		problems = append(problems, checkLanguage(n.Tasks+"\n"+n.Requirements+"\n"+n.Offer, lang)...)
+	}
+	return problems
+}
+
+// checkTranslated rejects a translation that dropped a section of the source or is not in
+// the target language.
+func checkTranslated(t *models.TranslatedContent, source *models.NormalizedContent, targetLanguage string) []string {
+	var problems []string
+	if source != nil {
+		sections := []struct {
+			name               string
+			source, translated string
+		}{
+			{"tasks", source.Tasks, t.Tasks},
+			{"requirements", source.Requirements, t.Requirements},
+			{"offer", source.Offer, t.Offer},
+		}
+		for _, s := range sections {
+			if s.source != "" && s.translated == "" {
+				problems = append(problems, fmt.Sprintf("field %q is empty, but the source has content to translate", s.name))
+			}
+		}
+	}
+
+	text := t.Description
+	if source != nil {
+		text = t.Tasks + "\n" + t.Requirements + "\n" + t.Offer
+	}
+	return append(problems, checkLanguage(text, targetLanguage)...)
+}
+
+// checkLanguage rejects text that is confidently detected as another language than want.
+// Languages the detector does not know are not checked.
+func checkLanguage(text, want string) []string {
+	if !language.Supported(want) {
+		return nil
+	}
+	got, confidence := language.Detect(text)
+	if got == "" || got == want || confidence < minLanguageConfidence {
+		return nil
+	}
+	return []string{fmt.Sprintf("the text is in %s, but must be in %s", getLanguageName(got), getLanguageName(want))}
+}
+
+// buildRepairPrompt asks the model to correct an answer that failed validation. format
+// describes the expected answer.
+func buildRepairPrompt(prompt, answer, format string, problems []string) string {
+	if len(answer) > maxEchoedAnswer {
+		answer = answer[:maxEchoedAnswer] + "..."
+	}
+
+	return fmt.Sprintf(`%s
+
+Your previous answer was rejected:
+%s
+
+Previous answer:
+%s
+
+Fix these problems and answer again. Return only %s.`,
+		prompt, "- "+strings.Join(problems, "\n- "), answer, format)
+}
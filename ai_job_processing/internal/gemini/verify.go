@@ -0,0 +1,143 @@
+package gemini
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"ai_job_processing/internal/models"
+)
+
+const (
+	// minRatioLength is the source length in characters from which the length ratio of a
+	// translation is checked; short texts vary too much
+	minRatioLength = 200
+
+	// minLengthRatio and maxLengthRatio bound the plausible length of a translation relative to
+	// its source
+	minLengthRatio = 0.5
+	maxLengthRatio = 2.0
+)
+
+var (
+	numberPattern = regexp.MustCompile(`\d+(?:['’.,]\d{3})*(?:[.,]\d+)?`)
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	urlPattern    = regexp.MustCompile(`(?:https?://|www\.)[^\s)<>"]+[^\s)<>".,;:!?]`)
+)
+
+// reviewTranslated runs the quality checks on a translation that passed checkTranslated: the
+// bullet points per section match the source, numbers, email addresses and URLs are preserved,
+// the length is plausible and the glossary terms are used. source is nil for a raw translation
+// of description. Issues are phrased for a repair prompt.
+func reviewTranslated(t *models.TranslatedContent, title string, source *models.NormalizedContent, description string, glossary []models.GlossaryTerm) []string {
+	var problems []string
+
+	sourceBody, translatedBody := description, t.Description
+	if source != nil {
+		sourceBody = source.Tasks + "\n" + source.Requirements + "\n" + source.Offer
+		translatedBody = t.Tasks + "\n" + t.Requirements + "\n" + t.Offer
+
+		sections := []struct {
+			name               string
+			source, translated string
+		}{
+			{"tasks", source.Tasks, t.Tasks},
+			{"requirements", source.Requirements, t.Requirements},
+			{"offer", source.Offer, t.Offer},
+		}
+		for _, s := range sections {
+			want, got := countBullets(s.source), countBullets(s.translated)
+			if want > 0 && got != want {
+				problems = append(problems, fmt.Sprintf("field %q has %d bullet points, but the source has %d", s.name, got, want))
+			}
+		}
+	}
+
+	sourceText := title + "\n" + sourceBody
+	translatedText := t.Title + "\n" + translatedBody
+
+	problems = appendMissing(problems, "numbers", missingNumbers(sourceText, translatedText))
+	problems = appendMissing(problems, "email addresses", missingVerbatim(emailPattern, sourceText, translatedText))
+	problems = appendMissing(problems, "URLs", missingVerbatim(urlPattern, sourceText, translatedText))
+
+	if n := utf8.RuneCountInString(sourceBody); n >= minRatioLength {
+		ratio := float64(utf8.RuneCountInString(translatedBody)) / float64(n)
+		if ratio < minLengthRatio || ratio > maxLengthRatio {
+			problems = append(problems, fmt.Sprintf("the translation is %.1f times as long as the source, expected between %.1f and %.1f",
+				ratio, minLengthRatio, maxLengthRatio))
+		}
+	}
+
+	terms := glossaryFor(glossary, sourceText, t.Language)
+	return append(problems, checkGlossary(terms, translatedText)...)
+}
+
+// countBullets counts the lines of a section that are bullet points.
+func countBullets(section string) int {
+	n := 0
+	for _, line := range strings.Split(section, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "-") || strings.HasPrefix(line, "•") || strings.HasPrefix(line, "*") {
+			n++
+		}
+	}
+	return n
+}
+
+// missingNumbers returns the numbers of source that translated lacks. Numbers are compared by
+// their digits, so "100'000" may become "100,000".
+func missingNumbers(source, translated string) []string {
+	have := make(map[string]bool)
+	for _, n := range numberPattern.FindAllString(translated, -1) {
+		have[digits(n)] = true
+	}
+
+	var missing []string
+	seen := make(map[string]bool)
+	for _, n := range numberPattern.FindAllString(source, -1) {
+		d := digits(n)
+		if !have[d] && !seen[d] {
+			seen[d] = true
+			missing = append(missing, n)
+		}
+	}
+	return missing
+}
+
+// digits strips everything but the digits from a number, and its leading zeros.
+func digits(n string) string {
+	d := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, n)
+	if d = strings.TrimLeft(d, "0"); d == "" {
+		return "0"
+	}
+	return d
+}
+
+// missingVerbatim returns the matches of pattern in source that translated does not contain
+// as written.
+func missingVerbatim(pattern *regexp.Regexp, source, translated string) []string {
+	var missing []string
+	seen := make(map[string]bool)
+	for _, m := range pattern.FindAllString(source, -1) {
+		if !strings.Contains(translated, m) && !seen[m] {
+			seen[m] = true
+			missing = append(missing, m)
+		}
+	}
+	return missing
+}
+
+// appendMissing appends a problem listing the source tokens of a kind missing from the
+// translation, if any.
+func appendMissing(problems []string, kind string, missing []string) []string {
+	if len(missing) == 0 {
+		return problems
+	}
+	return append(problems, fmt.Sprintf("the translation lacks these %s of the source: %s", kind, strings.Join(missing, ", ")))
+}
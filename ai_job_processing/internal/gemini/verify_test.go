@@ -0,0 +1,57 @@
+package gemini
+
+import (
+	"strings"
+	"testing"
+
+	"ai_job_processing/internal/models"
+)
+
+func TestReviewTranslated(t *testing.T) {
+	const contact = "Pensum 80-100%, Lohn CHF 100'000. Bewerbung an jobs@acme.ch oder https://acme.ch/jobs."
+	long := strings.Repeat("Wir suchen eine engagierte Person für unser Team. ", 5)
+
+	tests := []struct {
+		name        string
+		source      string
+		translation string
+		want        []string // Substrings of the expected problems, in order
+	}{
+		{"all preserved", contact, "Workload 80-100%, salary CHF 100,000. Apply to jobs@acme.ch or https://acme.ch/jobs.", nil},
+		{"number dropped", contact, "Workload 100%, salary CHF 100,000. Apply to jobs@acme.ch or https://acme.ch/jobs.",
+			[]string{"numbers of the source: 80"}},
+		{"number changed", contact, "Workload 80-100%, salary CHF 10,000. Apply to jobs@acme.ch or https://acme.ch/jobs.",
+			[]string{"numbers of the source: 100'000"}},
+		{"email translated", contact, "Workload 80-100%, salary CHF 100,000. Apply to jobs@acme.com or https://acme.ch/jobs.",
+			[]string{"email addresses of the source: jobs@acme.ch"}},
+		{"url changed", contact, "Workload 80-100%, salary CHF 100,000. Apply to jobs@acme.ch or https://acme.ch/en/jobs.",
+			[]string{"URLs of the source: https://acme.ch/jobs"}},
+		{"too short", long, "We are hiring.", []string{"0.1 times as long as the source"}},
+		{"too long", long, strings.Repeat(long, 3), []string{"3.0 times as long as the source"}},
+		{"short source", "Wir suchen dich.", strings.Repeat("We are looking for you. ", 10), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translated := &models.TranslatedContent{Language: "en", Title: "Engineer", Description: tt.translation}
+			problems := reviewTranslated(translated, "Ingenieur", nil, tt.source, nil)
+			if len(problems) != len(tt.want) {
+				t.Fatalf("problems = %v, want %d", problems, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(problems[i], want) {
+					t.Errorf("problem %q does not contain %q", problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestReviewTranslatedBullets(t *testing.T) {
+	source := &models.NormalizedContent{Tasks: "- Code\n- Review", Requirements: "- Go", Offer: "Remote"}
+	translated := &models.TranslatedContent{Language: "en", Title: "Engineer", Tasks: "- Code and review", Requirements: "- Go", Offer: "Remote"}
+
+	problems := reviewTranslated(translated, "Ingenieur", source, "", nil)
+	if len(problems) != 1 || !strings.Contains(problems[0], `field "tasks" has 1 bullet points, but the source has 2`) {
+		t.Errorf("problems = %v, want the tasks bullet count", problems)
+	}
+}
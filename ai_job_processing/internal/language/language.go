@@ -0,0 +1,79 @@
+package language
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minHits is the number of function words a text needs before Detect trusts it.
+const minHits = 5
+
+// stopwords holds frequent function words per language. Words common to several of the
+// languages (such as "la", "in", "des" or "du") are left out so every hit is a clear vote.
+var stopwords = map[string][]string{
+	"de": {
+		"der", "die", "das", "und", "ist", "nicht", "mit", "für", "auf", "den", "dem",
+		"ein", "eine", "einer", "einen", "wir", "sie", "ihre", "unsere", "zu", "bei",
+		"von", "oder", "auch", "sind", "werden", "wird", "als", "über", "sowie", "im",
+	},
+	"en": {
+		"the", "and", "of", "to", "is", "are", "with", "for", "our", "you", "your",
+		"we", "will", "be", "this", "that", "as", "on", "or", "from", "have", "has",
+		"at", "by", "who", "work", "team", "experience",
+	},
+	"fr": {
+		"le", "les", "et", "de", "une", "est", "pour", "avec", "nous", "vous", "votre",
+		"vos", "dans", "sur", "au", "aux", "qui", "que", "pas", "sont", "ou", "ce",
+		"cette", "être", "par", "équipe", "expérience",
+	},
+	"it": {
+		"di", "che", "è", "per", "con", "nostro", "nostra", "siamo", "sono", "una",
+		"nel", "nella", "alla", "al", "da", "del", "della", "delle", "dei", "degli",
+		"gli", "lo", "ed", "anche", "esperienza", "lavoro",
+	},
+}
+
+// lookup maps each stopword to its language.
+var lookup = func() map[string]string {
+	m := make(map[string]string)
+	for lang, words := range stopwords {
+		for _, w := range words {
+			m[w] = lang
+		}
+	}
+	return m
+}()
+
+// Supported reports whether Detect can recognize the language code.
+func Supported(code string) bool {
+	_, ok := stopwords[code]
+	return ok
+}
+
+// Detect guesses the language of text (de, en, fr or it) from its function words. It returns
+// the language code and its share of the matched words, or "" when the text has too few of
+// them to tell.
+func Detect(text string) (string, float64) {
+	hits := make(map[string]int)
+	total := 0
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		if lang, ok := lookup[w]; ok {
+			hits[lang]++
+			total++
+		}
+	}
+	if total < minHits {
+		return "", 0
+	}
+
+	best := ""
+	for lang, n := range hits {
+		if best == "" || n > hits[best] || (n == hits[best] && lang < best) {
+			best = lang
+		}
+	}
+	return best, float64(hits[best]) / float64(total)
+}
@@ -37,6 +37,9 @@ type ProcessResponse struct {
 	JobID        string              `json:"job_id,omitempty"`
 	Normalized   *NormalizedContent  `json:"normalized,omitempty"`
 	Translations []TranslatedContent `json:"translations,omitempty"`
+	Facts        *JobFacts           `json:"facts,omitempty"`
+	Detection    *LanguageDetection  `json:"language_detection,omitempty"`
+	Reviews      []TranslationReview `json:"reviews,omitempty"`
 	ProcessedAt  time.Time           `json:"processed_at"`
 	SavedToDB    bool                `json:"saved_to_db,omitempty"`
 	Skipped      bool                `json:"skipped,omitempty"`
@@ -45,19 +48,26 @@ type ProcessResponse struct {
 
 // NormalizedContent contains the structured job description sections.
 type NormalizedContent struct {
-	Tasks        string `json:"tasks"`
-	Requirements string `json:"requirements"`
-	Offer        string `json:"offer"`
+	Tasks         string `json:"tasks"`
+	Requirements  string `json:"requirements"`
+	Offer         string `json:"offer"`
+	PromptVersion int    `json:"prompt_version,omitempty"` // Version of the normalization prompt, 0 if unknown
 }
 
 // TranslatedContent contains translated content for one language.
 type TranslatedContent struct {
-	Language     string `json:"language"`
-	Title        string `json:"title"`
-	Description  string `json:"description"`
-	Tasks        string `json:"tasks,omitempty"`
-	Requirements string `json:"requirements,omitempty"`
-	Offer        string `json:"offer,omitempty"`
+	Language     string   `json:"language"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	Tasks        string   `json:"tasks,omitempty"`
+	Requirements string   `json:"requirements,omitempty"`
+	Offer        string   `json:"offer,omitempty"`
+	Issues       []string `json:"issues,omitempty"` // Failed quality checks; the translation is flagged for review
+
+	// Prompt template that produced the translation (translation or raw_translation); empty
+	// for the source language, whose content is copied
+	Prompt        string `json:"prompt,omitempty"`
+	PromptVersion int    `json:"prompt_version,omitempty"`
 }
 
 // NormalizeRequest is the request to normalize raw job data (no translation).
@@ -72,6 +82,8 @@ type NormalizeResponse struct {
 	JobID          string             `json:"job_id,omitempty"`
 	SourceLanguage string             `json:"source_language"`
 	Normalized     *NormalizedContent `json:"normalized"`
+	Facts          *JobFacts          `json:"facts,omitempty"`
+	Detection      *LanguageDetection `json:"language_detection,omitempty"`
 	ProcessedAt    time.Time          `json:"processed_at"`
 	SavedToDB      bool               `json:"saved_to_db,omitempty"`
 	Skipped        bool               `json:"skipped,omitempty"`
@@ -91,6 +103,8 @@ type TranslateRequest struct {
 type TranslateResponse struct {
 	JobID            string              `json:"job_id,omitempty"`
 	Translations     []TranslatedContent `json:"translations"`
+	Detection        *LanguageDetection  `json:"language_detection,omitempty"`
+	Reviews          []TranslationReview `json:"reviews,omitempty"`
 	ProcessedAt      time.Time           `json:"processed_at"`
 	SavedToDB        bool                `json:"saved_to_db,omitempty"`
 	Skipped          bool                `json:"skipped,omitempty"`
@@ -117,6 +131,14 @@ type HealthResponse struct {
 	Version   string    `json:"version,omitempty"`
 }
 
+// ReadinessResponse is the readiness check response. Checks maps each dependency
+// (database, llm) to "ok" or the error that made it fail.
+type ReadinessResponse struct {
+	Status    string            `json:"status"` // ready or unavailable
+	Checks    map[string]string `json:"checks"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
 // ErrorResponse is the standard error response.
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -124,6 +146,156 @@ type ErrorResponse struct {
 	Details string `json:"details,omitempty"`
 }
 
+// Methods of a LanguageDetection.
+const (
+	DetectionText = "text" // Function words of the text, see internal/language
+	DetectionLLM  = "llm"  // Asked the model, the text was too short or mixed to tell
+)
+
+// LanguageDetection is the detected language of a job's original text.
+type LanguageDetection struct {
+	Language   string    `json:"language"`        // ISO 639-1 code
+	Confidence float64   `json:"confidence"`      // From 0 to 1
+	Method     string    `json:"method"`          // text or llm
+	Label      string    `json:"label,omitempty"` // Language the job was scraped as, if different
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// GlossaryTerm is a glossary entry enforced in translations. A term without a translation
+// is kept as is in every language, e.g. a company or product name.
+type GlossaryTerm struct {
+	ID             int64  `db:"id" json:"id,omitempty"`
+	Tenant         string `db:"tenant" json:"tenant,omitempty"`                   // "" = all tenants
+	Term           string `db:"term" json:"term"`                                 // Matched as a whole word, ignoring case
+	TargetLanguage string `db:"target_language" json:"target_language,omitempty"` // Language of Translation
+	Translation    string `db:"translation" json:"translation,omitempty"`         // "" = never translate
+}
+
+// TranslationReview flags a translation that failed its quality checks for a human to check.
+type TranslationReview struct {
+	ID         int64      `db:"id" json:"id,omitempty"`
+	JobID      string     `db:"job_id" json:"job_id,omitempty"`
+	Language   string     `db:"language" json:"language"`
+	Problems   []string   `db:"-" json:"problems"`
+	Kept       bool       `db:"kept" json:"kept"` // The translation was saved despite the problems (false = dropped)
+	Model      string     `db:"model" json:"model"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	ResolvedAt *time.Time `db:"resolved_at" json:"resolved_at,omitempty"`
+}
+
+// Education levels of JobFacts, from lowest to highest.
+const (
+	EducationNone             = "none"              // No formal education required
+	EducationApprenticeship   = "apprenticeship"    // Vocational training (EFZ/CFC)
+	EducationHigherVocational = "higher_vocational" // Federal diploma, HF/ES
+	EducationBachelor         = "bachelor"          // Bachelor's degree (university or FH)
+	EducationMaster           = "master"            // Master's degree
+	EducationDoctorate        = "doctorate"         // PhD
+)
+
+// Remote work policies of JobFacts.
+const (
+	RemoteOnsite = "onsite"
+	RemoteHybrid = "hybrid"
+	RemoteFull   = "remote"
+)
+
+// Seniority levels of JobFacts.
+const (
+	SeniorityIntern    = "intern"
+	SeniorityJunior    = "junior"
+	SeniorityMid       = "mid"
+	SenioritySenior    = "senior"
+	SeniorityLead      = "lead"
+	SeniorityExecutive = "executive"
+)
+
+// JobFacts are the structured requirements and conditions extracted from a job description.
+// Empty strings and nil pointers mean the description does not state them.
+type JobFacts struct {
+	JobID              string        `json:"job_id,omitempty"`
+	Skills             []JobSkill    `json:"skills"`
+	MinYearsExperience *int          `json:"min_years_experience"`
+	EducationLevel     string        `json:"education_level,omitempty"`
+	Languages          []JobLanguage `json:"languages"`
+	RemotePolicy       string        `json:"remote_policy,omitempty"`
+	Salary             *Salary       `json:"salary,omitempty"`
+	Seniority          string        `json:"seniority,omitempty"`
+	Model              string        `json:"model"`          // Model that extracted the facts
+	PromptVersion      int           `json:"prompt_version"` // Version of the facts prompt
+	ExtractedAt        time.Time     `json:"extracted_at"`
+}
+
+// JobSkill is a skill asked for by a job, by its canonical name.
+type JobSkill struct {
+	Name     string `json:"name" db:"skill"`
+	Required bool   `json:"required" db:"required"` // false = nice to have
+}
+
+// JobLanguage is a spoken language asked for by a job.
+type JobLanguage struct {
+	Language string `json:"language"`        // ISO 639-1 code
+	Level    string `json:"level,omitempty"` // CEFR level A1-C2 or native, empty if not stated
+	Required bool   `json:"required"`        // false = nice to have
+}
+
+// Salary is the pay range stated in a job description. Either bound may be missing.
+type Salary struct {
+	Min      *int   `json:"min,omitempty"`
+	Max      *int   `json:"max,omitempty"`
+	Currency string `json:"currency"` // ISO 4217 code, e.g. CHF
+	Period   string `json:"period"`   // year, month or hour
+}
+
+// ExtractRequest is the request to extract facts from raw job data (no database).
+type ExtractRequest struct {
+	Title          string `json:"title" binding:"required"`
+	Description    string `json:"description" binding:"required"`
+	SourceLanguage string `json:"source_language,omitempty"`
+}
+
+// ExtractByIDRequest is the request to extract the facts of a job by ID.
+type ExtractByIDRequest struct {
+	JobID          string `json:"job_id" binding:"required"`
+	SourceLanguage string `json:"source_language,omitempty"`
+	Force          bool   `json:"force,omitempty"`
+}
+
+// ExtractResponse is the response after fact extraction.
+type ExtractResponse struct {
+	JobID       string    `json:"job_id,omitempty"`
+	Facts       *JobFacts `json:"facts"`
+	ProcessedAt time.Time `json:"processed_at"`
+	SavedToDB   bool      `json:"saved_to_db,omitempty"`
+	Skipped     bool      `json:"skipped,omitempty"`
+	SkipReason  string    `json:"skip_reason,omitempty"`
+}
+
+// EmbeddingSource is a normalized job description to embed, with the embedding stored for
+// it, if any.
+type EmbeddingSource struct {
+	Language     string  `db:"language_iso_code"`
+	Title        string  `db:"title"`
+	Tasks        string  `db:"tasks"`
+	Requirements string  `db:"requirements"`
+	Offer        string  `db:"offer"`
+	ContentHash  *string `db:"content_hash"` // Hash of the embedded text (nil = not embedded)
+	Provider     *string `db:"provider"`
+	Model        *string `db:"model"`
+	TextVersion  *int    `db:"text_version"`
+}
+
+// JobEmbedding is the embedding of one language of a job.
+type JobEmbedding struct {
+	JobID       string
+	Language    string
+	Vector      []float32
+	ContentHash string // SHA-256 of the embedded text
+	Provider    string // LLM provider, e.g. gemini
+	Model       string // Embedding model, e.g. text-embedding-004
+	TextVersion int    // Version of the embedded text layout
+}
+
 // JobFromDB represents a job loaded from the database.
 type JobFromDB struct {
 	ID           string `db:"id"`
@@ -160,3 +332,80 @@ func (n *NormalizedContent) BuildDescription(lang string) string {
 
 	return desc
 }
+
+// Queue item statuses of ai_job_queue.
+const (
+	QueueStatusPending    = "pending"    // Waiting to be claimed (available_at holds the retry backoff)
+	QueueStatusProcessing = "processing" // Claimed by a worker until available_at (visibility timeout)
+	QueueStatusDone       = "done"       // Processed or skipped as already done
+	QueueStatusDead       = "dead"       // Dead letter: failed permanently or ran out of attempts
+)
+
+// Processing modes of queued jobs, matching the scrapper's AI_PROCESSING_MODE.
+const (
+	QueueModeProcess   = "process"   // Normalize + translate
+	QueueModeNormalize = "normalize" // Normalize only
+	QueueModeTranslate = "translate" // Translate only
+)
+
+// QueueItem is a job waiting in ai_job_queue, queued by the scrapper.
+type QueueItem struct {
+	ID          int64      `json:"id" db:"id"`
+	JobID       string     `json:"job_id" db:"job_id"`
+	Mode        string     `json:"mode" db:"mode"`
+	Status      string     `json:"status" db:"status"`
+	Attempts    int        `json:"attempts" db:"attempts"`
+	MaxAttempts int        `json:"max_attempts" db:"max_attempts"`
+	AvailableAt time.Time  `json:"available_at" db:"available_at"`
+	LockedBy    *string    `json:"locked_by,omitempty" db:"locked_by"`
+	LastError   *string    `json:"last_error,omitempty" db:"last_error"`
+	RunID       *int64     `json:"run_id,omitempty" db:"run_id"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// QueueStats summarizes ai_job_queue.
+type QueueStats struct {
+	Pending    int        `json:"pending" db:"pending"`
+	Processing int        `json:"processing" db:"processing"`
+	Done       int        `json:"done" db:"done"`
+	Dead       int        `json:"dead" db:"dead"`
+	OldestDue  *time.Time `json:"oldest_due,omitempty" db:"oldest_due"` // Earliest available_at of a pending item
+}
+
+// Batch statuses.
+const (
+	BatchStatusRunning   = "running"   // Processing pending jobs
+	BatchStatusCompleted = "completed" // Every job was attempted
+	BatchStatusCancelled = "cancelled" // Stopped before every job was attempted
+)
+
+// BatchRequest is the request to process pending (un-normalized) jobs in the background.
+type BatchRequest struct {
+	Limit       int  `json:"limit,omitempty"`       // Max jobs to process, newest first (0 = all pending)
+	Concurrency int  `json:"concurrency,omitempty"` // Jobs processed at the same time (0 = BATCH_CONCURRENCY)
+	Force       bool `json:"force,omitempty"`       // Reprocess already translated languages
+}
+
+// BatchStatus reports the progress of a batch.
+type BatchStatus struct {
+	ID          string          `json:"id"`
+	Status      string          `json:"status"`
+	Total       int             `json:"total"`            // Pending jobs picked up when the batch started
+	Done        int             `json:"done"`             // Jobs processed successfully, including skipped ones
+	Skipped     int             `json:"skipped"`          // Jobs that turned out to be fully processed already
+	Failed      int             `json:"failed"`           // Jobs that failed
+	Concurrency int             `json:"concurrency"`      // Jobs processed at the same time
+	Force       bool            `json:"force,omitempty"`  // Already translated languages are reprocessed
+	StartedAt   time.Time       `json:"started_at"`       // When the batch started
+	FinishedAt  *time.Time      `json:"finished_at"`      // When the batch ended
+	ETA         *time.Time      `json:"eta,omitempty"`    // Estimated end, from the average rate so far
+	Errors      []BatchJobError `json:"errors,omitempty"` // Latest job failures
+}
+
+// BatchJobError records why a job of a batch failed.
+type BatchJobError struct {
+	JobID string `json:"job_id"`
+	Error string `json:"error"`
+}
@@ -0,0 +1,113 @@
+package processor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	"ai_job_processing/internal/models"
+	"llmkit/llm"
+)
+
+// embeddingTextVersion is the version of the text built by embeddingText. Bump it when
+// changing the text, so the embed command re-embeds all descriptions.
+const embeddingTextVersion = 1
+
+// EmbedByID embeds the normalized descriptions of a job whose embedding is missing, was made
+// with another model or text version, or no longer matches the description. force re-embeds
+// all of them. Returns the number of descriptions embedded.
+func (p *Processor) EmbedByID(ctx context.Context, jobID string, force bool) (int, error) {
+	ctx = llm.WithJob(ctx, jobID) // Account model calls to the job
+
+	sources, err := p.store.GetEmbeddingSources(ctx, jobID)
+	if err != nil {
+		return 0, err
+	}
+
+	provider := p.gemini.Provider()
+	embedded := 0
+
+	for _, src := range sources {
+		text := embeddingText(src)
+		sum := sha256.Sum256([]byte(text))
+		hash := hex.EncodeToString(sum[:])
+
+		current := src.ContentHash != nil && *src.ContentHash == hash &&
+			*src.Provider == provider.Name() &&
+			*src.Model == provider.EmbeddingModel() &&
+			*src.TextVersion >= embeddingTextVersion
+		if current && !force {
+			// The description was saved again without changing
+			if err := p.store.TouchEmbedding(ctx, jobID, src.Language); err != nil {
+				return embedded, err
+			}
+			continue
+		}
+
+		vector, err := p.gemini.GenerateEmbedding(ctx, text)
+		if err != nil {
+			return embedded, fmt.Errorf("failed to embed %s description: %w", src.Language, err)
+		}
+
+		err = p.store.SaveEmbedding(ctx, &models.JobEmbedding{
+			JobID:       jobID,
+			Language:    src.Language,
+			Vector:      vector,
+			ContentHash: hash,
+			Provider:    provider.Name(),
+			Model:       provider.EmbeddingModel(),
+			TextVersion: embeddingTextVersion,
+		})
+		if err != nil {
+			return embedded, err
+		}
+		embedded++
+	}
+
+	return embedded, nil
+}
+
+// GetStaleEmbeddingJobIDs returns the IDs of jobs with normalized descriptions that EmbedByID
+// would embed, newest first (limit 0 = all).
+func (p *Processor) GetStaleEmbeddingJobIDs(ctx context.Context, limit int) ([]string, error) {
+	if p.store == nil {
+		return nil, nil
+	}
+	provider := p.gemini.Provider()
+	return p.store.GetStaleEmbeddingJobIDs(ctx, provider.Name(), provider.EmbeddingModel(), embeddingTextVersion, limit)
+}
+
+// embedAndSave embeds the descriptions of a job that was just normalized, unless embeddings
+// are disabled. Like facts, embeddings are a by-product of processing, so failures are logged.
+func (p *Processor) embedAndSave(ctx context.Context, jobID string) {
+	if !p.embeddings {
+		return
+	}
+
+	n, err := p.EmbedByID(ctx, jobID, false)
+	if err != nil {
+		slog.Error("embedding failed",
+			"job_id", jobID,
+			"error", err,
+		)
+		return
+	}
+
+	slog.Debug("job embedded",
+		"job_id", jobID,
+		"descriptions", n,
+	)
+}
+
+// embeddingText builds the text embedded for a description: the title followed by the
+// normalized sections.
+func embeddingText(src models.EmbeddingSource) string {
+	normalized := &models.NormalizedContent{
+		Tasks:        src.Tasks,
+		Requirements: src.Requirements,
+		Offer:        src.Offer,
+	}
+	return src.Title + "\n\n" + normalized.BuildDescription(src.Language)
+}
@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"ai_job_processing/internal/models"
+)
+
+// defaultSourceLanguage is assumed when a job's language can be neither detected nor taken
+// from its label
+const defaultSourceLanguage = "de"
+
+// sourceLanguage determines the language a job's text is written in: the requested language
+// if set, else the detected one, else label (the language the job was scraped as), else
+// German. The detection of a stored job (jobID != "") is saved. Detection failures are logged,
+// not returned, since the label is a usable fallback.
+func (p *Processor) sourceLanguage(ctx context.Context, jobID, title, description, requested, label string) (string, *models.LanguageDetection) {
+	if requested != "" {
+		return requested, nil
+	}
+
+	detection, err := p.gemini.DetectLanguage(ctx, title, description)
+	if err != nil {
+		slog.Warn("language detection failed, using the job's label",
+			"job_id", jobID,
+			"label", label,
+			"error", err,
+		)
+		if label != "" {
+			return label, nil
+		}
+		return defaultSourceLanguage, nil
+	}
+
+	if label != "" && label != detection.Language {
+		detection.Label = label
+		slog.Info("job is written in another language than its label",
+			"job_id", jobID,
+			"label", label,
+			"detected", detection.Language,
+			"confidence", detection.Confidence,
+			"method", detection.Method,
+		)
+	}
+
+	if jobID != "" && p.store != nil {
+		if err := p.store.SaveLanguageDetection(ctx, jobID, detection); err != nil {
+			slog.Error("failed to save language detection",
+				"job_id", jobID,
+				"error", err,
+			)
+		}
+	}
+
+	return detection.Language, detection
+}
+
+// relabeledTargets adds label to targets when the job's text is in another language than
+// its label and label was requested, so the description stored under the label gets a real
+// translation instead of counting as already translated. requested are the target languages
+// before existing translations were skipped.
+func relabeledTargets(targets, requested []string, label, sourceLanguage string) []string {
+	if label == "" || label == sourceLanguage || !slices.Contains(requested, label) || slices.Contains(targets, label) {
+		return targets
+	}
+	return append(slices.Clip(targets), label)
+}
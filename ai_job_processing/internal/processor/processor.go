@@ -2,32 +2,43 @@ package processor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"ai_job_processing/internal/gemini"
 	"ai_job_processing/internal/models"
 	"ai_job_processing/internal/store"
+	"llmkit/llm"
 )
 
+// ErrNoContent is returned for a stored job without a title or description to process.
+var ErrNoContent = errors.New("job has no title or description")
+
 // Processor handles job processing operations.
 type Processor struct {
 	gemini          *gemini.Client
 	store           *store.Store
 	targetLanguages []string
+	embeddings      bool
+	glossaryTenant  string
 }
 
-// NewProcessor creates a new Processor.
-func NewProcessor(geminiClient *gemini.Client, st *store.Store, targetLanguages []string) *Processor {
+// NewProcessor creates a new Processor. With embeddings, jobs processed by ID are embedded
+// into job_embeddings. Translations follow the glossary of glossaryTenant.
+func NewProcessor(geminiClient *gemini.Client, st *store.Store, targetLanguages []string, embeddings bool, glossaryTenant string) *Processor {
 	return &Processor{
 		gemini:          geminiClient,
 		store:           st,
 		targetLanguages: targetLanguages,
+		embeddings:      embeddings,
+		glossaryTenant:  glossaryTenant,
 	}
 }
 
-// Process normalizes and translates raw job data (no database interaction).
+// Process normalizes and translates raw job data. Only the glossary is read from the database.
 func (p *Processor) Process(ctx context.Context, req *models.ProcessRequest) (*models.ProcessResponse, error) {
 	start := time.Now()
 
@@ -35,31 +46,31 @@ func (p *Processor) Process(ctx context.Context, req *models.ProcessRequest) (*m
 		"target_languages", p.targetLanguages,
 	)
 
+	sourceLanguage, detection := p.sourceLanguage(ctx, "", req.Title, req.Description, req.SourceLanguage, "")
+
 	// Normalize the job description
-	normalized, err := p.gemini.NormalizeJobDescription(ctx, req.Title, req.Description, req.SourceLanguage)
+	normalized, err := p.gemini.NormalizeJobDescription(ctx, req.Title, req.Description, sourceLanguage)
 	if err != nil {
 		return nil, fmt.Errorf("normalization failed: %w", err)
 	}
 
-	sourceLanguage := req.SourceLanguage
-	if sourceLanguage == "" {
-		sourceLanguage = "de"
-	}
-
 	// Translate to all target languages
-	translations, err := p.gemini.TranslateMultipleNormalized(ctx, req.Title, normalized, sourceLanguage, p.targetLanguages)
+	translations, reviews, err := p.gemini.TranslateMultipleNormalized(ctx, req.Title, normalized, sourceLanguage, p.targetLanguages, p.glossary(ctx, ""))
 	if err != nil {
 		return nil, fmt.Errorf("translation failed: %w", err)
 	}
 
 	slog.Info("job processing completed (raw data)",
 		"translations", len(translations),
+		"reviews", len(reviews),
 		"duration_ms", time.Since(start).Milliseconds(),
 	)
 
 	return &models.ProcessResponse{
 		Normalized:   normalized,
 		Translations: translations,
+		Reviews:      reviews,
+		Detection:    detection,
 		ProcessedAt:  time.Now(),
 	}, nil
 }
@@ -68,6 +79,7 @@ func (p *Processor) Process(ctx context.Context, req *models.ProcessRequest) (*m
 // Skips processing if job is already normalized and translated (unless Force is true).
 func (p *Processor) ProcessByID(ctx context.Context, req *models.ProcessByIDRequest) (*models.ProcessResponse, error) {
 	start := time.Now()
+	ctx = llm.WithJob(ctx, req.JobID) // Account model calls to the job
 
 	slog.Info("starting job processing by ID",
 		"job_id", req.JobID,
@@ -81,7 +93,7 @@ func (p *Processor) ProcessByID(ctx context.Context, req *models.ProcessByIDRequ
 	}
 
 	if job.Title == "" || job.Description == "" {
-		return nil, fmt.Errorf("job has no title or description: %s", req.JobID)
+		return nil, fmt.Errorf("%w: %s", ErrNoContent, req.JobID)
 	}
 
 	// Determine target languages
@@ -89,6 +101,7 @@ func (p *Processor) ProcessByID(ctx context.Context, req *models.ProcessByIDRequ
 	if len(targetLanguages) == 0 {
 		targetLanguages = p.targetLanguages
 	}
+	requestedLanguages := targetLanguages
 
 	// Check existing translations (unless Force)
 	if !req.Force {
@@ -103,10 +116,12 @@ func (p *Processor) ProcessByID(ctx context.Context, req *models.ProcessByIDRequ
 			)
 
 			normalized, translations, _ := p.store.GetExistingNormalizedContent(ctx, req.JobID)
+			facts, _ := p.store.GetJobFacts(ctx, req.JobID)
 			return &models.ProcessResponse{
 				JobID:        req.JobID,
 				Normalized:   normalized,
 				Translations: translations,
+				Facts:        facts,
 				ProcessedAt:  time.Now(),
 				Skipped:      true,
 				SkipReason:   "already normalized and translated",
@@ -122,14 +137,8 @@ func (p *Processor) ProcessByID(ctx context.Context, req *models.ProcessByIDRequ
 		}
 	}
 
-	// Determine source language
-	sourceLanguage := req.SourceLanguage
-	if sourceLanguage == "" {
-		sourceLanguage = job.Language
-	}
-	if sourceLanguage == "" {
-		sourceLanguage = "de"
-	}
+	sourceLanguage, detection := p.sourceLanguage(ctx, req.JobID, job.Title, job.Description, req.SourceLanguage, job.Language)
+	targetLanguages = relabeledTargets(targetLanguages, requestedLanguages, job.Language, sourceLanguage)
 
 	// Normalize the job description
 	normalized, err := p.gemini.NormalizeJobDescription(ctx, job.Title, job.Description, sourceLanguage)
@@ -138,14 +147,14 @@ func (p *Processor) ProcessByID(ctx context.Context, req *models.ProcessByIDRequ
 	}
 
 	// Translate to all target languages
-	translations, err := p.gemini.TranslateMultipleNormalized(ctx, job.Title, normalized, sourceLanguage, targetLanguages)
+	translations, reviews, err := p.gemini.TranslateMultipleNormalized(ctx, job.Title, normalized, sourceLanguage, targetLanguages, p.glossary(ctx, req.JobID))
 	if err != nil {
 		return nil, fmt.Errorf("translation failed: %w", err)
 	}
 
 	// Save all translations to database
 	savedToDB := false
-	if err := p.store.SaveAllTranslations(ctx, req.JobID, translations, true); err != nil {
+	if err := p.store.SaveAllTranslations(ctx, req.JobID, translations, normalized); err != nil {
 		slog.Error("failed to save translations",
 			"job_id", req.JobID,
 			"error", err,
@@ -153,6 +162,10 @@ func (p *Processor) ProcessByID(ctx context.Context, req *models.ProcessByIDRequ
 	} else {
 		savedToDB = true
 	}
+	p.saveReviews(ctx, req.JobID, translations, reviews)
+
+	facts := p.extractAndSaveFacts(ctx, req.JobID, job.Title, job.Description, sourceLanguage)
+	p.embedAndSave(ctx, req.JobID)
 
 	slog.Info("job processing by ID completed",
 		"job_id", req.JobID,
@@ -165,6 +178,9 @@ func (p *Processor) ProcessByID(ctx context.Context, req *models.ProcessByIDRequ
 		JobID:        req.JobID,
 		Normalized:   normalized,
 		Translations: translations,
+		Reviews:      reviews,
+		Facts:        facts,
+		Detection:    detection,
 		ProcessedAt:  time.Now(),
 		SavedToDB:    savedToDB,
 	}, nil
@@ -175,6 +191,7 @@ func (p *Processor) ProcessByID(ctx context.Context, req *models.ProcessByIDRequ
 // Skips languages that already have translations (unless Force is true).
 func (p *Processor) TranslateByID(ctx context.Context, req *models.TranslateByIDRequest) (*models.TranslateResponse, error) {
 	start := time.Now()
+	ctx = llm.WithJob(ctx, req.JobID) // Account model calls to the job
 
 	slog.Info("starting job translation by ID (no normalization)",
 		"job_id", req.JobID,
@@ -188,7 +205,7 @@ func (p *Processor) TranslateByID(ctx context.Context, req *models.TranslateByID
 	}
 
 	if job.Title == "" || job.Description == "" {
-		return nil, fmt.Errorf("job has no title or description: %s", req.JobID)
+		return nil, fmt.Errorf("%w: %s", ErrNoContent, req.JobID)
 	}
 
 	// Determine target languages
@@ -197,6 +214,7 @@ func (p *Processor) TranslateByID(ctx context.Context, req *models.TranslateByID
 		targetLanguages = p.targetLanguages
 	}
 
+	requestedLanguages := targetLanguages
 	var skippedLanguages []string
 
 	// Check existing translations (unless Force)
@@ -231,24 +249,21 @@ func (p *Processor) TranslateByID(ctx context.Context, req *models.TranslateByID
 		}
 	}
 
-	// Determine source language
-	sourceLanguage := req.SourceLanguage
-	if sourceLanguage == "" {
-		sourceLanguage = job.Language
-	}
-	if sourceLanguage == "" {
-		sourceLanguage = "de"
+	sourceLanguage, detection := p.sourceLanguage(ctx, req.JobID, job.Title, job.Description, req.SourceLanguage, job.Language)
+	if relabeled := relabeledTargets(targetLanguages, requestedLanguages, job.Language, sourceLanguage); len(relabeled) > len(targetLanguages) {
+		targetLanguages = relabeled
+		skippedLanguages = slices.DeleteFunc(skippedLanguages, func(lang string) bool { return lang == job.Language })
 	}
 
 	// Translate raw description to all target languages
-	translations, err := p.gemini.TranslateMultipleRaw(ctx, job.Title, job.Description, sourceLanguage, targetLanguages)
+	translations, reviews, err := p.gemini.TranslateMultipleRaw(ctx, job.Title, job.Description, sourceLanguage, targetLanguages, p.glossary(ctx, req.JobID))
 	if err != nil {
 		return nil, fmt.Errorf("translation failed: %w", err)
 	}
 
 	// Save translations to database (without normalization fields)
 	savedToDB := false
-	if err := p.store.SaveAllTranslations(ctx, req.JobID, translations, false); err != nil {
+	if err := p.store.SaveAllTranslations(ctx, req.JobID, translations, nil); err != nil {
 		slog.Error("failed to save translations",
 			"job_id", req.JobID,
 			"error", err,
@@ -256,6 +271,7 @@ func (p *Processor) TranslateByID(ctx context.Context, req *models.TranslateByID
 	} else {
 		savedToDB = true
 	}
+	p.saveReviews(ctx, req.JobID, translations, reviews)
 
 	slog.Info("job translation by ID completed",
 		"job_id", req.JobID,
@@ -267,6 +283,8 @@ func (p *Processor) TranslateByID(ctx context.Context, req *models.TranslateByID
 	return &models.TranslateResponse{
 		JobID:            req.JobID,
 		Translations:     translations,
+		Reviews:          reviews,
+		Detection:        detection,
 		ProcessedAt:      time.Now(),
 		SavedToDB:        savedToDB,
 		SkippedLanguages: skippedLanguages,
@@ -277,6 +295,7 @@ func (p *Processor) TranslateByID(ctx context.Context, req *models.TranslateByID
 // Skips if already normalized (unless Force is true).
 func (p *Processor) NormalizeByID(ctx context.Context, req *models.NormalizeByIDRequest) (*models.NormalizeResponse, error) {
 	start := time.Now()
+	ctx = llm.WithJob(ctx, req.JobID) // Account model calls to the job
 
 	slog.Info("starting job normalization by ID (no translation)",
 		"job_id", req.JobID,
@@ -290,7 +309,7 @@ func (p *Processor) NormalizeByID(ctx context.Context, req *models.NormalizeByID
 	}
 
 	if job.Title == "" || job.Description == "" {
-		return nil, fmt.Errorf("job has no title or description: %s", req.JobID)
+		return nil, fmt.Errorf("%w: %s", ErrNoContent, req.JobID)
 	}
 
 	// Check if already normalized (unless Force)
@@ -300,24 +319,19 @@ func (p *Processor) NormalizeByID(ctx context.Context, req *models.NormalizeByID
 		)
 
 		normalized, _, _ := p.store.GetExistingNormalizedContent(ctx, req.JobID)
+		facts, _ := p.store.GetJobFacts(ctx, req.JobID)
 		return &models.NormalizeResponse{
 			JobID:          req.JobID,
 			SourceLanguage: job.Language,
 			Normalized:     normalized,
+			Facts:          facts,
 			ProcessedAt:    time.Now(),
 			Skipped:        true,
 			SkipReason:     "already normalized",
 		}, nil
 	}
 
-	// Determine source language
-	sourceLanguage := req.SourceLanguage
-	if sourceLanguage == "" {
-		sourceLanguage = job.Language
-	}
-	if sourceLanguage == "" {
-		sourceLanguage = "de"
-	}
+	sourceLanguage, detection := p.sourceLanguage(ctx, req.JobID, job.Title, job.Description, req.SourceLanguage, job.Language)
 
 	// Normalize the job description
 	normalized, err := p.gemini.NormalizeJobDescription(ctx, job.Title, job.Description, sourceLanguage)
@@ -336,6 +350,9 @@ func (p *Processor) NormalizeByID(ctx context.Context, req *models.NormalizeByID
 		savedToDB = true
 	}
 
+	facts := p.extractAndSaveFacts(ctx, req.JobID, job.Title, job.Description, sourceLanguage)
+	p.embedAndSave(ctx, req.JobID)
+
 	slog.Info("job normalization by ID completed",
 		"job_id", req.JobID,
 		"saved_to_db", savedToDB,
@@ -346,6 +363,8 @@ func (p *Processor) NormalizeByID(ctx context.Context, req *models.NormalizeByID
 		JobID:          req.JobID,
 		SourceLanguage: sourceLanguage,
 		Normalized:     normalized,
+		Facts:          facts,
+		Detection:      detection,
 		ProcessedAt:    time.Now(),
 		SavedToDB:      savedToDB,
 	}, nil
@@ -357,16 +376,13 @@ func (p *Processor) Normalize(ctx context.Context, req *models.NormalizeRequest)
 
 	slog.Info("starting job normalization (raw data)")
 
-	normalized, err := p.gemini.NormalizeJobDescription(ctx, req.Title, req.Description, req.SourceLanguage)
+	sourceLanguage, detection := p.sourceLanguage(ctx, "", req.Title, req.Description, req.SourceLanguage, "")
+
+	normalized, err := p.gemini.NormalizeJobDescription(ctx, req.Title, req.Description, sourceLanguage)
 	if err != nil {
 		return nil, fmt.Errorf("normalization failed: %w", err)
 	}
 
-	sourceLanguage := req.SourceLanguage
-	if sourceLanguage == "" {
-		sourceLanguage = "de"
-	}
-
 	slog.Info("job normalization completed",
 		"duration_ms", time.Since(start).Milliseconds(),
 	)
@@ -374,11 +390,13 @@ func (p *Processor) Normalize(ctx context.Context, req *models.NormalizeRequest)
 	return &models.NormalizeResponse{
 		SourceLanguage: sourceLanguage,
 		Normalized:     normalized,
+		Detection:      detection,
 		ProcessedAt:    time.Now(),
 	}, nil
 }
 
-// Translate translates raw job data (supports both normalized and raw description).
+// Translate translates raw job data (supports both normalized and raw description). Only the
+// glossary is read from the database.
 func (p *Processor) Translate(ctx context.Context, req *models.TranslateRequest) (*models.TranslateResponse, error) {
 	start := time.Now()
 
@@ -391,25 +409,30 @@ func (p *Processor) Translate(ctx context.Context, req *models.TranslateRequest)
 		"target_languages", targetLangs,
 	)
 
-	sourceLanguage := req.SourceLanguage
-	if sourceLanguage == "" {
-		sourceLanguage = "de"
+	// Check if we have normalized content or just raw description
+	hasNormalized := req.Normalized != nil && (req.Normalized.Tasks != "" || req.Normalized.Requirements != "" || req.Normalized.Offer != "")
+	if !hasNormalized && req.Description == "" {
+		return nil, fmt.Errorf("either normalized content or description is required")
+	}
+
+	text := req.Description
+	if hasNormalized {
+		text = req.Normalized.Tasks + "\n" + req.Normalized.Requirements + "\n" + req.Normalized.Offer
 	}
+	sourceLanguage, detection := p.sourceLanguage(ctx, "", req.Title, text, req.SourceLanguage, "")
+
+	glossary := p.glossary(ctx, "")
 
 	var translations []models.TranslatedContent
+	var reviews []models.TranslationReview
 	var err error
-
-	// Check if we have normalized content or just raw description
-	if req.Normalized != nil && (req.Normalized.Tasks != "" || req.Normalized.Requirements != "" || req.Normalized.Offer != "") {
+	if hasNormalized {
 		// Translate normalized content
-		translations, err = p.gemini.TranslateMultipleNormalized(ctx, req.Title, req.Normalized, sourceLanguage, targetLangs)
-	} else if req.Description != "" {
-		// Translate raw description
-		translations, err = p.gemini.TranslateMultipleRaw(ctx, req.Title, req.Description, sourceLanguage, targetLangs)
+		translations, reviews, err = p.gemini.TranslateMultipleNormalized(ctx, req.Title, req.Normalized, sourceLanguage, targetLangs, glossary)
 	} else {
-		return nil, fmt.Errorf("either normalized content or description is required")
+		// Translate raw description
+		translations, reviews, err = p.gemini.TranslateMultipleRaw(ctx, req.Title, req.Description, sourceLanguage, targetLangs, glossary)
 	}
-
 	if err != nil {
 		return nil, fmt.Errorf("translation failed: %w", err)
 	}
@@ -421,10 +444,153 @@ func (p *Processor) Translate(ctx context.Context, req *models.TranslateRequest)
 
 	return &models.TranslateResponse{
 		Translations: translations,
+		Reviews:      reviews,
+		Detection:    detection,
 		ProcessedAt:  time.Now(),
 	}, nil
 }
 
+// ExtractByID extracts the facts of a job from the database and saves them.
+// Skips if the job has facts of the current prompt version (unless Force is true).
+func (p *Processor) ExtractByID(ctx context.Context, req *models.ExtractByIDRequest) (*models.ExtractResponse, error) {
+	start := time.Now()
+	ctx = llm.WithJob(ctx, req.JobID) // Account model calls to the job
+
+	slog.Info("starting fact extraction by ID",
+		"job_id", req.JobID,
+		"force", req.Force,
+	)
+
+	// Load job from database
+	job, err := p.store.GetJobByID(ctx, req.JobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job: %w", err)
+	}
+
+	if job.Title == "" || job.Description == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoContent, req.JobID)
+	}
+
+	// Check existing facts (unless Force)
+	if !req.Force {
+		existing, err := p.store.GetJobFacts(ctx, req.JobID)
+		if err != nil {
+			slog.Warn("failed to check existing facts", "error", err)
+		} else if existing != nil && existing.PromptVersion >= p.gemini.FactsPromptVersion() {
+			slog.Info("job facts already extracted, skipping",
+				"job_id", req.JobID,
+			)
+
+			return &models.ExtractResponse{
+				JobID:       req.JobID,
+				Facts:       existing,
+				ProcessedAt: time.Now(),
+				Skipped:     true,
+				SkipReason:  "already extracted",
+			}, nil
+		}
+	}
+
+	sourceLanguage, _ := p.sourceLanguage(ctx, req.JobID, job.Title, job.Description, req.SourceLanguage, job.Language)
+
+	facts, err := p.gemini.ExtractFacts(ctx, job.Title, job.Description, sourceLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("fact extraction failed: %w", err)
+	}
+	facts.JobID = req.JobID
+
+	// Save to database
+	savedToDB := false
+	if err := p.store.SaveJobFacts(ctx, req.JobID, facts); err != nil {
+		slog.Error("failed to save job facts",
+			"job_id", req.JobID,
+			"error", err,
+		)
+	} else {
+		savedToDB = true
+	}
+
+	slog.Info("fact extraction by ID completed",
+		"job_id", req.JobID,
+		"skills", len(facts.Skills),
+		"saved_to_db", savedToDB,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
+	return &models.ExtractResponse{
+		JobID:       req.JobID,
+		Facts:       facts,
+		ProcessedAt: time.Now(),
+		SavedToDB:   savedToDB,
+	}, nil
+}
+
+// Extract extracts the facts of raw job data (no database).
+func (p *Processor) Extract(ctx context.Context, req *models.ExtractRequest) (*models.ExtractResponse, error) {
+	start := time.Now()
+
+	slog.Info("starting fact extraction (raw data)")
+
+	facts, err := p.gemini.ExtractFacts(ctx, req.Title, req.Description, req.SourceLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("fact extraction failed: %w", err)
+	}
+
+	slog.Info("fact extraction completed",
+		"skills", len(facts.Skills),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
+	return &models.ExtractResponse{
+		Facts:       facts,
+		ProcessedAt: time.Now(),
+	}, nil
+}
+
+// extractAndSaveFacts extracts and saves the facts of a job that was just normalized. Facts
+// are a by-product of processing, so failures are logged and nil is returned.
+func (p *Processor) extractAndSaveFacts(ctx context.Context, jobID, title, description, sourceLanguage string) *models.JobFacts {
+	facts, err := p.gemini.ExtractFacts(ctx, title, description, sourceLanguage)
+	if err != nil {
+		slog.Error("fact extraction failed",
+			"job_id", jobID,
+			"error", err,
+		)
+		return nil
+	}
+	facts.JobID = jobID
+
+	if err := p.store.SaveJobFacts(ctx, jobID, facts); err != nil {
+		slog.Error("failed to save job facts",
+			"job_id", jobID,
+			"error", err,
+		)
+	}
+	return facts
+}
+
+// GetJobIDsWithoutFacts returns the IDs of jobs whose facts are missing or were extracted
+// with an older prompt version, newest first (limit 0 = all).
+func (p *Processor) GetJobIDsWithoutFacts(ctx context.Context, limit int) ([]string, error) {
+	if p.store == nil {
+		return nil, nil
+	}
+	return p.store.GetJobIDsWithoutFacts(ctx, p.gemini.FactsPromptVersion(), limit)
+}
+
+// CheckDatabase checks that the database is reachable.
+func (p *Processor) CheckDatabase(ctx context.Context) error {
+	if p.store == nil {
+		return nil
+	}
+	return p.store.Ping(ctx)
+}
+
+// CheckLLM checks that the LLM provider is reachable.
+func (p *Processor) CheckLLM(ctx context.Context) error {
+	return p.gemini.Ping(ctx)
+}
+
 // GetTargetLanguages returns the configured target languages.
 func (p *Processor) GetTargetLanguages() []string {
 	return p.targetLanguages
@@ -445,3 +611,11 @@ func (p *Processor) GetPendingJobs(ctx context.Context, limit int) ([]models.Job
 	}
 	return p.store.GetPendingJobs(ctx, limit)
 }
+
+// GetPendingJobIDs returns the IDs of jobs pending normalization (limit 0 = all).
+func (p *Processor) GetPendingJobIDs(ctx context.Context, limit int) ([]string, error) {
+	if p.store == nil {
+		return nil, nil
+	}
+	return p.store.GetPendingJobIDs(ctx, limit)
+}
@@ -0,0 +1,68 @@
+package processor
+
+import (
+	"context"
+	"log/slog"
+
+	"ai_job_processing/internal/models"
+)
+
+// glossary returns the glossary terms of the configured tenant and, for a stored job
+// (jobID != ""), its company name as a term never to translate. Failures are logged, not
+// returned; translations then go without the terms.
+func (p *Processor) glossary(ctx context.Context, jobID string) []models.GlossaryTerm {
+	if p.store == nil {
+		return nil
+	}
+
+	terms, err := p.store.GetGlossary(ctx, p.glossaryTenant)
+	if err != nil {
+		slog.Warn("failed to load glossary", "tenant", p.glossaryTenant, "error", err)
+	}
+
+	if jobID != "" {
+		company, err := p.store.GetCompanyName(ctx, jobID)
+		if err != nil {
+			slog.Warn("failed to load company name", "job_id", jobID, "error", err)
+		} else if company != "" {
+			terms = append(terms, models.GlossaryTerm{Term: company})
+		}
+	}
+
+	return terms
+}
+
+// saveReviews flags the translations of a stored job that failed or have quality issues for
+// review, and resolves the open reviews of the languages in translations that passed.
+func (p *Processor) saveReviews(ctx context.Context, jobID string, translations []models.TranslatedContent, reviews []models.TranslationReview) {
+	var passed []string
+	for _, t := range translations {
+		if len(t.Issues) == 0 {
+			passed = append(passed, t.Language)
+		}
+	}
+
+	if n, err := p.store.ResolveJobReviews(ctx, jobID, passed); err != nil {
+		slog.Error("failed to resolve translation reviews",
+			"job_id", jobID,
+			"error", err,
+		)
+	} else if n > 0 {
+		slog.Info("resolved translation reviews", "job_id", jobID, "count", n)
+	}
+
+	if len(reviews) == 0 {
+		return
+	}
+	if err := p.store.SaveTranslationReviews(ctx, jobID, reviews); err != nil {
+		slog.Error("failed to save translation reviews",
+			"job_id", jobID,
+			"error", err,
+		)
+		return
+	}
+	slog.Warn("translations flagged for review",
+		"job_id", jobID,
+		"reviews", len(reviews),
+	)
+}
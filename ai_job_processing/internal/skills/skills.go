@@ -0,0 +1,161 @@
+package skills
+
+import (
+	"strings"
+	"unicode"
+)
+
+// aliases maps lowercase spellings of a skill to its canonical name. Canonical names map to
+// themselves so that their spelling is fixed as well.
+var aliases = map[string]string{
+	// Programming languages
+	"go":              "Go",
+	"golang":          "Go",
+	"python":          "Python",
+	"python3":         "Python",
+	"python 3":        "Python",
+	"java":            "Java",
+	"javascript":      "JavaScript",
+	"js":              "JavaScript",
+	"ecmascript":      "JavaScript",
+	"typescript":      "TypeScript",
+	"ts":              "TypeScript",
+	"c#":              "C#",
+	"csharp":          "C#",
+	"c sharp":         "C#",
+	"c++":             "C++",
+	"cpp":             "C++",
+	"c":               "C",
+	"kotlin":          "Kotlin",
+	"swift":           "Swift",
+	"php":             "PHP",
+	"ruby":            "Ruby",
+	"rust":            "Rust",
+	"scala":           "Scala",
+	"r":               "R",
+	"matlab":          "MATLAB",
+	"abap":            "ABAP",
+	"sql":             "SQL",
+	"bash":            "Bash",
+	"shell":           "Bash",
+	"shell scripting": "Bash",
+	"powershell":      "PowerShell",
+
+	// Frameworks and libraries
+	"react":       "React",
+	"react.js":    "React",
+	"reactjs":     "React",
+	"angular":     "Angular",
+	"angularjs":   "Angular",
+	"vue":         "Vue.js",
+	"vue.js":      "Vue.js",
+	"vuejs":       "Vue.js",
+	"node":        "Node.js",
+	"node.js":     "Node.js",
+	"nodejs":      "Node.js",
+	"spring":      "Spring",
+	"spring boot": "Spring Boot",
+	"springboot":  "Spring Boot",
+	".net":        ".NET",
+	"dotnet":      ".NET",
+	".net core":   ".NET",
+	"django":      "Django",
+	"flask":       "Flask",
+	"pytorch":     "PyTorch",
+	"tensorflow":  "TensorFlow",
+	"pandas":      "pandas",
+
+	// Data stores
+	"postgresql":      "PostgreSQL",
+	"postgres":        "PostgreSQL",
+	"mysql":           "MySQL",
+	"mongodb":         "MongoDB",
+	"mongo":           "MongoDB",
+	"redis":           "Redis",
+	"oracle":          "Oracle Database",
+	"oracle db":       "Oracle Database",
+	"oracle database": "Oracle Database",
+	"ms sql":          "SQL Server",
+	"mssql":           "SQL Server",
+	"sql server":      "SQL Server",
+	"elasticsearch":   "Elasticsearch",
+	"kafka":           "Kafka",
+	"apache kafka":    "Kafka",
+
+	// Cloud and operations
+	"aws":                   "AWS",
+	"amazon web services":   "AWS",
+	"azure":                 "Azure",
+	"microsoft azure":       "Azure",
+	"gcp":                   "Google Cloud",
+	"google cloud":          "Google Cloud",
+	"google cloud platform": "Google Cloud",
+	"docker":                "Docker",
+	"kubernetes":            "Kubernetes",
+	"k8s":                   "Kubernetes",
+	"terraform":             "Terraform",
+	"ansible":               "Ansible",
+	"linux":                 "Linux",
+	"git":                   "Git",
+	"ci/cd":                 "CI/CD",
+	"cicd":                  "CI/CD",
+	"ci / cd":               "CI/CD",
+	"jenkins":               "Jenkins",
+	"devops":                "DevOps",
+
+	// Data and AI
+	"machine learning":        "Machine Learning",
+	"ml":                      "Machine Learning",
+	"deep learning":           "Deep Learning",
+	"artificial intelligence": "AI",
+	"ai":                      "AI",
+	"ki":                      "AI",
+	"künstliche intelligenz":  "AI",
+	"data science":            "Data Science",
+	"power bi":                "Power BI",
+	"powerbi":                 "Power BI",
+	"tableau":                 "Tableau",
+	"excel":                   "Excel",
+	"ms excel":                "Excel",
+	"microsoft excel":         "Excel",
+
+	// Business software and methods
+	"sap":                "SAP",
+	"sap s/4hana":        "SAP S/4HANA",
+	"s/4hana":            "SAP S/4HANA",
+	"salesforce":         "Salesforce",
+	"ms office":          "Microsoft Office",
+	"microsoft office":   "Microsoft Office",
+	"office 365":         "Microsoft 365",
+	"microsoft 365":      "Microsoft 365",
+	"scrum":              "Scrum",
+	"agile":              "Agile",
+	"agil":               "Agile",
+	"agile methods":      "Agile",
+	"agile methoden":     "Agile",
+	"project management": "Project Management",
+	"projektmanagement":  "Project Management",
+	"gestion de projet":  "Project Management",
+	"autocad":            "AutoCAD",
+}
+
+// Canonicalize returns the canonical name of a skill, e.g. "Go" for "golang" or "Kubernetes"
+// for "k8s". Unknown skills keep their spelling with whitespace and trailing punctuation
+// cleaned up. It returns "" for an empty name.
+func Canonicalize(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	name = strings.TrimRightFunc(name, func(r rune) bool {
+		return r == '.' || r == ',' || r == ';' || r == ':'
+	})
+	if name == "" {
+		return ""
+	}
+	if canonical, ok := aliases[strings.ToLower(name)]; ok {
+		return canonical
+	}
+	if r := []rune(name); unicode.IsLower(r[0]) && !strings.ContainsFunc(name, unicode.IsUpper) {
+		// All lowercase, e.g. "project controlling": capitalize like the canonical names
+		return string(unicode.ToUpper(r[0])) + string(r[1:])
+	}
+	return name
+}
@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ai_job_processing/internal/models"
+)
+
+// EmbeddingDimensions is the vector size of job_embeddings.embedding (migration 018).
+// Changing the embedding model to one of another size needs a migration.
+const EmbeddingDimensions = 768
+
+// GetEmbeddingSources returns the normalized descriptions of a job with their stored
+// embeddings.
+func (s *Store) GetEmbeddingSources(ctx context.Context, jobID string) ([]models.EmbeddingSource, error) {
+	var sources []models.EmbeddingSource
+	err := s.db.SelectContext(ctx, &sources, `
+		SELECT
+			jd.language_iso_code,
+			COALESCE(jd.title, '') as title,
+			COALESCE(jd.tasks, '') as tasks,
+			COALESCE(jd.requirements, '') as requirements,
+			COALESCE(jd.offer, '') as offer,
+			je.content_hash,
+			je.provider,
+			je.model,
+			je.text_version
+		FROM job_descriptions jd
+		LEFT JOIN job_embeddings je
+			ON je.job_id = jd.job_id AND je.language_iso_code = jd.language_iso_code
+		WHERE jd.job_id = $1 AND jd.is_normalized = TRUE
+		ORDER BY jd.language_iso_code`,
+		jobID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding sources: %w", err)
+	}
+	return sources, nil
+}
+
+// SaveEmbedding stores the embedding of one language of a job, replacing an earlier one.
+func (s *Store) SaveEmbedding(ctx context.Context, e *models.JobEmbedding) error {
+	if len(e.Vector) != EmbeddingDimensions {
+		return fmt.Errorf("embedding has %d dimensions, job_embeddings expects %d", len(e.Vector), EmbeddingDimensions)
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO job_embeddings (
+			job_id, language_iso_code, embedding, content_hash,
+			provider, model, text_version, created_at, updated_at
+		) VALUES ($1, $2, $3::vector, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT (job_id, language_iso_code) DO UPDATE SET
+			embedding = EXCLUDED.embedding,
+			content_hash = EXCLUDED.content_hash,
+			provider = EXCLUDED.provider,
+			model = EXCLUDED.model,
+			text_version = EXCLUDED.text_version,
+			updated_at = NOW()`,
+		e.JobID,
+		e.Language,
+		vectorLiteral(e.Vector),
+		e.ContentHash,
+		e.Provider,
+		e.Model,
+		e.TextVersion,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save embedding: %w", err)
+	}
+	return nil
+}
+
+// TouchEmbedding marks the embedding of a language as checked against its description,
+// for descriptions that were saved again without changing the embedded text.
+func (s *Store) TouchEmbedding(ctx context.Context, jobID, language string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE job_embeddings SET updated_at = NOW()
+		WHERE job_id = $1 AND language_iso_code = $2`,
+		jobID, language,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to touch embedding: %w", err)
+	}
+	return nil
+}
+
+// GetStaleEmbeddingJobIDs returns the IDs of jobs with a normalized description that has no
+// embedding, an embedding of another provider, model or older text version, or an embedding
+// older than the description, newest first (limit 0 = all).
+func (s *Store) GetStaleEmbeddingJobIDs(ctx context.Context, provider, model string, textVersion, limit int) ([]string, error) {
+	var ids []string
+	err := s.db.SelectContext(ctx, &ids, `
+		SELECT j.id
+		FROM jobs j
+		WHERE EXISTS (
+			SELECT 1
+			FROM job_descriptions jd
+			LEFT JOIN job_embeddings je
+				ON je.job_id = jd.job_id AND je.language_iso_code = jd.language_iso_code
+			WHERE jd.job_id = j.id AND jd.is_normalized = TRUE
+			  AND (je.job_id IS NULL
+			       OR je.provider <> $1 OR je.model <> $2 OR je.text_version < $3
+			       OR je.updated_at < jd.updated_at)
+		)
+		ORDER BY j.created_time DESC, j.id
+		LIMIT NULLIF($4, 0)`,
+		provider, model, textVersion, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs with stale embeddings: %w", err)
+	}
+	return ids, nil
+}
+
+// vectorLiteral formats v as a pgvector text literal, e.g. [0.1,0.2].
+func vectorLiteral(v []float32) string {
+	var b strings.Builder
+	b.Grow(len(v) * 10)
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ai_job_processing/internal/models"
+)
+
+// SaveJobFacts stores the facts of a job, replacing earlier ones (migration 017).
+func (s *Store) SaveJobFacts(ctx context.Context, jobID string, facts *models.JobFacts) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var salaryMin, salaryMax *int
+	var salaryCurrency, salaryPeriod *string
+	if facts.Salary != nil {
+		salaryMin, salaryMax = facts.Salary.Min, facts.Salary.Max
+		salaryCurrency, salaryPeriod = &facts.Salary.Currency, &facts.Salary.Period
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO job_facts (
+			job_id, min_years_experience, education_level, remote_policy, seniority,
+			salary_min, salary_max, salary_currency, salary_period,
+			model, prompt_version, extracted_at
+		) VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (job_id) DO UPDATE SET
+			min_years_experience = EXCLUDED.min_years_experience,
+			education_level = EXCLUDED.education_level,
+			remote_policy = EXCLUDED.remote_policy,
+			seniority = EXCLUDED.seniority,
+			salary_min = EXCLUDED.salary_min,
+			salary_max = EXCLUDED.salary_max,
+			salary_currency = EXCLUDED.salary_currency,
+			salary_period = EXCLUDED.salary_period,
+			model = EXCLUDED.model,
+			prompt_version = EXCLUDED.prompt_version,
+			extracted_at = EXCLUDED.extracted_at`,
+		jobID,
+		facts.MinYearsExperience,
+		facts.EducationLevel,
+		facts.RemotePolicy,
+		facts.Seniority,
+		salaryMin,
+		salaryMax,
+		salaryCurrency,
+		salaryPeriod,
+		facts.Model,
+		facts.PromptVersion,
+		facts.ExtractedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save job facts: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM job_skills WHERE job_id = $1`, jobID); err != nil {
+		return fmt.Errorf("failed to delete job skills: %w", err)
+	}
+	for _, skill := range facts.Skills {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO job_skills (job_id, skill, required)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (job_id, skill) DO UPDATE SET required = job_skills.required OR EXCLUDED.required`,
+			jobID, skill.Name, skill.Required,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save job skill %s: %w", skill.Name, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM job_languages WHERE job_id = $1`, jobID); err != nil {
+		return fmt.Errorf("failed to delete job languages: %w", err)
+	}
+	for _, lang := range facts.Languages {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO job_languages (job_id, language, level, required)
+			VALUES ($1, $2, NULLIF($3, ''), $4)
+			ON CONFLICT (job_id, language) DO NOTHING`,
+			jobID, lang.Language, lang.Level, lang.Required,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save job language %s: %w", lang.Language, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetJobFacts retrieves the extracted facts of a job, or nil if there are none.
+func (s *Store) GetJobFacts(ctx context.Context, jobID string) (*models.JobFacts, error) {
+	var row struct {
+		JobID              string         `db:"job_id"`
+		MinYearsExperience *int           `db:"min_years_experience"`
+		EducationLevel     sql.NullString `db:"education_level"`
+		RemotePolicy       sql.NullString `db:"remote_policy"`
+		Seniority          sql.NullString `db:"seniority"`
+		SalaryMin          *int           `db:"salary_min"`
+		SalaryMax          *int           `db:"salary_max"`
+		SalaryCurrency     sql.NullString `db:"salary_currency"`
+		SalaryPeriod       sql.NullString `db:"salary_period"`
+		Model              string         `db:"model"`
+		PromptVersion      int            `db:"prompt_version"`
+		ExtractedAt        time.Time      `db:"extracted_at"`
+	}
+
+	err := s.db.GetContext(ctx, &row, `
+		SELECT job_id, min_years_experience, education_level, remote_policy, seniority,
+		       salary_min, salary_max, salary_currency, salary_period,
+		       model, prompt_version, extracted_at
+		FROM job_facts
+		WHERE job_id = $1`,
+		jobID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get job facts: %w", err)
+	}
+
+	facts := models.JobFacts{
+		JobID:              row.JobID,
+		MinYearsExperience: row.MinYearsExperience,
+		EducationLevel:     row.EducationLevel.String,
+		RemotePolicy:       row.RemotePolicy.String,
+		Seniority:          row.Seniority.String,
+		Model:              row.Model,
+		PromptVersion:      row.PromptVersion,
+		ExtractedAt:        row.ExtractedAt,
+	}
+	if row.SalaryMin != nil || row.SalaryMax != nil {
+		facts.Salary = &models.Salary{
+			Min:      row.SalaryMin,
+			Max:      row.SalaryMax,
+			Currency: row.SalaryCurrency.String,
+			Period:   row.SalaryPeriod.String,
+		}
+	}
+
+	facts.Skills = make([]models.JobSkill, 0)
+	err = s.db.SelectContext(ctx, &facts.Skills, `
+		SELECT skill, required
+		FROM job_skills
+		WHERE job_id = $1
+		ORDER BY required DESC, skill`,
+		jobID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job skills: %w", err)
+	}
+
+	var languages []struct {
+		Language string         `db:"language"`
+		Level    sql.NullString `db:"level"`
+		Required bool           `db:"required"`
+	}
+	err = s.db.SelectContext(ctx, &languages, `
+		SELECT language, level, required
+		FROM job_languages
+		WHERE job_id = $1
+		ORDER BY required DESC, language`,
+		jobID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job languages: %w", err)
+	}
+	facts.Languages = make([]models.JobLanguage, len(languages))
+	for i, l := range languages {
+		facts.Languages[i] = models.JobLanguage{Language: l.Language, Level: l.Level.String, Required: l.Required}
+	}
+
+	return &facts, nil
+}
+
+// GetJobIDsWithoutFacts returns the IDs of jobs with a description that have no facts yet or
+// facts of a prompt version older than promptVersion, newest first (limit 0 = all).
+func (s *Store) GetJobIDsWithoutFacts(ctx context.Context, promptVersion, limit int) ([]string, error) {
+	var ids []string
+	err := s.db.SelectContext(ctx, &ids, `
+		SELECT j.id
+		FROM jobs j
+		LEFT JOIN job_facts f ON f.job_id = j.id
+		WHERE (f.job_id IS NULL OR f.prompt_version < $1)
+		  AND EXISTS (
+			SELECT 1 FROM job_descriptions jd
+			WHERE jd.job_id = j.id AND jd.description <> ''
+		  )
+		ORDER BY j.created_time DESC, j.id
+		LIMIT NULLIF($2, 0)`,
+		promptVersion, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs without facts: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,29 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"ai_job_processing/internal/models"
+)
+
+// SaveLanguageDetection stores the detected source language of a job (migration 019).
+func (s *Store) SaveLanguageDetection(ctx context.Context, jobID string, d *models.LanguageDetection) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET source_language = $1,
+		    source_language_confidence = $2,
+		    source_language_method = $3,
+		    source_language_detected_at = $4
+		WHERE id = $5`,
+		d.Language,
+		d.Confidence,
+		d.Method,
+		d.DetectedAt,
+		jobID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save language detection: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ai_job_processing/internal/models"
+)
+
+// queueColumns lists the ai_job_queue columns scanned into models.QueueItem; queries alias the table as q.
+const queueColumns = `q.id, q.job_id, q.mode, q.status, q.attempts, q.max_attempts, q.available_at,
+	q.locked_by, q.last_error, q.run_id, q.created_at, q.updated_at, q.completed_at`
+
+// ClaimQueueItems claims up to limit items that are due: pending items past their backoff and
+// processing items whose visibility timeout expired because their worker died. Claimed items
+// stay invisible to other workers for visibility; concurrent workers skip each other's rows.
+func (s *Store) ClaimQueueItems(ctx context.Context, workerID string, limit int, visibility time.Duration) ([]models.QueueItem, error) {
+	var items []models.QueueItem
+	err := s.db.SelectContext(ctx, &items, `
+		WITH due AS (
+			SELECT id
+			FROM ai_job_queue
+			WHERE status IN ('pending', 'processing')
+			  AND available_at <= NOW()
+			  AND attempts < max_attempts
+			ORDER BY available_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE ai_job_queue q
+		SET status = 'processing',
+		    attempts = q.attempts + 1,
+		    available_at = NOW() + $2 * INTERVAL '1 second',
+		    locked_by = $3,
+		    updated_at = NOW()
+		FROM due
+		WHERE q.id = due.id
+		RETURNING `+queueColumns,
+		limit, visibility.Seconds(), workerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim queue items: %w", err)
+	}
+	return items, nil
+}
+
+// DeadLetterExpiredItems dead-letters processing items whose visibility timeout expired
+// on their last attempt, i.e. whose worker kept dying or timing out.
+func (s *Store) DeadLetterExpiredItems(ctx context.Context) (int, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE ai_job_queue
+		SET status = 'dead',
+		    last_error = 'visibility timeout expired on attempt ' || attempts,
+		    locked_by = NULL,
+		    updated_at = NOW()
+		WHERE status = 'processing'
+		  AND available_at <= NOW()
+		  AND attempts >= max_attempts`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to dead-letter expired queue items: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// CompleteQueueItem marks a claimed item as done. It returns false if the claim was lost,
+// i.e. the visibility timeout expired and the item was claimed again in the meantime.
+func (s *Store) CompleteQueueItem(ctx context.Context, item *models.QueueItem) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE ai_job_queue
+		SET status = 'done', completed_at = NOW(), last_error = NULL, locked_by = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'processing' AND attempts = $2`,
+		item.ID, item.Attempts,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to complete queue item: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// FailQueueItem records a failed attempt of a claimed item. The item is retried after
+// retryAfter, or dead-lettered if permanent is set or it has no attempts left.
+// It returns the item's new status, or "" if the claim was lost.
+func (s *Store) FailQueueItem(ctx context.Context, item *models.QueueItem, errMsg string, retryAfter time.Duration, permanent bool) (string, error) {
+	var status string
+	err := s.db.GetContext(ctx, &status, `
+		UPDATE ai_job_queue
+		SET status = CASE WHEN $3 OR attempts >= max_attempts THEN 'dead' ELSE 'pending' END,
+		    available_at = NOW() + $4 * INTERVAL '1 second',
+		    last_error = $5,
+		    locked_by = NULL,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'processing' AND attempts = $2
+		RETURNING status`,
+		item.ID, item.Attempts, permanent, retryAfter.Seconds(), errMsg,
+	)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to record queue item failure: %w", err)
+	}
+	return status, nil
+}
+
+// DeferQueueItem puts a claimed item back as pending until retryAfter has passed without
+// counting the attempt, for items that did not fail but could not run yet (e.g. the daily AI
+// budget is used up). It returns false if the claim was lost.
+func (s *Store) DeferQueueItem(ctx context.Context, item *models.QueueItem, reason string, retryAfter time.Duration) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE ai_job_queue
+		SET status = 'pending',
+		    attempts = attempts - 1,
+		    available_at = NOW() + $3 * INTERVAL '1 second',
+		    last_error = $4,
+		    locked_by = NULL,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'processing' AND attempts = $2`,
+		item.ID, item.Attempts, retryAfter.Seconds(), reason,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to defer queue item: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// PurgeQueueItems deletes done items completed before olderThan ago.
+func (s *Store) PurgeQueueItems(ctx context.Context, olderThan time.Duration) (int, error) {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM ai_job_queue
+		WHERE status = 'done' AND completed_at < NOW() - $1 * INTERVAL '1 second'`,
+		olderThan.Seconds(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge queue items: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// GetQueueStats counts queue items by status.
+func (s *Store) GetQueueStats(ctx context.Context) (*models.QueueStats, error) {
+	var stats models.QueueStats
+	err := s.db.GetContext(ctx, &stats, `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+			COUNT(*) FILTER (WHERE status = 'processing') AS processing,
+			COUNT(*) FILTER (WHERE status = 'done') AS done,
+			COUNT(*) FILTER (WHERE status = 'dead') AS dead,
+			MIN(available_at) FILTER (WHERE status = 'pending') AS oldest_due
+		FROM ai_job_queue`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue stats: %w", err)
+	}
+	return &stats, nil
+}
+
+// ListDeadLetters returns the most recently dead-lettered items.
+func (s *Store) ListDeadLetters(ctx context.Context, limit int) ([]models.QueueItem, error) {
+	var items []models.QueueItem
+	err := s.db.SelectContext(ctx, &items, `
+		SELECT `+queueColumns+`
+		FROM ai_job_queue q
+		WHERE q.status = 'dead'
+		ORDER BY q.updated_at DESC, q.id DESC
+		LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return items, nil
+}
+
+// RequeueDeadLetters gives dead-lettered items a fresh set of attempts, all of them if ids is
+// empty. Only the latest dead item per job and mode is requeued, and none whose job was
+// queued again in the meantime.
+func (s *Store) RequeueDeadLetters(ctx context.Context, ids []int64) (int, error) {
+	if ids == nil {
+		ids = []int64{}
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE ai_job_queue q
+		SET status = 'pending', attempts = 0, available_at = NOW(), locked_by = NULL, updated_at = NOW()
+		WHERE q.id IN (
+			SELECT DISTINCT ON (job_id, mode) id
+			FROM ai_job_queue
+			WHERE status = 'dead' AND (cardinality($1::bigint[]) = 0 OR id = ANY($1))
+			ORDER BY job_id, mode, id DESC
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM ai_job_queue a
+			WHERE a.job_id = q.job_id AND a.mode = q.mode AND a.status IN ('pending', 'processing')
+		  )`,
+		ids,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue dead letters: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
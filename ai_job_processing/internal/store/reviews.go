@@ -0,0 +1,176 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ai_job_processing/internal/models"
+)
+
+// GetGlossary returns the glossary terms of a tenant and those for all tenants (migration 020).
+func (s *Store) GetGlossary(ctx context.Context, tenant string) ([]models.GlossaryTerm, error) {
+	var terms []models.GlossaryTerm
+	err := s.db.SelectContext(ctx, &terms, `
+		SELECT id, tenant, term, COALESCE(target_language, '') AS target_language, COALESCE(translation, '') AS translation
+		FROM glossary_terms
+		WHERE tenant IN ('', $1)
+		ORDER BY LOWER(term), target_language NULLS FIRST, tenant`,
+		tenant,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get glossary: %w", err)
+	}
+	return terms, nil
+}
+
+// AddGlossaryTerm adds a glossary term, replacing the translation of an existing term of the
+// same tenant and target language.
+func (s *Store) AddGlossaryTerm(ctx context.Context, term *models.GlossaryTerm) error {
+	err := s.db.GetContext(ctx, &term.ID, `
+		INSERT INTO glossary_terms (tenant, term, target_language, translation)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''))
+		ON CONFLICT (tenant, LOWER(term), COALESCE(target_language, '')) DO UPDATE
+		SET term = EXCLUDED.term, translation = EXCLUDED.translation
+		RETURNING id`,
+		term.Tenant, strings.TrimSpace(term.Term), term.TargetLanguage, strings.TrimSpace(term.Translation),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add glossary term: %w", err)
+	}
+	return nil
+}
+
+// DeleteGlossaryTerm deletes a glossary term. It reports whether the term existed.
+func (s *Store) DeleteGlossaryTerm(ctx context.Context, id int64) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM glossary_terms WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete glossary term: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// GetCompanyName returns the name of a job's company, or "" if it has none.
+func (s *Store) GetCompanyName(ctx context.Context, jobID string) (string, error) {
+	var name string
+	err := s.db.GetContext(ctx, &name, `
+		SELECT c.name
+		FROM jobs j
+		JOIN companies c ON c.id = j.company_id
+		WHERE j.id = $1`,
+		jobID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get company name: %w", err)
+	}
+	return name, nil
+}
+
+// SaveTranslationReviews flags translations of a job for review, replacing the problems of an
+// open review of the same language. The IDs and job ID of reviews are set.
+func (s *Store) SaveTranslationReviews(ctx context.Context, jobID string, reviews []models.TranslationReview) error {
+	for i := range reviews {
+		r := &reviews[i]
+		problems, err := json.Marshal(r.Problems)
+		if err != nil {
+			return fmt.Errorf("failed to encode review problems: %w", err)
+		}
+		err = s.db.GetContext(ctx, &r.ID, `
+			INSERT INTO translation_reviews (job_id, language, problems, kept, model, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (job_id, language) WHERE resolved_at IS NULL DO UPDATE
+			SET problems = EXCLUDED.problems,
+			    kept = EXCLUDED.kept,
+			    model = EXCLUDED.model,
+			    created_at = EXCLUDED.created_at
+			RETURNING id`,
+			jobID, r.Language, string(problems), r.Kept, r.Model, r.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save translation review for %s: %w", r.Language, err)
+		}
+		r.JobID = jobID
+	}
+	return nil
+}
+
+// ResolveJobReviews resolves the open reviews of a job's translations into languages, after
+// they were translated again without problems.
+func (s *Store) ResolveJobReviews(ctx context.Context, jobID string, languages []string) (int, error) {
+	if len(languages) == 0 {
+		return 0, nil
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE translation_reviews
+		SET resolved_at = NOW()
+		WHERE job_id = $1 AND language = ANY($2) AND resolved_at IS NULL`,
+		jobID, languages,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve translation reviews: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// ResolveReviews resolves open reviews, all of them if ids is empty.
+func (s *Store) ResolveReviews(ctx context.Context, ids []int64) (int, error) {
+	if ids == nil {
+		ids = []int64{}
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE translation_reviews
+		SET resolved_at = NOW()
+		WHERE resolved_at IS NULL AND (cardinality($1::bigint[]) = 0 OR id = ANY($1))`,
+		ids,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve translation reviews: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// ListOpenReviews returns the most recent open reviews.
+func (s *Store) ListOpenReviews(ctx context.Context, limit int) ([]models.TranslationReview, error) {
+	var rows []struct {
+		models.TranslationReview
+		Problems []byte `db:"problems"`
+	}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT id, job_id, language, problems, kept, model, created_at, resolved_at
+		FROM translation_reviews
+		WHERE resolved_at IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list translation reviews: %w", err)
+	}
+
+	reviews := make([]models.TranslationReview, len(rows))
+	for i, row := range rows {
+		reviews[i] = row.TranslationReview
+		if err := json.Unmarshal(row.Problems, &reviews[i].Problems); err != nil {
+			return nil, fmt.Errorf("failed to decode problems of translation review %d: %w", row.ID, err)
+		}
+	}
+	return reviews, nil
+}
+
+// CountOpenReviews returns the number of open reviews.
+func (s *Store) CountOpenReviews(ctx context.Context) (int, error) {
+	var n int
+	if err := s.db.GetContext(ctx, &n, `SELECT COUNT(*) FROM translation_reviews WHERE resolved_at IS NULL`); err != nil {
+		return 0, fmt.Errorf("failed to count translation reviews: %w", err)
+	}
+	return n, nil
+}
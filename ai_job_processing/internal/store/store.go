@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,6 +12,9 @@ import (
 	"ai_job_processing/internal/models"
 )
 
+// ErrJobNotFound is returned when a job ID does not exist in the database.
+var ErrJobNotFound = errors.New("job not found")
+
 // Store handles database operations.
 type Store struct {
 	db *sqlx.DB
@@ -26,6 +30,14 @@ func (s *Store) DB() *sqlx.DB {
 	return s.db
 }
 
+// Ping checks that the database is reachable.
+func (s *Store) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
 // GetJobByID retrieves a job and its primary description by job ID.
 func (s *Store) GetJobByID(ctx context.Context, jobID string) (*models.JobFromDB, error) {
 	var job models.JobFromDB
@@ -46,7 +58,7 @@ func (s *Store) GetJobByID(ctx context.Context, jobID string) (*models.JobFromDB
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("job not found: %s", jobID)
+			return nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
 		}
 		return nil, fmt.Errorf("failed to get job: %w", err)
 	}
@@ -116,13 +128,14 @@ func (s *Store) GetMissingLanguages(ctx context.Context, jobID string, targetLan
 // GetExistingNormalizedContent retrieves already-normalized content for a job.
 func (s *Store) GetExistingNormalizedContent(ctx context.Context, jobID string) (*models.NormalizedContent, []models.TranslatedContent, error) {
 	var normalized struct {
-		Tasks        sql.NullString `db:"tasks"`
-		Requirements sql.NullString `db:"requirements"`
-		Offer        sql.NullString `db:"offer"`
+		Tasks         sql.NullString `db:"tasks"`
+		Requirements  sql.NullString `db:"requirements"`
+		Offer         sql.NullString `db:"offer"`
+		PromptVersion sql.NullInt64  `db:"normalization_prompt_version"`
 	}
 
 	err := s.db.GetContext(ctx, &normalized, `
-		SELECT tasks, requirements, offer
+		SELECT tasks, requirements, offer, normalization_prompt_version
 		FROM job_descriptions
 		WHERE job_id = $1 AND is_normalized = TRUE
 		ORDER BY id ASC
@@ -137,23 +150,27 @@ func (s *Store) GetExistingNormalizedContent(ctx context.Context, jobID string)
 	}
 
 	normalizedContent := &models.NormalizedContent{
-		Tasks:        normalized.Tasks.String,
-		Requirements: normalized.Requirements.String,
-		Offer:        normalized.Offer.String,
+		Tasks:         normalized.Tasks.String,
+		Requirements:  normalized.Requirements.String,
+		Offer:         normalized.Offer.String,
+		PromptVersion: int(normalized.PromptVersion.Int64),
 	}
 
 	// Get all translations
 	var translations []struct {
-		Language     string         `db:"language_iso_code"`
-		Title        string         `db:"title"`
-		Description  string         `db:"description"`
-		Tasks        sql.NullString `db:"tasks"`
-		Requirements sql.NullString `db:"requirements"`
-		Offer        sql.NullString `db:"offer"`
+		Language      string         `db:"language_iso_code"`
+		Title         string         `db:"title"`
+		Description   string         `db:"description"`
+		Tasks         sql.NullString `db:"tasks"`
+		Requirements  sql.NullString `db:"requirements"`
+		Offer         sql.NullString `db:"offer"`
+		Prompt        sql.NullString `db:"translation_prompt"`
+		PromptVersion sql.NullInt64  `db:"translation_prompt_version"`
 	}
 
 	err = s.db.SelectContext(ctx, &translations, `
-		SELECT language_iso_code, title, description, tasks, requirements, offer
+		SELECT language_iso_code, title, description, tasks, requirements, offer,
+		       translation_prompt, translation_prompt_version
 		FROM job_descriptions
 		WHERE job_id = $1
 		ORDER BY language_iso_code`,
@@ -166,12 +183,14 @@ func (s *Store) GetExistingNormalizedContent(ctx context.Context, jobID string)
 	translatedContent := make([]models.TranslatedContent, len(translations))
 	for i, t := range translations {
 		translatedContent[i] = models.TranslatedContent{
-			Language:     t.Language,
-			Title:        t.Title,
-			Description:  t.Description,
-			Tasks:        t.Tasks.String,
-			Requirements: t.Requirements.String,
-			Offer:        t.Offer.String,
+			Language:      t.Language,
+			Title:         t.Title,
+			Description:   t.Description,
+			Tasks:         t.Tasks.String,
+			Requirements:  t.Requirements.String,
+			Offer:         t.Offer.String,
+			Prompt:        t.Prompt.String,
+			PromptVersion: int(t.PromptVersion.Int64),
 		}
 	}
 
@@ -181,16 +200,19 @@ func (s *Store) GetExistingNormalizedContent(ctx context.Context, jobID string)
 // GetExistingTranslations retrieves all existing translations for a job.
 func (s *Store) GetExistingTranslations(ctx context.Context, jobID string) ([]models.TranslatedContent, error) {
 	var translations []struct {
-		Language     string         `db:"language_iso_code"`
-		Title        string         `db:"title"`
-		Description  string         `db:"description"`
-		Tasks        sql.NullString `db:"tasks"`
-		Requirements sql.NullString `db:"requirements"`
-		Offer        sql.NullString `db:"offer"`
+		Language      string         `db:"language_iso_code"`
+		Title         string         `db:"title"`
+		Description   string         `db:"description"`
+		Tasks         sql.NullString `db:"tasks"`
+		Requirements  sql.NullString `db:"requirements"`
+		Offer         sql.NullString `db:"offer"`
+		Prompt        sql.NullString `db:"translation_prompt"`
+		PromptVersion sql.NullInt64  `db:"translation_prompt_version"`
 	}
 
 	err := s.db.SelectContext(ctx, &translations, `
-		SELECT language_iso_code, title, description, tasks, requirements, offer
+		SELECT language_iso_code, title, description, tasks, requirements, offer,
+		       translation_prompt, translation_prompt_version
 		FROM job_descriptions
 		WHERE job_id = $1
 		ORDER BY language_iso_code`,
@@ -203,12 +225,14 @@ func (s *Store) GetExistingTranslations(ctx context.Context, jobID string) ([]mo
 	result := make([]models.TranslatedContent, len(translations))
 	for i, t := range translations {
 		result[i] = models.TranslatedContent{
-			Language:     t.Language,
-			Title:        t.Title,
-			Description:  t.Description,
-			Tasks:        t.Tasks.String,
-			Requirements: t.Requirements.String,
-			Offer:        t.Offer.String,
+			Language:      t.Language,
+			Title:         t.Title,
+			Description:   t.Description,
+			Tasks:         t.Tasks.String,
+			Requirements:  t.Requirements.String,
+			Offer:         t.Offer.String,
+			Prompt:        t.Prompt.String,
+			PromptVersion: int(t.PromptVersion.Int64),
 		}
 	}
 
@@ -246,13 +270,15 @@ func (s *Store) SaveNormalizedContent(ctx context.Context, jobID, language strin
 			tasks = $1,
 			requirements = $2,
 			offer = $3,
+			normalization_prompt_version = NULLIF($4, 0),
 			is_normalized = TRUE,
-			normalized_at = $4,
+			normalized_at = $5,
 			updated_at = NOW()
-		WHERE job_id = $5 AND language_iso_code = $6`,
+		WHERE job_id = $6 AND language_iso_code = $7`,
 		normalized.Tasks,
 		normalized.Requirements,
 		normalized.Offer,
+		normalized.PromptVersion,
 		time.Now(),
 		jobID,
 		language,
@@ -269,16 +295,21 @@ func (s *Store) SaveTranslation(ctx context.Context, jobID string, translated *m
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO job_descriptions (
 			job_id, language_iso_code, title, description,
+			translation_prompt, translation_prompt_version,
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, NOW(), NOW())
+		) VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, 0), NOW(), NOW())
 		ON CONFLICT (job_id, language_iso_code) DO UPDATE SET
 			title = EXCLUDED.title,
 			description = EXCLUDED.description,
+			translation_prompt = EXCLUDED.translation_prompt,
+			translation_prompt_version = EXCLUDED.translation_prompt_version,
 			updated_at = NOW()`,
 		jobID,
 		translated.Language,
 		translated.Title,
 		translated.Description,
+		translated.Prompt,
+		translated.PromptVersion,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to save translation: %w", err)
@@ -293,8 +324,9 @@ func (s *Store) SaveNormalizedTranslation(ctx context.Context, jobID string, tra
 		INSERT INTO job_descriptions (
 			job_id, language_iso_code, title, description,
 			tasks, requirements, offer, is_normalized, normalized_at,
+			translation_prompt, translation_prompt_version,
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, TRUE, $8, NOW(), NOW())
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, TRUE, $8, NULLIF($9, ''), NULLIF($10, 0), NOW(), NOW())
 		ON CONFLICT (job_id, language_iso_code) DO UPDATE SET
 			title = EXCLUDED.title,
 			description = EXCLUDED.description,
@@ -303,6 +335,8 @@ func (s *Store) SaveNormalizedTranslation(ctx context.Context, jobID string, tra
 			offer = EXCLUDED.offer,
 			is_normalized = TRUE,
 			normalized_at = EXCLUDED.normalized_at,
+			translation_prompt = EXCLUDED.translation_prompt,
+			translation_prompt_version = EXCLUDED.translation_prompt_version,
 			updated_at = NOW()`,
 		jobID,
 		translated.Language,
@@ -312,6 +346,8 @@ func (s *Store) SaveNormalizedTranslation(ctx context.Context, jobID string, tra
 		translated.Requirements,
 		translated.Offer,
 		time.Now(),
+		translated.Prompt,
+		translated.PromptVersion,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to save normalized translation: %w", err)
@@ -320,8 +356,10 @@ func (s *Store) SaveNormalizedTranslation(ctx context.Context, jobID string, tra
 	return nil
 }
 
-// SaveAllTranslations saves all translations for a job (with normalization).
-func (s *Store) SaveAllTranslations(ctx context.Context, jobID string, translations []models.TranslatedContent, normalized bool) error {
+// SaveAllTranslations saves all translations for a job with the prompt versions behind them.
+// normalized is the normalized content the translations were made from, nil for
+// translations of the raw description (saved without normalization fields).
+func (s *Store) SaveAllTranslations(ctx context.Context, jobID string, translations []models.TranslatedContent, normalized *models.NormalizedContent) error {
 	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -329,14 +367,15 @@ func (s *Store) SaveAllTranslations(ctx context.Context, jobID string, translati
 	defer tx.Rollback()
 
 	for _, t := range translations {
-		if normalized {
+		if normalized != nil {
 			// Save with normalization fields
 			_, err := tx.ExecContext(ctx, `
 				INSERT INTO job_descriptions (
 					job_id, language_iso_code, title, description,
 					tasks, requirements, offer, is_normalized, normalized_at,
+					normalization_prompt_version, translation_prompt, translation_prompt_version,
 					created_at, updated_at
-				) VALUES ($1, $2, $3, $4, $5, $6, $7, TRUE, $8, NOW(), NOW())
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, TRUE, $8, NULLIF($9, 0), NULLIF($10, ''), NULLIF($11, 0), NOW(), NOW())
 				ON CONFLICT (job_id, language_iso_code) DO UPDATE SET
 					title = EXCLUDED.title,
 					description = EXCLUDED.description,
@@ -345,6 +384,9 @@ func (s *Store) SaveAllTranslations(ctx context.Context, jobID string, translati
 					offer = EXCLUDED.offer,
 					is_normalized = TRUE,
 					normalized_at = EXCLUDED.normalized_at,
+					normalization_prompt_version = EXCLUDED.normalization_prompt_version,
+					translation_prompt = EXCLUDED.translation_prompt,
+					translation_prompt_version = EXCLUDED.translation_prompt_version,
 					updated_at = NOW()`,
 				jobID,
 				t.Language,
@@ -354,6 +396,9 @@ func (s *Store) SaveAllTranslations(ctx context.Context, jobID string, translati
 				t.Requirements,
 				t.Offer,
 				time.Now(),
+				normalized.PromptVersion,
+				t.Prompt,
+				t.PromptVersion,
 			)
 			if err != nil {
 				return fmt.Errorf("failed to save translation for %s: %w", t.Language, err)
@@ -363,16 +408,21 @@ func (s *Store) SaveAllTranslations(ctx context.Context, jobID string, translati
 			_, err := tx.ExecContext(ctx, `
 				INSERT INTO job_descriptions (
 					job_id, language_iso_code, title, description,
+					translation_prompt, translation_prompt_version,
 					created_at, updated_at
-				) VALUES ($1, $2, $3, $4, NOW(), NOW())
+				) VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, 0), NOW(), NOW())
 				ON CONFLICT (job_id, language_iso_code) DO UPDATE SET
 					title = EXCLUDED.title,
 					description = EXCLUDED.description,
+					translation_prompt = EXCLUDED.translation_prompt,
+					translation_prompt_version = EXCLUDED.translation_prompt_version,
 					updated_at = NOW()`,
 				jobID,
 				t.Language,
 				t.Title,
 				t.Description,
+				t.Prompt,
+				t.PromptVersion,
 			)
 			if err != nil {
 				return fmt.Errorf("failed to save translation for %s: %w", t.Language, err)
@@ -412,6 +462,31 @@ func (s *Store) GetPendingJobs(ctx context.Context, limit int) ([]models.JobFrom
 	return jobs, nil
 }
 
+// GetPendingJobIDs returns the IDs of jobs that haven't been normalized, newest first
+// (limit 0 = all). Jobs waiting in ai_job_queue are left to the queue worker.
+func (s *Store) GetPendingJobIDs(ctx context.Context, limit int) ([]string, error) {
+	var ids []string
+	err := s.db.SelectContext(ctx, &ids, `
+		SELECT j.id
+		FROM jobs j
+		WHERE EXISTS (
+			SELECT 1 FROM job_descriptions jd
+			WHERE jd.job_id = j.id AND jd.is_normalized IS NOT TRUE
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM ai_job_queue q
+			WHERE q.job_id = j.id AND q.status IN ('pending', 'processing')
+		  )
+		ORDER BY j.created_time DESC, j.id
+		LIMIT NULLIF($1, 0)`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending job IDs: %w", err)
+	}
+	return ids, nil
+}
+
 // CountPendingJobs counts jobs that haven't been normalized.
 func (s *Store) CountPendingJobs(ctx context.Context) (int, error) {
 	var count int
@@ -0,0 +1,322 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"ai_job_processing/internal/models"
+	"ai_job_processing/internal/processor"
+	"ai_job_processing/internal/store"
+	"llmkit/llm"
+)
+
+const (
+	// maxRetryDelay caps the exponential retry backoff
+	maxRetryDelay = time.Hour
+
+	// minQuotaRetryDelay is the least backoff after the model quota ran out
+	minQuotaRetryDelay = 5 * time.Minute
+
+	// purgeInterval is how often done items past their retention are deleted
+	purgeInterval = time.Hour
+)
+
+// errUnknownMode is returned for a queue item with a mode the worker cannot process.
+var errUnknownMode = errors.New("unknown processing mode")
+
+// Config holds queue worker configuration.
+type Config struct {
+	ID           string        // Identifies this worker in locked_by (default hostname-pid)
+	Concurrency  int           // Items processed at the same time
+	PollInterval time.Duration // Wait between polls when no item is due
+	Visibility   time.Duration // How long a claimed item stays hidden; also its processing timeout
+	RetryBase    time.Duration // First retry delay, doubled per attempt up to maxRetryDelay
+	Retention    time.Duration // Done items older than this are purged (0 = keep)
+}
+
+// Worker drains ai_job_queue: it claims due items with SKIP LOCKED, so any number of workers
+// can share the queue, runs them through the processor and records the outcome.
+// Failed items are retried with exponential backoff and dead-lettered when they run out of
+// attempts or cannot succeed. Items of a crashed worker reappear after their visibility timeout.
+type Worker struct {
+	processor *processor.Processor
+	store     *store.Store
+	config    Config
+
+	mu          sync.Mutex // guards pausedUntil
+	pausedUntil time.Time  // no items are claimed before this, set when the daily AI budget is used up
+}
+
+// New creates a new Worker.
+func New(proc *processor.Processor, st *store.Store, cfg Config) *Worker {
+	if cfg.ID == "" {
+		host, _ := os.Hostname()
+		cfg.ID = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.Visibility <= 0 {
+		cfg.Visibility = 10 * time.Minute
+	}
+	if cfg.RetryBase <= 0 {
+		cfg.RetryBase = 30 * time.Second
+	}
+	return &Worker{
+		processor: proc,
+		store:     st,
+		config:    cfg,
+	}
+}
+
+// Run processes queue items until ctx is cancelled. It then stops claiming and waits for the
+// items in progress, which keep running (bounded by the visibility timeout) so their results
+// are saved instead of being redone by the next worker.
+func (w *Worker) Run(ctx context.Context) error {
+	slog.Info("queue worker started",
+		"worker_id", w.config.ID,
+		"concurrency", w.config.Concurrency,
+		"visibility", w.config.Visibility,
+	)
+
+	var (
+		wg       sync.WaitGroup
+		slots    = make(chan struct{}, w.config.Concurrency) // one token per item in progress
+		finished = make(chan struct{}, w.config.Concurrency) // wakes the loop when a slot frees up
+		purged   time.Time
+	)
+	defer func() {
+		wg.Wait()
+		slog.Info("queue worker stopped", "worker_id", w.config.ID)
+	}()
+
+	for {
+		if w.config.Retention > 0 && time.Since(purged) >= purgeInterval {
+			w.purge(ctx)
+			purged = time.Now()
+		}
+
+		free := cap(slots) - len(slots)
+		claimed := 0
+		if free > 0 && !w.paused() {
+			items, err := w.claim(ctx, free)
+			if err != nil && ctx.Err() == nil {
+				slog.Error("failed to claim queue items", "error", err)
+			}
+			for i := range items {
+				item := items[i]
+				slots <- struct{}{}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					w.handle(ctx, &item)
+					<-slots
+					select {
+					case finished <- struct{}{}:
+					default:
+					}
+				}()
+			}
+			claimed = len(items)
+		}
+
+		// A full batch suggests more items are due, so claim again as soon as a slot is free
+		wait := w.config.PollInterval
+		if claimed > 0 && claimed == free {
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-finished:
+		case <-time.After(wait):
+		}
+	}
+}
+
+// claim dead-letters items that timed out on their last attempt and claims up to limit due items.
+func (w *Worker) claim(ctx context.Context, limit int) ([]models.QueueItem, error) {
+	dead, err := w.store.DeadLetterExpiredItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dead > 0 {
+		slog.Warn("dead-lettered queue items that timed out on their last attempt", "count", dead)
+	}
+	return w.store.ClaimQueueItems(ctx, w.config.ID, limit, w.config.Visibility)
+}
+
+// handle processes one claimed item and records the outcome. It is detached from ctx so that
+// shutdown does not abort a half-finished AI call; the visibility timeout bounds it instead.
+func (w *Worker) handle(ctx context.Context, item *models.QueueItem) {
+	itemCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.config.Visibility)
+	defer cancel()
+
+	start := time.Now()
+	skipped, err := w.process(itemCtx, item)
+	// Record the outcome even if the item used up its whole timeout
+	recordCtx := context.WithoutCancel(ctx)
+
+	if err == nil {
+		ok, err := w.store.CompleteQueueItem(recordCtx, item)
+		switch {
+		case err != nil:
+			slog.Error("failed to complete queue item", "item_id", item.ID, "job_id", item.JobID, "error", err)
+		case !ok:
+			slog.Warn("queue item was reclaimed before it completed", "item_id", item.ID, "job_id", item.JobID)
+		default:
+			slog.Info("queue item done",
+				"item_id", item.ID,
+				"job_id", item.JobID,
+				"mode", item.Mode,
+				"attempt", item.Attempts,
+				"skipped", skipped,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+		}
+		return
+	}
+
+	if errors.Is(err, llm.ErrBudgetExceeded) {
+		w.postpone(recordCtx, item, err)
+		return
+	}
+
+	// A refused prompt is refused again; invalid output may well be fixed by the next attempt
+	permanent := errors.Is(err, store.ErrJobNotFound) || errors.Is(err, processor.ErrNoContent) ||
+		errors.Is(err, errUnknownMode) || errors.Is(err, llm.ErrRefused)
+	retryAfter := w.retryDelay(item.Attempts)
+	if errors.Is(err, llm.ErrQuota) {
+		retryAfter = max(retryAfter, minQuotaRetryDelay)
+	}
+	status, recErr := w.store.FailQueueItem(recordCtx, item, err.Error(), retryAfter, permanent)
+	switch {
+	case recErr != nil:
+		slog.Error("failed to record queue item failure", "item_id", item.ID, "job_id", item.JobID, "error", recErr)
+	case status == "":
+		slog.Warn("queue item was reclaimed before it failed", "item_id", item.ID, "job_id", item.JobID, "error", err)
+	case status == models.QueueStatusDead:
+		slog.Error("queue item dead-lettered",
+			"item_id", item.ID,
+			"job_id", item.JobID,
+			"mode", item.Mode,
+			"attempt", item.Attempts,
+			"permanent", permanent,
+			"error", err,
+		)
+	default:
+		slog.Warn("queue item failed, will retry",
+			"item_id", item.ID,
+			"job_id", item.JobID,
+			"mode", item.Mode,
+			"attempt", item.Attempts,
+			"retry_in", retryAfter,
+			"error", err,
+		)
+	}
+}
+
+// postpone puts back an item that hit the daily AI budget without using up an attempt and
+// stops claiming items until the budget is reset at midnight UTC.
+func (w *Worker) postpone(ctx context.Context, item *models.QueueItem, err error) {
+	reset := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+
+	w.mu.Lock()
+	pausing := w.pausedUntil.Before(reset)
+	if pausing {
+		w.pausedUntil = reset
+	}
+	w.mu.Unlock()
+	if pausing {
+		slog.Warn("daily AI budget used up, pausing the queue", "until", reset, "error", err)
+	}
+
+	ok, recErr := w.store.DeferQueueItem(ctx, item, err.Error(), time.Until(reset))
+	switch {
+	case recErr != nil:
+		slog.Error("failed to defer queue item", "item_id", item.ID, "job_id", item.JobID, "error", recErr)
+	case !ok:
+		slog.Warn("queue item was reclaimed before it was deferred", "item_id", item.ID, "job_id", item.JobID)
+	default:
+		slog.Info("queue item deferred until the AI budget is reset",
+			"item_id", item.ID,
+			"job_id", item.JobID,
+			"mode", item.Mode,
+			"retry_in", time.Until(reset).Round(time.Second),
+		)
+	}
+}
+
+// paused reports whether claiming is paused because the daily AI budget is used up.
+func (w *Worker) paused() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return time.Now().Before(w.pausedUntil)
+}
+
+// process runs the item's mode through the processor and reports whether the work was
+// skipped because it had already been done.
+func (w *Worker) process(ctx context.Context, item *models.QueueItem) (bool, error) {
+	switch item.Mode {
+	case models.QueueModeProcess:
+		resp, err := w.processor.ProcessByID(ctx, &models.ProcessByIDRequest{JobID: item.JobID})
+		if err != nil {
+			return false, err
+		}
+		if !resp.Skipped && !resp.SavedToDB {
+			return false, fmt.Errorf("results were not saved")
+		}
+		return resp.Skipped, nil
+	case models.QueueModeNormalize:
+		resp, err := w.processor.NormalizeByID(ctx, &models.NormalizeByIDRequest{JobID: item.JobID})
+		if err != nil {
+			return false, err
+		}
+		if !resp.Skipped && !resp.SavedToDB {
+			return false, fmt.Errorf("results were not saved")
+		}
+		return resp.Skipped, nil
+	case models.QueueModeTranslate:
+		resp, err := w.processor.TranslateByID(ctx, &models.TranslateByIDRequest{JobID: item.JobID})
+		if err != nil {
+			return false, err
+		}
+		if !resp.Skipped && !resp.SavedToDB {
+			return false, fmt.Errorf("results were not saved")
+		}
+		return resp.Skipped, nil
+	default:
+		return false, fmt.Errorf("%w: %s", errUnknownMode, item.Mode)
+	}
+}
+
+// retryDelay returns the backoff after a failed attempt (1-based): RetryBase doubled per attempt.
+func (w *Worker) retryDelay(attempt int) time.Duration {
+	d := w.config.RetryBase << min(max(attempt-1, 0), 16)
+	if d <= 0 || d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d
+}
+
+// purge deletes done items past the retention period.
+func (w *Worker) purge(ctx context.Context) {
+	n, err := w.store.PurgeQueueItems(ctx, w.config.Retention)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("failed to purge queue items", "error", err)
+		}
+		return
+	}
+	if n > 0 {
+		slog.Info("purged done queue items", "count", n, "retention", w.config.Retention)
+	}
+}
@@ -0,0 +1,2 @@
+-- Rollback: Drop llm_cache table
+DROP TABLE IF EXISTS llm_cache;
@@ -0,0 +1,22 @@
+-- Migration: Create llm_cache table
+-- Content-addressed cache of validated model answers, shared by ai_job_processing, auth_service and autoapply_service
+
+CREATE TABLE IF NOT EXISTS llm_cache (
+    key TEXT PRIMARY KEY,
+    prompt TEXT NOT NULL,
+    prompt_version INTEGER NOT NULL,
+    answer TEXT NOT NULL,
+    hits INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_hit_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_cache_prompt ON llm_cache(prompt, prompt_version);
+CREATE INDEX IF NOT EXISTS idx_llm_cache_expires ON llm_cache(expires_at);
+
+COMMENT ON TABLE llm_cache IS 'Validated LLM answers keyed by provider, model, prompt version and prompt text';
+COMMENT ON COLUMN llm_cache.key IS 'SHA-256 of provider, model, prompt@version and the full prompt text';
+COMMENT ON COLUMN llm_cache.prompt IS 'Prompt template name, e.g. normalization';
+COMMENT ON COLUMN llm_cache.prompt_version IS 'Template version; entries of other versions are deleted when a service starts';
+COMMENT ON COLUMN llm_cache.hits IS 'Times the answer was served from the cache';
@@ -0,0 +1,4 @@
+-- Rollback: Drop job facts tables
+DROP TABLE IF EXISTS job_languages;
+DROP TABLE IF EXISTS job_skills;
+DROP TABLE IF EXISTS job_facts;
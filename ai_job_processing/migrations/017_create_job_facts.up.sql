@@ -0,0 +1,46 @@
+-- Migration: Create job_facts, job_skills and job_languages tables
+-- Structured requirements and conditions extracted from job descriptions by ai_job_processing
+
+CREATE TABLE IF NOT EXISTS job_facts (
+    job_id TEXT PRIMARY KEY REFERENCES jobs(id) ON DELETE CASCADE,
+    min_years_experience SMALLINT CHECK (min_years_experience BETWEEN 0 AND 40),
+    education_level TEXT CHECK (education_level IN ('none', 'apprenticeship', 'higher_vocational', 'bachelor', 'master', 'doctorate')),
+    remote_policy TEXT CHECK (remote_policy IN ('onsite', 'hybrid', 'remote')),
+    seniority TEXT CHECK (seniority IN ('intern', 'junior', 'mid', 'senior', 'lead', 'executive')),
+    salary_min INTEGER,
+    salary_max INTEGER,
+    salary_currency CHAR(3),
+    salary_period TEXT CHECK (salary_period IN ('year', 'month', 'hour')),
+    model TEXT NOT NULL,
+    prompt_version INTEGER NOT NULL,
+    extracted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS job_skills (
+    job_id TEXT NOT NULL REFERENCES job_facts(job_id) ON DELETE CASCADE,
+    skill TEXT NOT NULL,
+    required BOOLEAN NOT NULL,
+    PRIMARY KEY (job_id, skill)
+);
+
+CREATE TABLE IF NOT EXISTS job_languages (
+    job_id TEXT NOT NULL REFERENCES job_facts(job_id) ON DELETE CASCADE,
+    language CHAR(2) NOT NULL,
+    level TEXT CHECK (level IN ('A1', 'A2', 'B1', 'B2', 'C1', 'C2', 'native')),
+    required BOOLEAN NOT NULL,
+    PRIMARY KEY (job_id, language)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_facts_prompt ON job_facts(prompt_version, model);
+CREATE INDEX IF NOT EXISTS idx_job_skills_skill ON job_skills(skill);
+CREATE INDEX IF NOT EXISTS idx_job_languages_language ON job_languages(language, level);
+
+COMMENT ON TABLE job_facts IS 'Requirements and conditions extracted from a job description; NULL = not stated';
+COMMENT ON COLUMN job_facts.salary_period IS 'Period the salary bounds refer to: year, month or hour';
+COMMENT ON COLUMN job_facts.model IS 'Model that extracted the facts';
+COMMENT ON COLUMN job_facts.prompt_version IS 'Version of the facts prompt, to find jobs to re-extract after a prompt change';
+COMMENT ON TABLE job_skills IS 'Skills asked for by a job, by canonical name (e.g. Go for golang)';
+COMMENT ON COLUMN job_skills.required IS 'TRUE = required, FALSE = nice to have';
+COMMENT ON TABLE job_languages IS 'Spoken languages asked for by a job';
+COMMENT ON COLUMN job_languages.language IS 'ISO 639-1 code';
+COMMENT ON COLUMN job_languages.level IS 'CEFR level or native; NULL = not stated';
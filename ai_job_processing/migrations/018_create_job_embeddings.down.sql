@@ -0,0 +1,2 @@
+-- Rollback: Drop job_embeddings table (the vector extension is kept for other users)
+DROP TABLE IF EXISTS job_embeddings;
@@ -0,0 +1,27 @@
+-- Migration: Create job_embeddings table
+-- Vector embeddings of normalized job descriptions per language, for semantic search and matching (pgvector)
+
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE IF NOT EXISTS job_embeddings (
+    job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    language_iso_code TEXT NOT NULL,
+    embedding vector(768) NOT NULL,
+    content_hash TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    text_version INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (job_id, language_iso_code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_embeddings_hnsw ON job_embeddings USING hnsw (embedding vector_cosine_ops);
+CREATE INDEX IF NOT EXISTS idx_job_embeddings_model ON job_embeddings(provider, model, text_version);
+
+COMMENT ON TABLE job_embeddings IS 'Embeddings of the normalized title, tasks, requirements and offer of a job description';
+COMMENT ON COLUMN job_embeddings.content_hash IS 'SHA-256 of the embedded text; the description is re-embedded when it changes';
+COMMENT ON COLUMN job_embeddings.provider IS 'LLM provider that computed the embedding, e.g. gemini';
+COMMENT ON COLUMN job_embeddings.model IS 'Embedding model, e.g. text-embedding-004; vectors of different models are not comparable';
+COMMENT ON COLUMN job_embeddings.text_version IS 'Version of the text built for embedding; older versions are re-embedded by the embed command';
+COMMENT ON COLUMN job_embeddings.updated_at IS 'When the embedding was last checked against its description';
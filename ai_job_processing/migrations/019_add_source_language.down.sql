@@ -0,0 +1,23 @@
+-- Rollback: Remove the detected source language from jobs
+
+DROP INDEX IF EXISTS idx_jobs_source_language;
+
+-- Restore canonical_jobs as scrapper migration 013 created it; a view cannot lose columns
+-- through CREATE OR REPLACE
+DROP VIEW IF EXISTS canonical_jobs;
+CREATE VIEW canonical_jobs AS
+SELECT j.id, j.source, j.created_time, j.updated_time, j.status,
+       j.source_system, j.external_ref, j.stellennummer_egov, j.fingerprint, j.reporting_obligation,
+       j.company_id, j.location_id, j.raw_data, j.external_url, j.number_of_positions,
+       j.created_at, j.updated_at, j.last_seen_at, j.last_seen_run_id, j.missed_runs, j.cluster_id
+FROM jobs j
+LEFT JOIN job_clusters c ON c.id = j.cluster_id
+WHERE c.id IS NULL OR c.canonical_job_id = j.id;
+
+COMMENT ON VIEW canonical_jobs IS 'Jobs collapsed to one row per duplicate cluster, for search and matching';
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS source_language,
+DROP COLUMN IF EXISTS source_language_confidence,
+DROP COLUMN IF EXISTS source_language_method,
+DROP COLUMN IF EXISTS source_language_detected_at;
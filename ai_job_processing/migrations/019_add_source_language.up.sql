@@ -0,0 +1,26 @@
+-- Migration: Add the detected source language to jobs
+-- The language a posting is actually written in, which is not always the language job-room
+-- files it under
+
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS source_language CHAR(2),
+ADD COLUMN IF NOT EXISTS source_language_confidence REAL CHECK (source_language_confidence BETWEEN 0 AND 1),
+ADD COLUMN IF NOT EXISTS source_language_method TEXT CHECK (source_language_method IN ('text', 'llm')),
+ADD COLUMN IF NOT EXISTS source_language_detected_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_source_language ON jobs(source_language);
+
+-- canonical_jobs (scrapper migration 013) lists the jobs columns, so add the new ones to it
+CREATE OR REPLACE VIEW canonical_jobs AS
+SELECT j.id, j.source, j.created_time, j.updated_time, j.status,
+       j.source_system, j.external_ref, j.stellennummer_egov, j.fingerprint, j.reporting_obligation,
+       j.company_id, j.location_id, j.raw_data, j.external_url, j.number_of_positions,
+       j.created_at, j.updated_at, j.last_seen_at, j.last_seen_run_id, j.missed_runs, j.cluster_id,
+       j.source_language, j.source_language_confidence, j.source_language_method, j.source_language_detected_at
+FROM jobs j
+LEFT JOIN job_clusters c ON c.id = j.cluster_id
+WHERE c.id IS NULL OR c.canonical_job_id = j.id;
+
+COMMENT ON COLUMN jobs.source_language IS 'ISO 639-1 code of the language the original description is written in, detected by ai_job_processing';
+COMMENT ON COLUMN jobs.source_language_confidence IS 'Confidence of the detection, from 0 to 1';
+COMMENT ON COLUMN jobs.source_language_method IS 'text = function words of the description, llm = asked the model';
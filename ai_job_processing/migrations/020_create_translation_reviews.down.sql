@@ -0,0 +1,3 @@
+-- Rollback: Drop glossary and translation review tables
+DROP TABLE IF EXISTS translation_reviews;
+DROP TABLE IF EXISTS glossary_terms;
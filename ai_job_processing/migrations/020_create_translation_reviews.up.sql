@@ -0,0 +1,39 @@
+-- Migration: Create glossary_terms and translation_reviews tables
+-- Glossary enforced in translations by ai_job_processing, and translations flagged by its quality checks
+
+CREATE TABLE IF NOT EXISTS glossary_terms (
+    id BIGSERIAL PRIMARY KEY,
+    tenant TEXT NOT NULL DEFAULT '',
+    term TEXT NOT NULL CHECK (term <> ''),
+    target_language CHAR(2),
+    translation TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((target_language IS NULL) = (translation IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_glossary_terms_unique
+    ON glossary_terms(tenant, LOWER(term), COALESCE(target_language, ''));
+
+CREATE TABLE IF NOT EXISTS translation_reviews (
+    id BIGSERIAL PRIMARY KEY,
+    job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    language CHAR(2) NOT NULL,
+    problems JSONB NOT NULL DEFAULT '[]',
+    kept BOOLEAN NOT NULL,
+    model TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+
+-- At most one open review per job and language
+CREATE UNIQUE INDEX IF NOT EXISTS idx_translation_reviews_open
+    ON translation_reviews(job_id, language) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_translation_reviews_created ON translation_reviews(created_at DESC);
+
+COMMENT ON TABLE glossary_terms IS 'Terms enforced in translations, e.g. Lehre -> apprenticeship';
+COMMENT ON COLUMN glossary_terms.tenant IS 'Tenant the term applies to (GLOSSARY_TENANT); empty = all tenants';
+COMMENT ON COLUMN glossary_terms.translation IS 'Translation into target_language; NULL = never translate the term';
+COMMENT ON TABLE translation_reviews IS 'Translations that failed their quality checks, for a human to check';
+COMMENT ON COLUMN translation_reviews.problems IS 'JSON list of the problems found';
+COMMENT ON COLUMN translation_reviews.kept IS 'TRUE = saved despite the problems, FALSE = dropped';
+COMMENT ON COLUMN translation_reviews.resolved_at IS 'When the review was resolved, by a human or a later clean translation; NULL = open';
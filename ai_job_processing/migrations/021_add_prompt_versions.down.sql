@@ -0,0 +1,7 @@
+-- Rollback: Drop prompt version columns
+DROP INDEX IF EXISTS idx_job_descriptions_translation_prompt;
+
+ALTER TABLE job_descriptions
+DROP COLUMN IF EXISTS normalization_prompt_version,
+DROP COLUMN IF EXISTS translation_prompt,
+DROP COLUMN IF EXISTS translation_prompt_version;
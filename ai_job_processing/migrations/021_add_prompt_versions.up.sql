@@ -0,0 +1,15 @@
+-- Migration: Record the prompt versions behind normalized and translated job descriptions
+-- Written by ai_job_processing; see internal/gemini/prompts for the templates
+
+ALTER TABLE job_descriptions
+ADD COLUMN IF NOT EXISTS normalization_prompt_version INTEGER,
+ADD COLUMN IF NOT EXISTS translation_prompt TEXT CHECK (translation_prompt IN ('translation', 'raw_translation')),
+ADD COLUMN IF NOT EXISTS translation_prompt_version INTEGER;
+
+-- Index for finding descriptions made with an old prompt version
+CREATE INDEX IF NOT EXISTS idx_job_descriptions_translation_prompt
+    ON job_descriptions(translation_prompt, translation_prompt_version) WHERE translation_prompt IS NOT NULL;
+
+COMMENT ON COLUMN job_descriptions.normalization_prompt_version IS 'Version of the normalization prompt behind tasks, requirements and offer; NULL = unknown';
+COMMENT ON COLUMN job_descriptions.translation_prompt IS 'Prompt that translated the description: translation (normalized) or raw_translation; NULL = original text';
+COMMENT ON COLUMN job_descriptions.translation_prompt_version IS 'Version of translation_prompt';
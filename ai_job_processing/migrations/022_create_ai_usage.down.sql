@@ -0,0 +1,2 @@
+-- Rollback: Drop ai_usage table
+DROP TABLE IF EXISTS ai_usage;
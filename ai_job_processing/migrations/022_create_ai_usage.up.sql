@@ -0,0 +1,34 @@
+-- Migration: Create ai_usage table
+-- One row per model call of ai_job_processing, auth_service, autoapply_service, cv_generator, job_search and matching_service
+
+CREATE TABLE IF NOT EXISTS ai_usage (
+    id BIGSERIAL PRIMARY KEY,
+    service TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    method TEXT NOT NULL CHECK (method IN ('generate_json', 'generate_text', 'embed')),
+    operation TEXT NOT NULL DEFAULT '',
+    user_id TEXT,
+    job_id TEXT,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    estimated BOOLEAN NOT NULL DEFAULT FALSE,
+    cost_usd NUMERIC(12, 6) NOT NULL DEFAULT 0,
+    latency_ms INTEGER NOT NULL,
+    success BOOLEAN NOT NULL,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Daily budget checks sum the tokens of a service or a user since midnight
+CREATE INDEX IF NOT EXISTS idx_ai_usage_service_created ON ai_usage(service, created_at);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_user_created ON ai_usage(user_id, created_at) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ai_usage_job ON ai_usage(job_id) WHERE job_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ai_usage_created ON ai_usage(created_at);
+
+COMMENT ON TABLE ai_usage IS 'Token usage, cost and latency of every LLM call, for daily budgets and usage reports';
+COMMENT ON COLUMN ai_usage.operation IS 'Prompt name, e.g. cv_parse or normalization';
+COMMENT ON COLUMN ai_usage.user_id IS 'User the call was made for, if any';
+COMMENT ON COLUMN ai_usage.job_id IS 'Job the call was made for, if any; not a foreign key, usage outlives deleted jobs';
+COMMENT ON COLUMN ai_usage.estimated IS 'The provider did not report token counts; they were estimated from the text length';
+COMMENT ON COLUMN ai_usage.cost_usd IS 'Cost at the calling service''s LLM_PRICE_* settings when the call was made';
@@ -2,7 +2,9 @@ package analytics
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -185,14 +187,15 @@ func (s *Service) GetMarketStats(ctx context.Context) (*models.MarketStats, erro
 		})
 	}
 
-	// Top skills (extracted from job descriptions - simplified)
-	stats.TopSkills = []models.SkillStat{
-		{Skill: "Python", Count: 234, Trend: "up"},
-		{Skill: "Java", Count: 198, Trend: "stable"},
-		{Skill: "JavaScript", Count: 176, Trend: "up"},
-		{Skill: "React", Count: 145, Trend: "up"},
-		{Skill: "SQL", Count: 132, Trend: "stable"},
+	// Top skills (extracted from job descriptions by ai_job_processing)
+	topSkills, err := s.GetSkillsTrend(ctx)
+	if err != nil {
+		slog.Warn("Failed to get top skills", "error", err)
+	}
+	if len(topSkills) > 5 {
+		topSkills = topSkills[:5]
 	}
+	stats.TopSkills = topSkills
 
 	// Workload trends
 	s.db.GetContext(ctx, &stats.WorkloadTrends.FullTime, `
@@ -277,15 +280,46 @@ func (s *Service) GetProfileInsights(ctx context.Context, userID uuid.UUID, appS
 	return insights
 }
 
-// GetSkillsTrend returns skill demand trends.
+// GetSkillsTrend returns the most demanded skills of the last 30 days with their growth
+// against the 30 days before. Skills come from the job_skills table that ai_job_processing
+// fills when it processes a job.
 func (s *Service) GetSkillsTrend(ctx context.Context) ([]models.SkillStat, error) {
-	// This would analyze job descriptions for skill mentions
-	// Simplified implementation
-	return []models.SkillStat{
-		{Skill: "Python", Count: 234, Trend: "up", Growth: 15.5},
-		{Skill: "Kubernetes", Count: 98, Trend: "up", Growth: 28.2},
-		{Skill: "React", Count: 176, Trend: "up", Growth: 12.3},
-		{Skill: "Java", Count: 198, Trend: "stable", Growth: 2.1},
-		{Skill: "Machine Learning", Count: 87, Trend: "up", Growth: 32.5},
-	}, nil
+	var rows []struct {
+		Skill    string `db:"skill"`
+		Current  int    `db:"current"`
+		Previous int    `db:"previous"`
+	}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT js.skill,
+		       COUNT(*) FILTER (WHERE j.created_time >= NOW() - INTERVAL '30 days') AS current,
+		       COUNT(*) FILTER (WHERE j.created_time < NOW() - INTERVAL '30 days') AS previous
+		FROM job_skills js
+		JOIN jobs j ON j.id = js.job_id
+		WHERE j.created_time >= NOW() - INTERVAL '60 days'
+		GROUP BY js.skill
+		HAVING COUNT(*) FILTER (WHERE j.created_time >= NOW() - INTERVAL '30 days') > 0
+		ORDER BY current DESC, js.skill
+		LIMIT 20`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get skills trend: %w", err)
+	}
+
+	skills := make([]models.SkillStat, len(rows))
+	for i, r := range rows {
+		skills[i] = models.SkillStat{Skill: r.Skill, Count: r.Current, Trend: "up"}
+		if r.Previous > 0 {
+			skills[i].Growth = math.Round(float64(r.Current-r.Previous)/float64(r.Previous)*1000) / 10
+			switch {
+			case skills[i].Growth > 5:
+				skills[i].Trend = "up"
+			case skills[i].Growth < -5:
+				skills[i].Trend = "down"
+			default:
+				skills[i].Trend = "stable"
+			}
+		}
+	}
+
+	return skills, nil
 }
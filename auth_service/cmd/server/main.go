@@ -20,6 +20,9 @@ import (
 	"auth_service/internal/gemini"
 	"auth_service/internal/logger"
 	"auth_service/internal/store"
+	"llmkit/llm"
+	"llmkit/llmcache"
+	"llmkit/llmusage"
 )
 
 var (
@@ -84,6 +87,8 @@ Environment Variables:
   LINKEDIN_CLIENT_ID    LinkedIn OAuth client ID
   LINKEDIN_CLIENT_SECRET LinkedIn OAuth client secret
   GEMINI_API_KEY        Gemini API key for CV parsing
+  LLM_PROVIDER          gemini, openai or fake (default: gemini)
+  LLM_CACHE_TTL_HOURS   Hours parsed CVs stay cached (default: 720, 0 = no cache)
   PORT                  Server port (default: 8082)
   FRONTEND_URL          Frontend URL for CORS (default: http://localhost:3000)`)
 }
@@ -111,6 +116,14 @@ func runServer(cfg *config.Config) {
 	defer dbConn.Close()
 	slog.Info("Database connected")
 
+	// The daily token budgets are kept in ai_usage, created by ai_job_processing's migrations
+	if cfg.Usage.HasBudget() {
+		if err := llmusage.CheckTable(ctx, dbConn); err != nil {
+			slog.Error("Failed to enable the AI token budgets", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// Initialize store
 	storeInstance := store.NewStore(dbConn)
 
@@ -134,19 +147,26 @@ func runServer(cfg *config.Config) {
 		slog.Info("LinkedIn OAuth enabled")
 	}
 
-	// Initialize Gemini client
+	// Initialize LLM client for CV parsing; calls are recorded in ai_usage and checked
+	// against the daily token budgets
 	var geminiClient *gemini.Client
-	if cfg.IsGeminiEnabled() {
-		geminiClient, err = gemini.NewClient(ctx, gemini.ClientConfig{
-			APIKey:      cfg.GeminiAPIKey,
-			Model:       cfg.GeminiModel,
-			Temperature: cfg.GeminiTemperature,
-		})
+	if cfg.LLM.Enabled() {
+		provider, err := llm.New(ctx, cfg.LLM)
 		if err != nil {
-			slog.Warn("Failed to initialize Gemini client", "error", err)
+			slog.Warn("Failed to initialize LLM provider", "error", err)
 		} else {
+			provider = llm.Metered(provider, "auth_service", llmusage.New(dbConn, cfg.Usage))
+			var cache llm.Cache
+			if cfg.LLMCacheTTLHours > 0 {
+				c := llmcache.New(dbConn, time.Duration(cfg.LLMCacheTTLHours)*time.Hour)
+				if _, err := c.Invalidate(ctx, gemini.Prompts()); err != nil {
+					slog.Warn("Failed to invalidate outdated cached answers", "error", err)
+				}
+				cache = c
+			}
+			geminiClient = gemini.NewClient(provider, cache)
 			defer geminiClient.Close()
-			slog.Info("Gemini CV parsing enabled")
+			slog.Info("AI CV parsing enabled", "provider", provider.Name(), "model", provider.Model())
 		}
 	}
 
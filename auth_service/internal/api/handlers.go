@@ -3,6 +3,7 @@ package api
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"auth_service/internal/gemini"
 	"auth_service/internal/models"
 	"auth_service/internal/store"
+	"llmkit/llm"
 )
 
 // Handler holds API handler dependencies.
@@ -695,6 +697,13 @@ func (h *Handler) ImportCV(c *gin.Context) {
 
 	// Parse CV using Gemini
 	parsed, err := h.geminiClient.ParseCV(c.Request.Context(), req.FileContent, req.FileName)
+	if errors.Is(err, llm.ErrBudgetExceeded) {
+		c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+			Error: "Daily AI usage limit reached, try again tomorrow",
+			Code:  "BUDGET_EXCEEDED",
+		})
+		return
+	}
 	if err != nil {
 		slog.Error("CV parsing failed", "error", err)
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
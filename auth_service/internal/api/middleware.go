@@ -11,6 +11,7 @@ import (
 
 	"auth_service/internal/auth"
 	"auth_service/internal/models"
+	"llmkit/llm"
 )
 
 // AuthMiddleware validates JWT tokens.
@@ -59,6 +60,7 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 
 		c.Set("user_id", userID)
 		c.Set("email", claims.Email)
+		c.Request = c.Request.WithContext(llm.WithUser(c.Request.Context(), userID.String()))
 		c.Next()
 	}
 }
@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"auth_service/internal/auth"
 	"auth_service/internal/config"
@@ -30,6 +31,9 @@ func SetupRouter(
 	// Health check
 	r.GET("/health", handler.HealthCheck)
 
+	// Prometheus metrics
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	// API v1
 	v1 := r.Group("/api/v1")
 	{
@@ -4,6 +4,9 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	"llmkit/llm"
+	"llmkit/llmusage"
 )
 
 // Config holds all application configuration.
@@ -30,10 +33,13 @@ type Config struct {
 	LinkedInClientSecret string
 	LinkedInRedirectURL  string
 
-	// Gemini AI (for CV parsing)
-	GeminiAPIKey      string
-	GeminiModel       string
-	GeminiTemperature float32
+	// LLM provider (gemini, openai or fake); GEMINI_* configure the gemini provider
+	LLM              llm.Config
+	LLMCacheTTLHours int // Hours validated answers stay in llm_cache (0 = no caching)
+
+	// AI usage accounting in ai_usage: daily token budgets (0 = unlimited) and prices in
+	// USD per million tokens
+	Usage llmusage.Config
 
 	// Frontend
 	FrontendURL string
@@ -58,12 +64,30 @@ func Load() *Config {
 		LinkedInClientID:     GetEnv("LINKEDIN_CLIENT_ID", ""),
 		LinkedInClientSecret: GetEnv("LINKEDIN_CLIENT_SECRET", ""),
 		LinkedInRedirectURL:  GetEnv("LINKEDIN_REDIRECT_URL", "http://localhost:8082/api/v1/auth/linkedin/callback"),
-		GeminiAPIKey:         GetEnv("GEMINI_API_KEY", ""),
-		GeminiModel:          GetEnv("GEMINI_MODEL", "gemini-2.0-flash"),
-		GeminiTemperature:    GetEnvFloat32("GEMINI_TEMPERATURE", 0.3),
-		FrontendURL:          GetEnv("FRONTEND_URL", "http://localhost:3000"),
-		LogLevel:             GetEnv("LOG_LEVEL", "INFO"),
-		LogFormat:            GetEnv("LOG_FORMAT", "json"),
+		LLM: llm.Config{
+			Provider:       GetEnv("LLM_PROVIDER", llm.ProviderGemini),
+			APIKey:         GetEnv("LLM_API_KEY", ""),
+			BaseURL:        GetEnv("LLM_BASE_URL", ""),
+			Model:          GetEnv("LLM_MODEL", ""),
+			EmbeddingModel: GetEnv("LLM_EMBEDDING_MODEL", ""),
+			Temperature:    GetEnvFloat32("GEMINI_TEMPERATURE", 0.3),
+		}.WithGeminiDefaults(
+			GetEnv("GEMINI_API_KEY", ""),
+			GetEnv("GEMINI_MODEL", "gemini-2.0-flash"),
+			"",
+		),
+		LLMCacheTTLHours: GetEnvInt("LLM_CACHE_TTL_HOURS", 720),
+
+		Usage: llmusage.Config{
+			UserDailyTokens:    GetEnvInt("LLM_USER_DAILY_TOKENS", 0),
+			ServiceDailyTokens: GetEnvInt("LLM_SERVICE_DAILY_TOKENS", 0),
+			PromptPrice:        GetEnvFloat64("LLM_PRICE_PROMPT", 0),
+			CompletionPrice:    GetEnvFloat64("LLM_PRICE_COMPLETION", 0),
+		},
+
+		FrontendURL: GetEnv("FRONTEND_URL", "http://localhost:3000"),
+		LogLevel:    GetEnv("LOG_LEVEL", "INFO"),
+		LogFormat:   GetEnv("LOG_FORMAT", "json"),
 	}
 }
 
@@ -77,11 +101,6 @@ func (c *Config) IsLinkedInOAuthEnabled() bool {
 	return c.LinkedInClientID != "" && c.LinkedInClientSecret != ""
 }
 
-// IsGeminiEnabled returns true if Gemini is configured.
-func (c *Config) IsGeminiEnabled() bool {
-	return c.GeminiAPIKey != ""
-}
-
 // GetEnv returns the value of an environment variable or a default value.
 func GetEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -110,6 +129,16 @@ func GetEnvFloat32(key string, defaultValue float32) float32 {
 	return defaultValue
 }
 
+// GetEnvFloat64 returns the float64 value of an environment variable or a default value.
+func GetEnvFloat64(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
 // GetEnvBool returns the boolean value of an environment variable or a default value.
 func GetEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -8,82 +8,70 @@ import (
 	"strings"
 	"time"
 
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
-
 	"auth_service/internal/models"
+	"llmkit/llm"
 )
 
-// ClientConfig holds Gemini client configuration.
-type ClientConfig struct {
-	APIKey      string
-	Model       string
-	Temperature float32
-}
-
-// Client wraps the Gemini API client.
+// Client runs the CV parsing prompt on an LLM provider (Gemini by default, see LLM_PROVIDER).
 type Client struct {
-	client *genai.Client
-	model  *genai.GenerativeModel
-	config ClientConfig
+	llm   llm.Provider
+	cache llm.Cache
 }
 
-// NewClient creates a new Gemini client.
-func NewClient(ctx context.Context, cfg ClientConfig) (*Client, error) {
-	if cfg.APIKey == "" {
-		return nil, fmt.Errorf("GEMINI_API_KEY is required")
-	}
-
-	if cfg.Model == "" {
-		cfg.Model = "gemini-2.0-flash"
-	}
+// cvParsePrompt identifies the CV parsing template. Bump its version whenever the template changes.
+var cvParsePrompt = llm.Prompt{Name: "cv_parse", Version: 1}
 
-	client, err := genai.NewClient(ctx, option.WithAPIKey(cfg.APIKey))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
-	}
-
-	model := client.GenerativeModel(cfg.Model)
-	model.SetTemperature(cfg.Temperature)
-	model.ResponseMIMEType = "application/json"
+// Prompts returns the current prompt templates, for invalidating cached answers of older versions.
+func Prompts() []llm.Prompt {
+	return []llm.Prompt{cvParsePrompt}
+}
 
-	return &Client{
-		client: client,
-		model:  model,
-		config: cfg,
-	}, nil
+// NewClient creates a new Client on top of provider. Parsed CVs are kept in cache
+// (nil = no caching), so uploading the same CV again costs no model call.
+func NewClient(provider llm.Provider, cache llm.Cache) *Client {
+	return &Client{llm: provider, cache: cache}
 }
 
-// Close closes the Gemini client.
+// Close closes the underlying LLM provider.
 func (c *Client) Close() error {
-	return c.client.Close()
+	return c.llm.Close()
 }
 
 // ParseCV extracts structured data from CV content.
 func (c *Client) ParseCV(ctx context.Context, content, fileName string) (*models.ParsedCV, error) {
 	start := time.Now()
+	ctx = llm.WithOperation(ctx, cvParsePrompt.Name)
 
 	prompt := buildCVParsePrompt(content, fileName)
 
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
-	if err != nil {
-		return nil, fmt.Errorf("Gemini API error: %w", err)
+	var key string
+	if c.cache != nil {
+		key = llm.CacheKey(c.llm, cvParsePrompt, prompt)
+		if text, ok := c.cache.Get(ctx, cvParsePrompt, key); ok {
+			var result models.ParsedCV
+			if err := json.Unmarshal([]byte(text), &result); err == nil {
+				return &result, nil
+			}
+		}
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("empty response from Gemini")
+	text, err := c.llm.GenerateJSON(ctx, prompt)
+	if err != nil {
+		return nil, err
 	}
 
-	text := extractText(resp.Candidates[0].Content.Parts)
-
 	var result models.ParsedCV
 	if err := json.Unmarshal([]byte(text), &result); err != nil {
 		text = extractJSONFromMarkdown(text)
 		if err := json.Unmarshal([]byte(text), &result); err != nil {
-			return nil, fmt.Errorf("failed to parse Gemini response: %w", err)
+			return nil, fmt.Errorf("failed to parse CV parsing response: %w", err)
 		}
 	}
 
+	if c.cache != nil {
+		c.cache.Put(ctx, cvParsePrompt, key, text)
+	}
+
 	slog.Debug("CV parsing completed",
 		"duration_ms", time.Since(start).Milliseconds(),
 		"file_name", fileName,
@@ -181,17 +169,6 @@ Return as JSON:
 }`, fileName, content)
 }
 
-// extractText extracts text from Gemini response parts.
-func extractText(parts []genai.Part) string {
-	var texts []string
-	for _, part := range parts {
-		if text, ok := part.(genai.Text); ok {
-			texts = append(texts, string(text))
-		}
-	}
-	return strings.Join(texts, "")
-}
-
 // extractJSONFromMarkdown extracts JSON from markdown code blocks.
 func extractJSONFromMarkdown(text string) string {
 	if start := strings.Index(text, "```json"); start != -1 {
@@ -23,6 +23,9 @@ import (
 	"autoapply_service/internal/logger"
 	"autoapply_service/internal/selenium"
 	"autoapply_service/internal/store"
+	"llmkit/llm"
+	"llmkit/llmcache"
+	"llmkit/llmusage"
 )
 
 var (
@@ -71,7 +74,9 @@ Commands:
 
 Environment Variables:
   DATABASE_URL          PostgreSQL connection string (required)
-  GEMINI_API_KEY        Gemini API key (required)
+  GEMINI_API_KEY        Gemini API key (required for the gemini provider)
+  LLM_PROVIDER          gemini, openai or fake (default: gemini)
+  LLM_CACHE_TTL_HOURS   Hours cover letters stay cached (default: 720, 0 = no cache)
   AUTH_SERVICE_URL      URL of auth_service (default: http://localhost:8082)
   CV_GENERATOR_URL      URL of cv_generator (default: http://localhost:8083)
   SMTP_HOST             SMTP server host
@@ -90,8 +95,8 @@ func runServer(cfg *config.Config) {
 		slog.Error("DATABASE_URL is required")
 		os.Exit(1)
 	}
-	if cfg.GeminiAPIKey == "" {
-		slog.Error("GEMINI_API_KEY is required")
+	if !cfg.LLM.Enabled() {
+		slog.Error("GEMINI_API_KEY is required (or set LLM_PROVIDER)")
 		os.Exit(1)
 	}
 
@@ -105,6 +110,14 @@ func runServer(cfg *config.Config) {
 	defer dbConn.Close()
 	slog.Info("Database connected")
 
+	// The daily token budgets are kept in ai_usage, created by ai_job_processing's migrations
+	if cfg.Usage.HasBudget() {
+		if err := llmusage.CheckTable(ctx, dbConn); err != nil {
+			slog.Error("Failed to enable the AI token budgets", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// Store
 	storeInstance := store.NewStore(dbConn)
 
@@ -130,18 +143,24 @@ func runServer(cfg *config.Config) {
 	}
 	emailSender := email.NewSender(smtpConfig)
 
-	// Gemini client
-	geminiClient, err := gemini.NewClient(ctx, gemini.ClientConfig{
-		APIKey:      cfg.GeminiAPIKey,
-		Model:       cfg.GeminiModel,
-		Temperature: cfg.GeminiTemperature,
-	})
+	// LLM client; calls are recorded in ai_usage and checked against the daily token budgets
+	provider, err := llm.New(ctx, cfg.LLM)
 	if err != nil {
-		slog.Error("Failed to initialize Gemini client", "error", err)
+		slog.Error("Failed to initialize LLM provider", "error", err)
 		os.Exit(1)
 	}
+	provider = llm.Metered(provider, "autoapply_service", llmusage.New(dbConn, cfg.Usage))
+	var cache llm.Cache
+	if cfg.LLMCacheTTLHours > 0 {
+		c := llmcache.New(dbConn, time.Duration(cfg.LLMCacheTTLHours)*time.Hour)
+		if _, err := c.Invalidate(ctx, gemini.Prompts()); err != nil {
+			slog.Warn("Failed to invalidate outdated cached answers", "error", err)
+		}
+		cache = c
+	}
+	geminiClient := gemini.NewClient(provider, cache)
 	defer geminiClient.Close()
-	slog.Info("Gemini client initialized")
+	slog.Info("LLM client initialized", "provider", provider.Name(), "model", provider.Model())
 
 	// Web automation
 	automation := selenium.NewAutomation(cfg.ChromePath, cfg.AutomationDelay)
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -17,6 +18,7 @@ import (
 	"autoapply_service/internal/models"
 	"autoapply_service/internal/selenium"
 	"autoapply_service/internal/store"
+	"llmkit/llm"
 )
 
 // Handler holds API handler dependencies.
@@ -137,9 +139,10 @@ func (h *Handler) ApplyViaEmail(c *gin.Context) {
 	)
 	if err != nil {
 		h.store.UpdateApplicationStatus(c.Request.Context(), app.ID, models.StatusFailed, "Failed to generate cover letter")
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		status, code := llmErrorStatus(err)
+		c.JSON(status, models.ErrorResponse{
 			Error:   "Failed to generate cover letter",
-			Code:    "GEMINI_ERROR",
+			Code:    code,
 			Details: err.Error(),
 		})
 		return
@@ -272,9 +275,10 @@ func (h *Handler) ApplyViaWeb(c *gin.Context) {
 	)
 	if err != nil {
 		h.store.UpdateApplicationStatus(c.Request.Context(), app.ID, models.StatusFailed, "Failed to generate form responses")
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		status, code := llmErrorStatus(err)
+		c.JSON(status, models.ErrorResponse{
 			Error:   "Failed to generate form responses",
-			Code:    "GEMINI_ERROR",
+			Code:    code,
 			Details: err.Error(),
 		})
 		return
@@ -405,9 +409,10 @@ func (h *Handler) GenerateCoverLetter(c *gin.Context) {
 		req.CustomMessage, req.Language,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		status, code := llmErrorStatus(err)
+		c.JSON(status, models.ErrorResponse{
 			Error:   "Failed to generate cover letter",
-			Code:    "GEMINI_ERROR",
+			Code:    code,
 			Details: err.Error(),
 		})
 		return
@@ -415,3 +420,12 @@ func (h *Handler) GenerateCoverLetter(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+// llmErrorStatus maps an LLM error to its HTTP status and error code: 429 once a daily AI
+// token budget is used up, 500 otherwise.
+func llmErrorStatus(err error) (int, string) {
+	if errors.Is(err, llm.ErrBudgetExceeded) {
+		return http.StatusTooManyRequests, "BUDGET_EXCEEDED"
+	}
+	return http.StatusInternalServerError, "GEMINI_ERROR"
+}
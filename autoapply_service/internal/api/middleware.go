@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 
 	"autoapply_service/internal/models"
+	"llmkit/llm"
 )
 
 // AuthMiddleware validates JWT tokens.
@@ -51,6 +52,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		if userIDStr, ok := claims["user_id"].(string); ok {
 			if userID, err := uuid.Parse(userIDStr); err == nil {
 				c.Set("user_id", userID)
+				c.Request = c.Request.WithContext(llm.WithUser(c.Request.Context(), userID.String()))
 			}
 		}
 
@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"autoapply_service/internal/auth"
 	"autoapply_service/internal/config"
@@ -34,6 +35,9 @@ func SetupRouter(
 	// Health check
 	r.GET("/health", handler.HealthCheck)
 
+	// Prometheus metrics
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	// API v1
 	v1 := r.Group("/api/v1")
 	{
@@ -4,6 +4,9 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"llmkit/llm"
+	"llmkit/llmusage"
 )
 
 // Config holds all application configuration.
@@ -21,10 +24,13 @@ type Config struct {
 	// CV Generator Service
 	CVGeneratorURL string
 
-	// Gemini AI
-	GeminiAPIKey      string
-	GeminiModel       string
-	GeminiTemperature float32
+	// LLM provider (gemini, openai or fake); GEMINI_* configure the gemini provider
+	LLM              llm.Config
+	LLMCacheTTLHours int // Hours validated answers stay in llm_cache (0 = no caching)
+
+	// AI usage accounting in ai_usage: daily token budgets (0 = unlimited) and prices in
+	// USD per million tokens
+	Usage llmusage.Config
 
 	// Email - SMTP (platform fallback)
 	SMTPHost     string
@@ -53,14 +59,24 @@ type Config struct {
 // Load loads configuration from environment variables.
 func Load() *Config {
 	return &Config{
-		Port:               GetEnv("PORT", "8084"),
-		Host:               GetEnv("HOST", "0.0.0.0"),
-		DatabaseURL:        GetEnv("DATABASE_URL", ""),
-		AuthServiceURL:     GetEnv("AUTH_SERVICE_URL", "http://localhost:8082"),
-		CVGeneratorURL:     GetEnv("CV_GENERATOR_URL", "http://localhost:8083"),
-		GeminiAPIKey:       GetEnv("GEMINI_API_KEY", ""),
-		GeminiModel:        GetEnv("GEMINI_MODEL", "gemini-2.0-flash"),
-		GeminiTemperature:  GetEnvFloat32("GEMINI_TEMPERATURE", 0.7),
+		Port:           GetEnv("PORT", "8084"),
+		Host:           GetEnv("HOST", "0.0.0.0"),
+		DatabaseURL:    GetEnv("DATABASE_URL", ""),
+		AuthServiceURL: GetEnv("AUTH_SERVICE_URL", "http://localhost:8082"),
+		CVGeneratorURL: GetEnv("CV_GENERATOR_URL", "http://localhost:8083"),
+		LLM: llm.Config{
+			Provider:       GetEnv("LLM_PROVIDER", llm.ProviderGemini),
+			APIKey:         GetEnv("LLM_API_KEY", ""),
+			BaseURL:        GetEnv("LLM_BASE_URL", ""),
+			Model:          GetEnv("LLM_MODEL", ""),
+			EmbeddingModel: GetEnv("LLM_EMBEDDING_MODEL", ""),
+			Temperature:    GetEnvFloat32("GEMINI_TEMPERATURE", 0.7),
+		}.WithGeminiDefaults(
+			GetEnv("GEMINI_API_KEY", ""),
+			GetEnv("GEMINI_MODEL", "gemini-2.0-flash"),
+			"",
+		),
+		LLMCacheTTLHours:   GetEnvInt("LLM_CACHE_TTL_HOURS", 720),
 		SMTPHost:           GetEnv("SMTP_HOST", ""),
 		SMTPPort:           GetEnvInt("SMTP_PORT", 587),
 		SMTPUsername:       GetEnv("SMTP_USERNAME", ""),
@@ -74,6 +90,13 @@ func Load() *Config {
 		RateLimitPerHour:   GetEnvInt("RATE_LIMIT_PER_HOUR", 20),
 		LogLevel:           GetEnv("LOG_LEVEL", "INFO"),
 		LogFormat:          GetEnv("LOG_FORMAT", "json"),
+
+		Usage: llmusage.Config{
+			UserDailyTokens:    GetEnvInt("LLM_USER_DAILY_TOKENS", 0),
+			ServiceDailyTokens: GetEnvInt("LLM_SERVICE_DAILY_TOKENS", 0),
+			PromptPrice:        GetEnvFloat64("LLM_PRICE_PROMPT", 0),
+			CompletionPrice:    GetEnvFloat64("LLM_PRICE_COMPLETION", 0),
+		},
 	}
 }
 
@@ -82,11 +105,6 @@ func (c *Config) IsSMTPEnabled() bool {
 	return c.SMTPHost != "" && c.SMTPUsername != ""
 }
 
-// IsGeminiEnabled returns true if Gemini is configured.
-func (c *Config) IsGeminiEnabled() bool {
-	return c.GeminiAPIKey != ""
-}
-
 // GetEnv returns the value of an environment variable or a default.
 func GetEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -114,3 +132,13 @@ func GetEnvFloat32(key string, defaultValue float32) float32 {
 	}
 	return defaultValue
 }
+
+// GetEnvFloat64 returns the float64 value of an environment variable or a default value.
+func GetEnvFloat64(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
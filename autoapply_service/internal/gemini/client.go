@@ -8,59 +8,41 @@ import (
 	"strings"
 	"time"
 
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
-
 	"autoapply_service/internal/models"
+	"llmkit/llm"
 )
 
-// ClientConfig holds Gemini client configuration.
-type ClientConfig struct {
-	APIKey      string
-	Model       string
-	Temperature float32
-}
-
-// Client wraps the Gemini API client.
+// Client runs the cover letter and form prompts on an LLM provider (Gemini by default,
+// see LLM_PROVIDER).
 type Client struct {
-	client *genai.Client
-	model  *genai.GenerativeModel
-	config ClientConfig
+	llm   llm.Provider
+	cache llm.Cache
 }
 
-// NewClient creates a new Gemini client.
-func NewClient(ctx context.Context, cfg ClientConfig) (*Client, error) {
-	if cfg.APIKey == "" {
-		return nil, fmt.Errorf("GEMINI_API_KEY is required")
-	}
-
-	if cfg.Model == "" {
-		cfg.Model = "gemini-2.0-flash"
-	}
-
-	client, err := genai.NewClient(ctx, option.WithAPIKey(cfg.APIKey))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
-	}
+// coverLetterPrompt identifies the cover letter template. Bump its version whenever the
+// template changes.
+var coverLetterPrompt = llm.Prompt{Name: "cover_letter", Version: 1}
 
-	model := client.GenerativeModel(cfg.Model)
-	model.SetTemperature(cfg.Temperature)
+// Prompts returns the current prompt templates, for invalidating cached answers of older versions.
+func Prompts() []llm.Prompt {
+	return []llm.Prompt{coverLetterPrompt}
+}
 
-	return &Client{
-		client: client,
-		model:  model,
-		config: cfg,
-	}, nil
+// NewClient creates a new Client on top of provider. Cover letters are kept in cache
+// (nil = no caching), so requesting the same letter again costs no model call.
+func NewClient(provider llm.Provider, cache llm.Cache) *Client {
+	return &Client{llm: provider, cache: cache}
 }
 
-// Close closes the Gemini client.
+// Close closes the underlying LLM provider.
 func (c *Client) Close() error {
-	return c.client.Close()
+	return c.llm.Close()
 }
 
 // GenerateCoverLetter generates a personalized cover letter.
 func (c *Client) GenerateCoverLetter(ctx context.Context, resume *models.ResumeData, jobTitle, companyName, jobDescription, customMessage, language string) (*models.CoverLetterResponse, error) {
 	start := time.Now()
+	ctx = llm.WithOperation(ctx, coverLetterPrompt.Name)
 
 	if language == "" {
 		language = "English"
@@ -68,17 +50,21 @@ func (c *Client) GenerateCoverLetter(ctx context.Context, resume *models.ResumeD
 
 	prompt := buildCoverLetterPrompt(resume, jobTitle, companyName, jobDescription, customMessage, language)
 
-	c.model.ResponseMIMEType = "application/json"
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
-	if err != nil {
-		return nil, fmt.Errorf("Gemini API error: %w", err)
+	var key string
+	if c.cache != nil {
+		key = llm.CacheKey(c.llm, coverLetterPrompt, prompt)
+		if text, ok := c.cache.Get(ctx, coverLetterPrompt, key); ok {
+			var result models.CoverLetterResponse
+			if err := json.Unmarshal([]byte(text), &result); err == nil {
+				return &result, nil
+			}
+		}
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("empty response from Gemini")
+	text, err := c.llm.GenerateJSON(ctx, prompt)
+	if err != nil {
+		return nil, err
 	}
-
-	text := extractText(resp.Candidates[0].Content.Parts)
 	text = cleanJSON(text)
 
 	var result models.CoverLetterResponse
@@ -86,6 +72,10 @@ func (c *Client) GenerateCoverLetter(ctx context.Context, resume *models.ResumeD
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	if c.cache != nil {
+		c.cache.Put(ctx, coverLetterPrompt, key, text)
+	}
+
 	slog.Debug("Cover letter generated",
 		"duration_ms", time.Since(start).Milliseconds(),
 		"job_title", jobTitle,
@@ -97,20 +87,14 @@ func (c *Client) GenerateCoverLetter(ctx context.Context, resume *models.ResumeD
 // GenerateFormResponses generates responses for form fields.
 func (c *Client) GenerateFormResponses(ctx context.Context, resume *models.ResumeData, jobTitle, companyName, jobDescription string, fields []models.FormField) ([]models.FormResponse, error) {
 	start := time.Now()
+	ctx = llm.WithOperation(ctx, "form_responses")
 
 	prompt := buildFormResponsePrompt(resume, jobTitle, companyName, jobDescription, fields)
 
-	c.model.ResponseMIMEType = "application/json"
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
+	text, err := c.llm.GenerateJSON(ctx, prompt)
 	if err != nil {
-		return nil, fmt.Errorf("Gemini API error: %w", err)
-	}
-
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("empty response from Gemini")
+		return nil, err
 	}
-
-	text := extractText(resp.Candidates[0].Content.Parts)
 	text = cleanJSON(text)
 
 	var result []models.FormResponse
@@ -268,16 +252,6 @@ Return JSON array:
 	return sb.String()
 }
 
-func extractText(parts []genai.Part) string {
-	var texts []string
-	for _, part := range parts {
-		if text, ok := part.(genai.Text); ok {
-			texts = append(texts, string(text))
-		}
-	}
-	return strings.Join(texts, "")
-}
-
 func cleanJSON(text string) string {
 	// Remove markdown code blocks
 	if strings.HasPrefix(text, "```json") {
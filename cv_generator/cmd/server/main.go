@@ -15,9 +15,12 @@ import (
 	"cv_generator/internal/api"
 	"cv_generator/internal/auth"
 	"cv_generator/internal/config"
+	"cv_generator/internal/db"
 	"cv_generator/internal/generator"
 	"cv_generator/internal/logger"
 	"cv_generator/internal/pdf"
+	"llmkit/llm"
+	"llmkit/llmusage"
 )
 
 var (
@@ -69,8 +72,10 @@ Commands:
   help      Show this help message
 
 Environment Variables:
-  GEMINI_API_KEY        Gemini API key (required)
+  GEMINI_API_KEY        Gemini API key (required for the gemini provider)
+  LLM_PROVIDER          gemini, openai or fake (default: gemini)
   AUTH_SERVICE_URL      URL of auth_service (default: http://localhost:8082)
+  DATABASE_URL          PostgreSQL connection string for AI usage accounting (optional)
   PORT                  Server port (default: 8083)
   CHROME_PATH           Path to Chrome/Chromium (optional, auto-detected)`)
 }
@@ -79,8 +84,12 @@ func runServer(cfg *config.Config) {
 	ctx := context.Background()
 
 	// Validate required config
-	if cfg.GeminiAPIKey == "" {
-		slog.Error("GEMINI_API_KEY is required")
+	if !cfg.LLM.Enabled() {
+		slog.Error("GEMINI_API_KEY is required (or set LLM_PROVIDER)")
+		os.Exit(1)
+	}
+	if cfg.Usage.HasBudget() && cfg.DatabaseURL == "" {
+		slog.Error("DATABASE_URL is required for the AI token budgets")
 		os.Exit(1)
 	}
 
@@ -88,18 +97,39 @@ func runServer(cfg *config.Config) {
 	authClient := auth.NewClient(cfg.AuthServiceURL)
 	slog.Info("Auth service client initialized", "url", cfg.AuthServiceURL)
 
-	// Initialize Gemini client
-	geminiClient, err := generator.NewClient(ctx, generator.ClientConfig{
-		APIKey:      cfg.GeminiAPIKey,
-		Model:       cfg.GeminiModel,
-		Temperature: cfg.GeminiTemperature,
-	})
+	// Initialize LLM client
+	provider, err := llm.New(ctx, cfg.LLM)
 	if err != nil {
-		slog.Error("Failed to initialize Gemini client", "error", err)
+		slog.Error("Failed to initialize LLM provider", "error", err)
 		os.Exit(1)
 	}
+
+	// With a database, calls are recorded in ai_usage and checked against the daily token budgets
+	if cfg.DatabaseURL != "" {
+		dbConn, err := db.NewDB(ctx, cfg.DatabaseURL)
+		if err != nil && cfg.Usage.HasBudget() {
+			slog.Error("Failed to connect to database", "error", err)
+			os.Exit(1)
+		}
+		if err != nil {
+			slog.Warn("Failed to connect to database, AI usage is not recorded", "error", err)
+		} else {
+			defer dbConn.Close()
+			// The daily token budgets are kept in ai_usage, created by ai_job_processing's migrations
+			if cfg.Usage.HasBudget() {
+				if err := llmusage.CheckTable(ctx, dbConn); err != nil {
+					slog.Error("Failed to enable the AI token budgets", "error", err)
+					os.Exit(1)
+				}
+			}
+			provider = llm.Metered(provider, "cv_generator", llmusage.New(dbConn, cfg.Usage))
+			slog.Info("AI usage accounting enabled")
+		}
+	}
+
+	geminiClient := generator.NewClient(provider)
 	defer geminiClient.Close()
-	slog.Info("Gemini client initialized", "model", cfg.GeminiModel)
+	slog.Info("LLM client initialized", "provider", provider.Name(), "model", provider.Model())
 
 	// Initialize PDF converter
 	pdfConverter := pdf.NewConverter(cfg.ChromePath)
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"cv_generator/internal/generator"
 	"cv_generator/internal/models"
 	"cv_generator/internal/pdf"
+	"llmkit/llm"
 )
 
 // Handler holds API handler dependencies.
@@ -137,9 +139,10 @@ func (h *Handler) GenerateCV(c *gin.Context) {
 	html, err := h.geminiClient.GenerateCV(c.Request.Context(), resumeData, &req)
 	if err != nil {
 		slog.Error("Failed to generate CV HTML", "error", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		status, code := generationErrorStatus(err)
+		c.JSON(status, models.ErrorResponse{
 			Error:   "Failed to generate CV",
-			Code:    "GENERATION_ERROR",
+			Code:    code,
 			Details: err.Error(),
 		})
 		return
@@ -214,9 +217,10 @@ func (h *Handler) PreviewCV(c *gin.Context) {
 	// Generate HTML only (no PDF)
 	html, err := h.geminiClient.GenerateCV(c.Request.Context(), resumeData, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		status, code := generationErrorStatus(err)
+		c.JSON(status, models.ErrorResponse{
 			Error:   "Failed to generate CV",
-			Code:    "GENERATION_ERROR",
+			Code:    code,
 			Details: err.Error(),
 		})
 		return
@@ -258,3 +262,12 @@ func applyDefaults(req *models.GenerateCVRequest) {
 		}
 	}
 }
+
+// generationErrorStatus maps a CV generation error to its HTTP status and error code: 429
+// once a daily AI token budget is used up, 500 otherwise.
+func generationErrorStatus(err error) (int, string) {
+	if errors.Is(err, llm.ErrBudgetExceeded) {
+		return http.StatusTooManyRequests, "BUDGET_EXCEEDED"
+	}
+	return http.StatusInternalServerError, "GENERATION_ERROR"
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 
 	"cv_generator/internal/models"
+	"llmkit/llm"
 )
 
 // AuthMiddleware validates JWT tokens from the Authorization header.
@@ -50,6 +51,11 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Account model calls to the user
+		if userID, ok := claims["user_id"].(string); ok && userID != "" {
+			c.Request = c.Request.WithContext(llm.WithUser(c.Request.Context(), userID))
+		}
+
 		c.Set("access_token", token)
 		c.Next()
 	}
@@ -3,6 +3,9 @@ package config
 import (
 	"os"
 	"strconv"
+
+	"llmkit/llm"
+	"llmkit/llmusage"
 )
 
 // Config holds all application configuration.
@@ -14,10 +17,15 @@ type Config struct {
 	// Auth Service
 	AuthServiceURL string
 
-	// Gemini AI
-	GeminiAPIKey      string
-	GeminiModel       string
-	GeminiTemperature float32
+	// Database (optional, only for AI usage accounting in ai_usage)
+	DatabaseURL string
+
+	// LLM provider (gemini, openai or fake); GEMINI_* configure the gemini provider
+	LLM llm.Config
+
+	// AI usage accounting in ai_usage: daily token budgets (0 = unlimited) and prices in
+	// USD per million tokens
+	Usage llmusage.Config
 
 	// PDF Generation
 	ChromePath string // Path to Chrome/Chromium (optional)
@@ -30,21 +38,33 @@ type Config struct {
 // Load loads configuration from environment variables.
 func Load() *Config {
 	return &Config{
-		Port:              GetEnv("PORT", "8083"),
-		Host:              GetEnv("HOST", "0.0.0.0"),
-		AuthServiceURL:    GetEnv("AUTH_SERVICE_URL", "http://localhost:8082"),
-		GeminiAPIKey:      GetEnv("GEMINI_API_KEY", ""),
-		GeminiModel:       GetEnv("GEMINI_MODEL", "gemini-2.0-flash"),
-		GeminiTemperature: GetEnvFloat32("GEMINI_TEMPERATURE", 0.7),
-		ChromePath:        GetEnv("CHROME_PATH", ""),
-		LogLevel:          GetEnv("LOG_LEVEL", "INFO"),
-		LogFormat:         GetEnv("LOG_FORMAT", "json"),
-	}
-}
+		Port:           GetEnv("PORT", "8083"),
+		Host:           GetEnv("HOST", "0.0.0.0"),
+		AuthServiceURL: GetEnv("AUTH_SERVICE_URL", "http://localhost:8082"),
+		DatabaseURL:    GetEnv("DATABASE_URL", ""),
+		LLM: llm.Config{
+			Provider:       GetEnv("LLM_PROVIDER", llm.ProviderGemini),
+			APIKey:         GetEnv("LLM_API_KEY", ""),
+			BaseURL:        GetEnv("LLM_BASE_URL", ""),
+			Model:          GetEnv("LLM_MODEL", ""),
+			EmbeddingModel: GetEnv("LLM_EMBEDDING_MODEL", ""),
+			Temperature:    GetEnvFloat32("GEMINI_TEMPERATURE", 0.7),
+		}.WithGeminiDefaults(
+			GetEnv("GEMINI_API_KEY", ""),
+			GetEnv("GEMINI_MODEL", "gemini-2.0-flash"),
+			"",
+		),
+		ChromePath: GetEnv("CHROME_PATH", ""),
+		LogLevel:   GetEnv("LOG_LEVEL", "INFO"),
+		LogFormat:  GetEnv("LOG_FORMAT", "json"),
 
-// IsGeminiEnabled returns true if Gemini is configured.
-func (c *Config) IsGeminiEnabled() bool {
-	return c.GeminiAPIKey != ""
+		Usage: llmusage.Config{
+			UserDailyTokens:    GetEnvInt("LLM_USER_DAILY_TOKENS", 0),
+			ServiceDailyTokens: GetEnvInt("LLM_SERVICE_DAILY_TOKENS", 0),
+			PromptPrice:        GetEnvFloat64("LLM_PRICE_PROMPT", 0),
+			CompletionPrice:    GetEnvFloat64("LLM_PRICE_COMPLETION", 0),
+		},
+	}
 }
 
 // GetEnv returns the value of an environment variable or a default value.
@@ -55,6 +75,16 @@ func GetEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// GetEnvInt returns the integer value of an environment variable or a default value.
+func GetEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intVal, err := strconv.Atoi(value); err == nil {
+			return intVal
+		}
+	}
+	return defaultValue
+}
+
 // GetEnvFloat32 returns the float32 value of an environment variable.
 func GetEnvFloat32(key string, defaultValue float32) float32 {
 	if value := os.Getenv(key); value != "" {
@@ -64,3 +94,13 @@ func GetEnvFloat32(key string, defaultValue float32) float32 {
 	}
 	return defaultValue
 }
+
+// GetEnvFloat64 returns the float64 value of an environment variable or a default value.
+func GetEnvFloat64(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
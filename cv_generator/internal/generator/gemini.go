@@ -2,64 +2,33 @@ package generator
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"strings"
 	"time"
 
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
-
 	"cv_generator/internal/models"
+	"llmkit/llm"
 )
 
-// ClientConfig holds Gemini client configuration.
-type ClientConfig struct {
-	APIKey      string
-	Model       string
-	Temperature float32
-}
-
-// Client wraps the Gemini API client.
+// Client runs the CV generation prompt on an LLM provider (Gemini by default, see LLM_PROVIDER).
 type Client struct {
-	client *genai.Client
-	model  *genai.GenerativeModel
-	config ClientConfig
+	llm llm.Provider
 }
 
-// NewClient creates a new Gemini client.
-func NewClient(ctx context.Context, cfg ClientConfig) (*Client, error) {
-	if cfg.APIKey == "" {
-		return nil, fmt.Errorf("GEMINI_API_KEY is required")
-	}
-
-	if cfg.Model == "" {
-		cfg.Model = "gemini-2.0-flash"
-	}
-
-	client, err := genai.NewClient(ctx, option.WithAPIKey(cfg.APIKey))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
-	}
-
-	model := client.GenerativeModel(cfg.Model)
-	model.SetTemperature(cfg.Temperature)
-
-	return &Client{
-		client: client,
-		model:  model,
-		config: cfg,
-	}, nil
+// NewClient creates a new Client on top of provider.
+func NewClient(provider llm.Provider) *Client {
+	return &Client{llm: provider}
 }
 
-// Close closes the Gemini client.
+// Close closes the underlying LLM provider.
 func (c *Client) Close() error {
-	return c.client.Close()
+	return c.llm.Close()
 }
 
 // GenerateCV generates an HTML CV from resume data and options.
 func (c *Client) GenerateCV(ctx context.Context, data *models.ResumeData, opts *models.GenerateCVRequest) (string, error) {
 	start := time.Now()
+	ctx = llm.WithOperation(ctx, "cv_generate")
 
 	// Use defaults if not specified
 	if opts == nil {
@@ -69,17 +38,11 @@ func (c *Client) GenerateCV(ctx context.Context, data *models.ResumeData, opts *
 
 	prompt := BuildCVPrompt(data, opts)
 
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
+	html, err := c.llm.GenerateText(ctx, prompt)
 	if err != nil {
-		return "", fmt.Errorf("Gemini API error: %w", err)
+		return "", err
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("empty response from Gemini")
-	}
-
-	html := extractText(resp.Candidates[0].Content.Parts)
-
 	// Clean up the response - remove any markdown code blocks if present
 	html = cleanHTMLResponse(html)
 
@@ -92,17 +55,6 @@ func (c *Client) GenerateCV(ctx context.Context, data *models.ResumeData, opts *
 	return html, nil
 }
 
-// extractText extracts text from Gemini response parts.
-func extractText(parts []genai.Part) string {
-	var texts []string
-	for _, part := range parts {
-		if text, ok := part.(genai.Text); ok {
-			texts = append(texts, string(text))
-		}
-	}
-	return strings.Join(texts, "")
-}
-
 // cleanHTMLResponse removes markdown code blocks and extracts pure HTML.
 func cleanHTMLResponse(text string) string {
 	// Remove markdown code block markers
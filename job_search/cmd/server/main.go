@@ -18,6 +18,8 @@ import (
 	"job_search/internal/gemini"
 	"job_search/internal/logger"
 	"job_search/internal/search"
+	"llmkit/llm"
+	"llmkit/llmusage"
 )
 
 var (
@@ -63,6 +65,7 @@ Commands:
 Environment Variables:
   DATABASE_URL          PostgreSQL connection string (required)
   GEMINI_API_KEY        Gemini API key (required for AI search)
+  LLM_PROVIDER          gemini, openai or fake (default: gemini)
   AUTH_SERVICE_URL      URL of auth_service (default: http://localhost:8082)
   PORT                  Server port (default: 8085)
   DEFAULT_PAGE_SIZE     Default results per page (default: 20)
@@ -87,20 +90,26 @@ func runServer(cfg *config.Config) {
 	defer dbConn.Close()
 	slog.Info("Database connected")
 
-	// Gemini client (optional)
+	// The daily token budgets are kept in ai_usage, created by ai_job_processing's migrations
+	if cfg.Usage.HasBudget() {
+		if err := llmusage.CheckTable(ctx, dbConn); err != nil {
+			slog.Error("Failed to enable the AI token budgets", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// LLM client (optional); calls are recorded in ai_usage and checked against the daily
+	// token budgets
 	var geminiClient *gemini.Client
-	if cfg.GeminiAPIKey != "" {
-		geminiClient, err = gemini.NewClient(ctx, gemini.ClientConfig{
-			APIKey:         cfg.GeminiAPIKey,
-			Model:          cfg.GeminiModel,
-			EmbeddingModel: cfg.EmbeddingModel,
-			Temperature:    cfg.GeminiTemperature,
-		})
+	if cfg.LLM.Enabled() {
+		provider, err := llm.New(ctx, cfg.LLM)
 		if err != nil {
-			slog.Warn("Failed to initialize Gemini client, AI search disabled", "error", err)
+			slog.Warn("Failed to initialize LLM provider, AI search disabled", "error", err)
 		} else {
+			provider = llm.Metered(provider, "job_search", llmusage.New(dbConn, cfg.Usage))
+			geminiClient = gemini.NewClient(provider)
 			defer geminiClient.Close()
-			slog.Info("Gemini client initialized (AI search enabled)")
+			slog.Info("LLM client initialized (AI search enabled)", "provider", provider.Name(), "model", provider.Model())
 		}
 	} else {
 		slog.Info("GEMINI_API_KEY not set, AI search disabled")
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 
 	"job_search/internal/models"
+	"llmkit/llm"
 )
 
 // AuthMiddleware validates JWT tokens.
@@ -50,6 +51,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		if userIDStr, ok := claims["user_id"].(string); ok {
 			if userID, err := uuid.Parse(userIDStr); err == nil {
 				c.Set("user_id", userID)
+				c.Request = c.Request.WithContext(llm.WithUser(c.Request.Context(), userID.String()))
 			}
 		}
 
@@ -85,6 +87,7 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		if userIDStr, ok := claims["user_id"].(string); ok {
 			if userID, err := uuid.Parse(userIDStr); err == nil {
 				c.Set("user_id", userID)
+				c.Request = c.Request.WithContext(llm.WithUser(c.Request.Context(), userID.String()))
 			}
 		}
 
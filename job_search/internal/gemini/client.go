@@ -8,67 +8,30 @@ import (
 	"strings"
 	"time"
 
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
-
 	"job_search/internal/models"
+	"llmkit/llm"
 )
 
-// ClientConfig holds Gemini client configuration.
-type ClientConfig struct {
-	APIKey         string
-	Model          string
-	EmbeddingModel string
-	Temperature    float32
-}
-
-// Client wraps the Gemini API client.
+// Client runs the query parsing and embedding calls on an LLM provider (Gemini by default,
+// see LLM_PROVIDER).
 type Client struct {
-	client         *genai.Client
-	model          *genai.GenerativeModel
-	embeddingModel *genai.EmbeddingModel
-	config         ClientConfig
+	llm llm.Provider
 }
 
-// NewClient creates a new Gemini client.
-func NewClient(ctx context.Context, cfg ClientConfig) (*Client, error) {
-	if cfg.APIKey == "" {
-		return nil, fmt.Errorf("GEMINI_API_KEY is required")
-	}
-
-	if cfg.Model == "" {
-		cfg.Model = "gemini-2.0-flash"
-	}
-	if cfg.EmbeddingModel == "" {
-		cfg.EmbeddingModel = "text-embedding-004"
-	}
-
-	client, err := genai.NewClient(ctx, option.WithAPIKey(cfg.APIKey))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
-	}
-
-	model := client.GenerativeModel(cfg.Model)
-	model.SetTemperature(cfg.Temperature)
-
-	embeddingModel := client.EmbeddingModel(cfg.EmbeddingModel)
-
-	return &Client{
-		client:         client,
-		model:          model,
-		embeddingModel: embeddingModel,
-		config:         cfg,
-	}, nil
+// NewClient creates a new Client on top of provider.
+func NewClient(provider llm.Provider) *Client {
+	return &Client{llm: provider}
 }
 
-// Close closes the Gemini client.
+// Close closes the underlying LLM provider.
 func (c *Client) Close() error {
-	return c.client.Close()
+	return c.llm.Close()
 }
 
 // ParseNaturalLanguageQuery uses AI to understand a natural language search query.
 func (c *Client) ParseNaturalLanguageQuery(ctx context.Context, query string) (*models.AIQueryResult, error) {
 	start := time.Now()
+	ctx = llm.WithOperation(ctx, "query_parse")
 
 	prompt := fmt.Sprintf(`Analyze this job search query and extract structured information.
 
@@ -88,17 +51,10 @@ Intent can be: "search" (looking for jobs), "filter" (refining results), "recomm
 If no specific information is found for a field, return an empty array.
 Return ONLY valid JSON, no markdown.`, query)
 
-	c.model.ResponseMIMEType = "application/json"
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
+	text, err := c.llm.GenerateJSON(ctx, prompt)
 	if err != nil {
-		return nil, fmt.Errorf("Gemini API error: %w", err)
-	}
-
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("empty response from Gemini")
+		return nil, err
 	}
-
-	text := extractText(resp.Candidates[0].Content.Parts)
 	text = cleanJSON(text)
 
 	var result models.AIQueryResult
@@ -123,22 +79,19 @@ Return ONLY valid JSON, no markdown.`, query)
 // GenerateEmbedding generates a vector embedding for text.
 func (c *Client) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
 	start := time.Now()
+	ctx = llm.WithOperation(ctx, "embedding")
 
-	resp, err := c.embeddingModel.EmbedContent(ctx, genai.Text(text))
+	embedding, err := c.llm.Embed(ctx, text)
 	if err != nil {
 		return nil, fmt.Errorf("embedding error: %w", err)
 	}
 
-	if resp.Embedding == nil || len(resp.Embedding.Values) == 0 {
-		return nil, fmt.Errorf("empty embedding response")
-	}
-
 	slog.Debug("Embedding generated",
 		"duration_ms", time.Since(start).Milliseconds(),
-		"dimensions", len(resp.Embedding.Values),
+		"dimensions", len(embedding),
 	)
 
-	return resp.Embedding.Values, nil
+	return embedding, nil
 }
 
 // GenerateEmbeddingBatch generates embeddings for multiple texts.
@@ -156,16 +109,6 @@ func (c *Client) GenerateEmbeddingBatch(ctx context.Context, texts []string) ([]
 	return embeddings, nil
 }
 
-func extractText(parts []genai.Part) string {
-	var texts []string
-	for _, part := range parts {
-		if text, ok := part.(genai.Text); ok {
-			texts = append(texts, string(text))
-		}
-	}
-	return strings.Join(texts, "")
-}
-
 func cleanJSON(text string) string {
 	if strings.HasPrefix(text, "```json") {
 		text = strings.TrimPrefix(text, "```json")
@@ -24,7 +24,7 @@ type SearchFilters struct {
 	Company    *CompanyFilter    `json:"company,omitempty"`
 	Date       *DateFilter       `json:"date,omitempty"`
 	Language   string            `json:"language,omitempty"` // de, fr, it, en
-	Source     string            `json:"source,omitempty"`   // jobroom, platform
+	Source     string            `json:"source,omitempty"`   // jobroom, careerpage, platform
 	Status     string            `json:"status,omitempty"`   // active, inactive, expired
 }
 
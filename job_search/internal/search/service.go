@@ -481,7 +481,7 @@ func (s *Service) GetJob(ctx context.Context, id string) (*models.JobResult, err
 func (s *Service) GetFilterOptions(ctx context.Context) (*models.FilterOptions, error) {
 	options := &models.FilterOptions{
 		Languages: []string{"de", "fr", "it", "en"},
-		Sources:   []string{"jobroom", "platform"},
+		Sources:   []string{"jobroom", "careerpage", "platform"},
 	}
 
 	// Get distinct cantons
@@ -0,0 +1,43 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Prompt identifies a prompt template. Bump Version whenever the template or the way its
+// answer is interpreted changes, so that cached answers of the old template are not reused.
+type Prompt struct {
+	Name    string
+	Version int
+	TTL     time.Duration // How long answers stay cached (0 = the cache default)
+}
+
+// String returns the prompt as name@vN.
+func (p Prompt) String() string {
+	return p.Name + "@v" + strconv.Itoa(p.Version)
+}
+
+// Cache stores model answers by content address. Implementations treat their own failures
+// as misses, so a broken cache only costs model calls.
+type Cache interface {
+	// Get returns the cached answer for key.
+	Get(ctx context.Context, p Prompt, key string) (string, bool)
+
+	// Put stores an answer for key. Only answers that passed the caller's checks are stored.
+	Put(ctx context.Context, p Prompt, key, answer string)
+}
+
+// CacheKey returns the content address of an answer: a hash of the provider, model, prompt
+// template version and the full prompt text, which contains the input.
+func CacheKey(provider Provider, p Prompt, prompt string) string {
+	h := sha256.New()
+	for _, part := range []string{provider.Name(), provider.Model(), p.String(), prompt} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
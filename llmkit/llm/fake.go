@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"strings"
+	"sync"
+)
+
+// Fake is a deterministic Provider that needs no network or API key. Prompts get the response
+// of the first rule whose substring they contain, "{}" (JSON) or "fake response" (text)
+// otherwise. Embeddings are unit vectors derived from a hash of the text, so equal texts
+// get equal vectors.
+type Fake struct {
+	mu             sync.Mutex
+	rules          []fakeRule
+	calls          int
+	model          string
+	embeddingModel string
+	dims           int
+}
+
+type fakeRule struct {
+	substr   string
+	response string
+	err      error
+}
+
+// NewFake creates a fake provider.
+func NewFake(cfg Config) *Fake {
+	model := cfg.Model
+	if model == "" {
+		model = "fake"
+	}
+	embeddingModel := cfg.EmbeddingModel
+	if embeddingModel == "" {
+		embeddingModel = "fake-embedding"
+	}
+	dims := cfg.Dimensions
+	if dims <= 0 {
+		dims = 768
+	}
+	return &Fake{model: model, embeddingModel: embeddingModel, dims: dims}
+}
+
+// Respond makes prompts containing substr return response.
+func (f *Fake) Respond(substr, response string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, fakeRule{substr: substr, response: response})
+}
+
+// Fail makes prompts containing substr fail with err.
+func (f *Fake) Fail(substr string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, fakeRule{substr: substr, err: err})
+}
+
+// Calls returns the number of generate and embed calls so far.
+func (f *Fake) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// GenerateJSON implements Provider.
+func (f *Fake) GenerateJSON(ctx context.Context, prompt string) (string, error) {
+	return f.generate(ctx, prompt, "{}")
+}
+
+// GenerateText implements Provider.
+func (f *Fake) GenerateText(ctx context.Context, prompt string) (string, error) {
+	return f.generate(ctx, prompt, "fake response")
+}
+
+func (f *Fake) generate(ctx context.Context, prompt, fallback string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+
+	for _, rule := range f.rules {
+		if strings.Contains(prompt, rule.substr) {
+			return rule.response, rule.err
+		}
+	}
+	return fallback, nil
+}
+
+// Embed implements Provider.
+func (f *Fake) Embed(ctx context.Context, text string) ([]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+
+	vec := make([]float32, f.dims)
+	var norm float64
+	var block [sha256.Size]byte
+	for i := range vec {
+		// Each SHA-256 block of the text and a counter yields 8 components
+		if i%8 == 0 {
+			var counter [8]byte
+			binary.BigEndian.PutUint64(counter[:], uint64(i/8))
+			block = sha256.Sum256(append([]byte(text), counter[:]...))
+		}
+		v := float64(binary.BigEndian.Uint32(block[(i%8)*4:]))/math.MaxUint32*2 - 1
+		vec[i] = float32(v)
+		norm += v * v
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vec {
+			vec[i] *= scale
+		}
+	}
+	return vec, nil
+}
+
+// Ping implements Provider.
+func (f *Fake) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Name implements Provider.
+func (f *Fake) Name() string {
+	return ProviderFake
+}
+
+// Model implements Provider.
+func (f *Fake) Model() string {
+	return f.model
+}
+
+// EmbeddingModel implements Provider.
+func (f *Fake) EmbeddingModel() string {
+	return f.embeddingModel
+}
+
+// Close implements Provider.
+func (f *Fake) Close() error {
+	return nil
+}
@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// Gemini is the Provider for Google Gemini.
+type Gemini struct {
+	client    *genai.Client
+	textModel *genai.GenerativeModel
+	jsonModel *genai.GenerativeModel
+	embedding *genai.EmbeddingModel
+	config    Config
+}
+
+// NewGemini creates a Gemini provider.
+func NewGemini(ctx context.Context, cfg Config) (*Gemini, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("GEMINI_API_KEY is required")
+	}
+
+	if cfg.Model == "" {
+		cfg.Model = "gemini-2.0-flash"
+	}
+	if cfg.EmbeddingModel == "" {
+		cfg.EmbeddingModel = "text-embedding-004"
+	}
+
+	client, err := genai.NewClient(ctx, option.WithAPIKey(cfg.APIKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+
+	// Separate models for text and JSON, since the response MIME type is model state
+	textModel := client.GenerativeModel(cfg.Model)
+	textModel.SetTemperature(cfg.Temperature)
+
+	jsonModel := client.GenerativeModel(cfg.Model)
+	jsonModel.SetTemperature(cfg.Temperature)
+	jsonModel.ResponseMIMEType = "application/json"
+
+	return &Gemini{
+		client:    client,
+		textModel: textModel,
+		jsonModel: jsonModel,
+		embedding: client.EmbeddingModel(cfg.EmbeddingModel),
+		config:    cfg,
+	}, nil
+}
+
+// GenerateJSON implements Provider.
+func (g *Gemini) GenerateJSON(ctx context.Context, prompt string) (string, error) {
+	return g.generate(ctx, g.jsonModel, prompt)
+}
+
+// GenerateText implements Provider.
+func (g *Gemini) GenerateText(ctx context.Context, prompt string) (string, error) {
+	return g.generate(ctx, g.textModel, prompt)
+}
+
+func (g *Gemini) generate(ctx context.Context, model *genai.GenerativeModel, prompt string) (string, error) {
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return "", classifyGeminiError("Gemini API error", err)
+	}
+	if u := resp.UsageMetadata; u != nil {
+		reportUsage(ctx, int(u.PromptTokenCount), int(u.CandidatesTokenCount))
+	}
+
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return "", ErrEmptyResponse
+	}
+
+	var texts []string
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if text, ok := part.(genai.Text); ok {
+			texts = append(texts, string(text))
+		}
+	}
+	text := strings.Join(texts, "")
+	if strings.TrimSpace(text) == "" {
+		return "", ErrEmptyResponse
+	}
+	return text, nil
+}
+
+// Embed implements Provider.
+func (g *Gemini) Embed(ctx context.Context, text string) ([]float32, error) {
+	resp, err := g.embedding.EmbedContent(ctx, genai.Text(text))
+	if err != nil {
+		return nil, classifyGeminiError("Gemini embedding error", err)
+	}
+	if resp.Embedding == nil || len(resp.Embedding.Values) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return resp.Embedding.Values, nil
+}
+
+// Ping implements Provider. It fetches the generation model's metadata.
+func (g *Gemini) Ping(ctx context.Context) error {
+	if _, err := g.textModel.Info(ctx); err != nil {
+		return classifyGeminiError("Gemini model lookup failed", err)
+	}
+	return nil
+}
+
+// classifyGeminiError wraps err with ErrRefused for blocked prompts or answers and with
+// ErrQuota for rate limit responses.
+func classifyGeminiError(msg string, err error) error {
+	var blocked *genai.BlockedError
+	if errors.As(err, &blocked) {
+		return fmt.Errorf("%w: %s: %w", ErrRefused, msg, err)
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %s: %w", ErrQuota, msg, err)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// Name implements Provider.
+func (g *Gemini) Name() string {
+	return ProviderGemini
+}
+
+// Model implements Provider.
+func (g *Gemini) Model() string {
+	return g.config.Model
+}
+
+// EmbeddingModel implements Provider.
+func (g *Gemini) EmbeddingModel() string {
+	return g.config.EmbeddingModel
+}
+
+// Close implements Provider.
+func (g *Gemini) Close() error {
+	return g.client.Close()
+}
@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Provider names selected by LLM_PROVIDER.
+const (
+	ProviderGemini = "gemini" // Google Gemini via genai
+	ProviderOpenAI = "openai" // Any OpenAI-compatible HTTP endpoint (OpenAI, llama.cpp, Ollama, vLLM)
+	ProviderFake   = "fake"   // Deterministic offline responses for tests and local runs
+)
+
+// Errors shared by all providers. Provider errors wrap them, so check with errors.Is.
+var (
+	// ErrEmptyResponse is returned when the model answered without any text.
+	ErrEmptyResponse = errors.New("empty response from model")
+
+	// ErrRefused is returned when the model or its safety filters declined the prompt or
+	// the answer. Sending the same prompt again will not help.
+	ErrRefused = errors.New("model refused")
+
+	// ErrQuota is returned when the provider rejected the request because of rate or
+	// quota limits. The request may succeed later.
+	ErrQuota = errors.New("model quota exceeded")
+
+	// ErrInvalidOutput is returned by callers when the answer does not have the expected
+	// format or content, even after asking the model to repair it.
+	ErrInvalidOutput = errors.New("invalid model output")
+)
+
+// Config holds language model provider configuration.
+type Config struct {
+	Provider       string        // gemini, openai or fake (default gemini)
+	APIKey         string        // Required for gemini; sent as bearer token to openai if set
+	BaseURL        string        // openai: endpoint base URL, e.g. http://localhost:11434/v1
+	Model          string        // Generation model
+	EmbeddingModel string        // Embedding model
+	Temperature    float32       // Sampling temperature
+	Timeout        time.Duration // openai: HTTP timeout per request (default 2m)
+	Dimensions     int           // fake: embedding dimensions (default 768)
+}
+
+// WithGeminiDefaults returns c with the API key, model and embedding model it lacks taken
+// from the given fallbacks, usually GEMINI_API_KEY, GEMINI_MODEL and EMBEDDING_MODEL, if c
+// selects the gemini provider. Other providers are returned unchanged.
+func (c Config) WithGeminiDefaults(apiKey, model, embeddingModel string) Config {
+	if c.Provider != ProviderGemini && c.Provider != "" {
+		return c
+	}
+	if c.APIKey == "" {
+		c.APIKey = apiKey
+	}
+	if c.Model == "" {
+		c.Model = model
+	}
+	if c.EmbeddingModel == "" {
+		c.EmbeddingModel = embeddingModel
+	}
+	return c
+}
+
+// Enabled reports whether c is complete enough to create its provider. Only gemini needs
+// an API key.
+func (c Config) Enabled() bool {
+	return (c.Provider != ProviderGemini && c.Provider != "") || c.APIKey != ""
+}
+
+// Provider generates text, JSON and embeddings with a language model.
+// Implementations are safe for concurrent use.
+type Provider interface {
+	// GenerateJSON asks for a JSON answer and returns the raw text the model produced.
+	// Models may still wrap it in markdown or add prose, so callers parse defensively.
+	GenerateJSON(ctx context.Context, prompt string) (string, error)
+
+	// GenerateText asks for a free-form answer.
+	GenerateText(ctx context.Context, prompt string) (string, error)
+
+	// Embed returns the embedding vector of text.
+	Embed(ctx context.Context, text string) ([]float32, error)
+
+	// Name returns the provider name, e.g. "gemini".
+	Name() string
+
+	// Model returns the generation model name.
+	Model() string
+
+	// EmbeddingModel returns the embedding model name. Vectors of different embedding
+	// models are not comparable.
+	EmbeddingModel() string
+
+	// Ping checks that the provider is reachable and accepts the credentials, without
+	// generating anything.
+	Ping(ctx context.Context) error
+
+	// Close releases the provider's resources.
+	Close() error
+}
+
+// New creates the provider selected by cfg.Provider.
+func New(ctx context.Context, cfg Config) (Provider, error) {
+	switch cfg.Provider {
+	case ProviderGemini, "":
+		return NewGemini(ctx, cfg)
+	case ProviderOpenAI:
+		return NewOpenAI(cfg)
+	case ProviderFake:
+		return NewFake(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q (expected gemini, openai or fake)", cfg.Provider)
+	}
+}
@@ -0,0 +1,233 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBody bounds how much of an error response is included in the error message.
+const maxErrorBody = 512
+
+// OpenAI is the Provider for any OpenAI-compatible HTTP endpoint: the OpenAI API itself or a
+// local server such as llama.cpp (llama-server), Ollama or vLLM.
+type OpenAI struct {
+	client *http.Client
+	config Config
+}
+
+// NewOpenAI creates an OpenAI-compatible provider.
+func NewOpenAI(cfg Config) (*OpenAI, error) {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.openai.com/v1"
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("LLM_MODEL is required for the openai provider")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 2 * time.Minute
+	}
+
+	return &OpenAI{
+		client: &http.Client{Timeout: cfg.Timeout},
+		config: cfg,
+	}, nil
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	Refusal string `json:"refusal,omitempty"`
+}
+
+type responseFormat struct {
+	Type string `json:"type"`
+}
+
+type chatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	Temperature    float32         `json:"temperature"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+// tokenUsage is the usage object of chat and embedding responses. Some local servers
+// leave it out.
+type tokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *tokenUsage `json:"usage"`
+}
+
+type embeddingRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Usage *tokenUsage `json:"usage"`
+}
+
+type modelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// GenerateJSON implements Provider. It requests JSON mode, which OpenAI, llama.cpp and
+// Ollama support; the prompt itself must still ask for JSON.
+func (o *OpenAI) GenerateJSON(ctx context.Context, prompt string) (string, error) {
+	return o.chat(ctx, prompt, &responseFormat{Type: "json_object"})
+}
+
+// GenerateText implements Provider.
+func (o *OpenAI) GenerateText(ctx context.Context, prompt string) (string, error) {
+	return o.chat(ctx, prompt, nil)
+}
+
+func (o *OpenAI) chat(ctx context.Context, prompt string, format *responseFormat) (string, error) {
+	var resp chatResponse
+	err := o.post(ctx, "/chat/completions", chatRequest{
+		Model:          o.config.Model,
+		Messages:       []chatMessage{{Role: "user", Content: prompt}},
+		Temperature:    o.config.Temperature,
+		ResponseFormat: format,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.Usage != nil {
+		reportUsage(ctx, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", ErrEmptyResponse
+	}
+	choice := resp.Choices[0]
+	if choice.Message.Refusal != "" {
+		return "", fmt.Errorf("%w: %s", ErrRefused, choice.Message.Refusal)
+	}
+	if choice.FinishReason == "content_filter" {
+		return "", fmt.Errorf("%w: answer blocked by content filter", ErrRefused)
+	}
+	if strings.TrimSpace(choice.Message.Content) == "" {
+		return "", ErrEmptyResponse
+	}
+	return choice.Message.Content, nil
+}
+
+// Embed implements Provider.
+func (o *OpenAI) Embed(ctx context.Context, text string) ([]float32, error) {
+	model := o.config.EmbeddingModel
+	if model == "" {
+		return nil, fmt.Errorf("LLM_EMBEDDING_MODEL is required for openai embeddings")
+	}
+
+	var resp embeddingResponse
+	if err := o.post(ctx, "/embeddings", embeddingRequest{Model: model, Input: text}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Usage != nil {
+		reportUsage(ctx, resp.Usage.PromptTokens, 0)
+	}
+
+	if len(resp.Data) == 0 || len(resp.Data[0].Embedding) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	return resp.Data[0].Embedding, nil
+}
+
+// Ping implements Provider. It lists the endpoint's models, which OpenAI, llama.cpp, Ollama
+// and vLLM all serve. Local servers name models differently (e.g. "llama3.1:latest"), so the
+// list is not checked for the generation model.
+func (o *OpenAI) Ping(ctx context.Context) error {
+	var resp modelsResponse
+	return o.do(ctx, http.MethodGet, "/models", nil, &resp)
+}
+
+// post sends a JSON request to path and decodes the JSON response into out.
+func (o *OpenAI) post(ctx context.Context, path string, body, out any) error {
+	return o.do(ctx, http.MethodPost, path, body, out)
+}
+
+// do sends a request with body encoded as JSON (nil = no body) and decodes the JSON response
+// into out.
+func (o *OpenAI) do(ctx context.Context, method, path string, body, out any) error {
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		payload = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, o.config.BaseURL+path, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if o.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.config.APIKey)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("LLM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		err := fmt.Errorf("LLM API error: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return fmt.Errorf("%w: %w", ErrQuota, err)
+		}
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode LLM response: %w", err)
+	}
+	return nil
+}
+
+// Name implements Provider.
+func (o *OpenAI) Name() string {
+	return ProviderOpenAI
+}
+
+// Model implements Provider.
+func (o *OpenAI) Model() string {
+	return o.config.Model
+}
+
+// EmbeddingModel implements Provider.
+func (o *OpenAI) EmbeddingModel() string {
+	return o.config.EmbeddingModel
+}
+
+// Close implements Provider.
+func (o *OpenAI) Close() error {
+	o.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,179 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// Call methods recorded by Metered.
+const (
+	MethodGenerateJSON = "generate_json"
+	MethodGenerateText = "generate_text"
+	MethodEmbed        = "embed"
+)
+
+// ErrBudgetExceeded is returned instead of calling the model when a daily token budget is
+// used up. It wraps ErrQuota, so callers that back off on quota errors back off on it too.
+var ErrBudgetExceeded = fmt.Errorf("%w: daily AI budget exceeded", ErrQuota)
+
+// Usage is the number of tokens one model call consumed.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	Estimated        bool // The provider did not report the counts; see EstimateTokens
+}
+
+// Total returns the prompt and completion tokens together.
+func (u Usage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Call describes one model call for accounting. Before the call (Accountant.Allow) only the
+// identifying fields are set.
+type Call struct {
+	Service   string // Service making the call, e.g. "auth_service"
+	Provider  string
+	Model     string
+	Method    string // MethodGenerateJSON, MethodGenerateText or MethodEmbed
+	Operation string // Prompt name, e.g. "cv_parse" (see WithOperation)
+	UserID    string // User the call is made for (see WithUser)
+	JobID     string // Job the call is made for (see WithJob)
+
+	Usage   Usage
+	Latency time.Duration
+	Err     error // nil if the model answered
+}
+
+// Accountant checks budgets before and records usage after every call of a Metered provider.
+type Accountant interface {
+	// Allow returns an error wrapping ErrBudgetExceeded if the call must not be made.
+	// Implementations let calls through when they cannot check the budget.
+	Allow(ctx context.Context, call Call) error
+
+	// Record stores a finished call, failed ones included. Implementations log their own
+	// failures instead of returning them.
+	Record(ctx context.Context, call Call)
+}
+
+type (
+	userKey      struct{}
+	jobKey       struct{}
+	operationKey struct{}
+	usageKey     struct{}
+)
+
+// WithUser returns a context whose model calls are accounted to the user.
+func WithUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// WithJob returns a context whose model calls are accounted to the job.
+func WithJob(ctx context.Context, jobID string) context.Context {
+	return context.WithValue(ctx, jobKey{}, jobID)
+}
+
+// WithOperation returns a context whose model calls are accounted to the operation, usually
+// the prompt name.
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// contextString returns the string stored under key, or "".
+func contextString(ctx context.Context, key any) string {
+	s, _ := ctx.Value(key).(string)
+	return s
+}
+
+// reportUsage lets a provider report the token counts of the call in progress to Metered.
+// Without a Metered provider around it, it does nothing.
+func reportUsage(ctx context.Context, promptTokens, completionTokens int) {
+	if u, ok := ctx.Value(usageKey{}).(*Usage); ok {
+		u.PromptTokens = promptTokens
+		u.CompletionTokens = completionTokens
+	}
+}
+
+// EstimateTokens approximates the token count of text for providers that do not report it,
+// at four characters per token.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// metered is the Provider returned by Metered.
+type metered struct {
+	Provider
+	service    string
+	accountant Accountant
+}
+
+// Metered wraps provider so that every generate and embed call is first checked against the
+// accountant's budgets and then recorded with its token counts, latency and the user, job
+// and operation of its context. Other methods are passed through.
+func Metered(provider Provider, service string, accountant Accountant) Provider {
+	return &metered{Provider: provider, service: service, accountant: accountant}
+}
+
+// GenerateJSON implements Provider.
+func (m *metered) GenerateJSON(ctx context.Context, prompt string) (string, error) {
+	return m.call(ctx, MethodGenerateJSON, prompt, func(ctx context.Context) (string, error) {
+		return m.Provider.GenerateJSON(ctx, prompt)
+	})
+}
+
+// GenerateText implements Provider.
+func (m *metered) GenerateText(ctx context.Context, prompt string) (string, error) {
+	return m.call(ctx, MethodGenerateText, prompt, func(ctx context.Context) (string, error) {
+		return m.Provider.GenerateText(ctx, prompt)
+	})
+}
+
+// Embed implements Provider.
+func (m *metered) Embed(ctx context.Context, text string) ([]float32, error) {
+	var vec []float32
+	_, err := m.call(ctx, MethodEmbed, text, func(ctx context.Context) (string, error) {
+		var err error
+		vec, err = m.Provider.Embed(ctx, text)
+		return "", err
+	})
+	return vec, err
+}
+
+// call runs fn, which returns the answer text, if the accountant allows it and records it.
+// Token counts the provider did not report for a successful call are estimated from the
+// prompt and the answer.
+func (m *metered) call(ctx context.Context, method, prompt string, fn func(ctx context.Context) (string, error)) (string, error) {
+	model := m.Provider.Model()
+	if method == MethodEmbed {
+		model = m.Provider.EmbeddingModel()
+	}
+	call := Call{
+		Service:   m.service,
+		Provider:  m.Provider.Name(),
+		Model:     model,
+		Method:    method,
+		Operation: contextString(ctx, operationKey{}),
+		UserID:    contextString(ctx, userKey{}),
+		JobID:     contextString(ctx, jobKey{}),
+	}
+
+	if err := m.accountant.Allow(ctx, call); err != nil {
+		return "", err
+	}
+
+	var usage Usage
+	start := time.Now()
+	answer, err := fn(context.WithValue(ctx, usageKey{}, &usage))
+	call.Latency = time.Since(start)
+	call.Err = err
+
+	if err == nil && usage.PromptTokens == 0 && usage.CompletionTokens == 0 {
+		usage = Usage{PromptTokens: EstimateTokens(prompt), CompletionTokens: EstimateTokens(answer), Estimated: true}
+	}
+	call.Usage = usage
+
+	// Record even if the caller gave up, the tokens are spent either way
+	m.accountant.Record(context.WithoutCancel(ctx), call)
+	return answer, err
+}
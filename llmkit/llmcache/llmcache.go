@@ -0,0 +1,177 @@
+package llmcache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"llmkit/llm"
+)
+
+// purgeInterval is how often expired entries are deleted
+const purgeInterval = time.Hour
+
+var (
+	lookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "llm_cache_lookups_total",
+		Help: "LLM cache lookups by prompt and result (hit, miss, error).",
+	}, []string{"prompt", "result"})
+
+	stores = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "llm_cache_stores_total",
+		Help: "Answers stored in the LLM cache by prompt.",
+	}, []string{"prompt"})
+)
+
+// Cache is the llm.Cache on the llm_cache table (ai_job_processing migration 016). Lookup and
+// store errors are logged and counted, never returned, so an unavailable table only costs
+// model calls.
+type Cache struct {
+	db  *sqlx.DB
+	ttl time.Duration
+
+	mu     sync.Mutex
+	purged time.Time
+}
+
+// New creates a cache whose entries expire after ttl unless their prompt sets its own TTL.
+func New(db *sqlx.DB, ttl time.Duration) *Cache {
+	return &Cache{db: db, ttl: ttl}
+}
+
+// Get implements llm.Cache.
+func (c *Cache) Get(ctx context.Context, p llm.Prompt, key string) (string, bool) {
+	var answer string
+	err := c.db.GetContext(ctx, &answer, `
+		UPDATE llm_cache
+		SET hits = hits + 1, last_hit_at = NOW()
+		WHERE key = $1 AND expires_at > NOW()
+		RETURNING answer`,
+		key,
+	)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		lookups.WithLabelValues(p.Name, "miss").Inc()
+		return "", false
+	case err != nil:
+		lookups.WithLabelValues(p.Name, "error").Inc()
+		slog.Warn("LLM cache lookup failed", "prompt", p.String(), "error", err)
+		return "", false
+	}
+	lookups.WithLabelValues(p.Name, "hit").Inc()
+	return answer, true
+}
+
+// Put implements llm.Cache.
+func (c *Cache) Put(ctx context.Context, p llm.Prompt, key, answer string) {
+	ttl := c.ttl
+	if p.TTL > 0 {
+		ttl = p.TTL
+	}
+
+	_, err := c.db.ExecContext(ctx, `
+		INSERT INTO llm_cache (key, prompt, prompt_version, answer, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + $5 * INTERVAL '1 second')
+		ON CONFLICT (key) DO UPDATE
+		SET answer = EXCLUDED.answer,
+		    created_at = NOW(),
+		    expires_at = EXCLUDED.expires_at`,
+		key, p.Name, p.Version, answer, ttl.Seconds(),
+	)
+	if err != nil {
+		slog.Warn("LLM cache store failed", "prompt", p.String(), "error", err)
+		return
+	}
+	stores.WithLabelValues(p.Name).Inc()
+
+	c.purgeExpired(ctx)
+}
+
+// Invalidate deletes the entries of the given prompts that were made with another version
+// of the template. Services call it at startup with their current prompts.
+func (c *Cache) Invalidate(ctx context.Context, prompts []llm.Prompt) (int, error) {
+	total := 0
+	for _, p := range prompts {
+		res, err := c.db.ExecContext(ctx, `
+			DELETE FROM llm_cache WHERE prompt = $1 AND prompt_version <> $2`,
+			p.Name, p.Version,
+		)
+		if err != nil {
+			return total, fmt.Errorf("failed to invalidate LLM cache for %s: %w", p, err)
+		}
+		n, _ := res.RowsAffected()
+		total += int(n)
+	}
+	return total, nil
+}
+
+// Clear deletes all entries of a prompt, or all entries if prompt is empty.
+func (c *Cache) Clear(ctx context.Context, prompt string) (int, error) {
+	res, err := c.db.ExecContext(ctx, `
+		DELETE FROM llm_cache WHERE $1 = '' OR prompt = $1`,
+		prompt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear LLM cache: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// Stats summarizes the cache per prompt version.
+type Stats struct {
+	Prompt  string     `db:"prompt" json:"prompt"`
+	Version int        `db:"prompt_version" json:"version"`
+	Entries int        `db:"entries" json:"entries"`
+	Expired int        `db:"expired" json:"expired"`
+	Hits    int        `db:"hits" json:"hits"`
+	LastHit *time.Time `db:"last_hit_at" json:"last_hit_at,omitempty"`
+	Newest  time.Time  `db:"newest" json:"newest"`
+}
+
+// Stats returns the entry and hit counts per prompt version.
+func (c *Cache) Stats(ctx context.Context) ([]Stats, error) {
+	var stats []Stats
+	err := c.db.SelectContext(ctx, &stats, `
+		SELECT prompt, prompt_version,
+		       COUNT(*) AS entries,
+		       COUNT(*) FILTER (WHERE expires_at <= NOW()) AS expired,
+		       COALESCE(SUM(hits), 0) AS hits,
+		       MAX(last_hit_at) AS last_hit_at,
+		       MAX(created_at) AS newest
+		FROM llm_cache
+		GROUP BY prompt, prompt_version
+		ORDER BY prompt, prompt_version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM cache stats: %w", err)
+	}
+	return stats, nil
+}
+
+// purgeExpired deletes expired entries at most once per purgeInterval.
+func (c *Cache) purgeExpired(ctx context.Context) {
+	c.mu.Lock()
+	if time.Since(c.purged) < purgeInterval {
+		c.mu.Unlock()
+		return
+	}
+	c.purged = time.Now()
+	c.mu.Unlock()
+
+	res, err := c.db.ExecContext(ctx, `DELETE FROM llm_cache WHERE expires_at <= NOW()`)
+	if err != nil {
+		slog.Warn("failed to purge expired LLM cache entries", "error", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		slog.Info("purged expired LLM cache entries", "count", n)
+	}
+}
@@ -0,0 +1,201 @@
+package llmusage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"llmkit/llm"
+)
+
+// Config holds a service's daily token budgets and model prices.
+type Config struct {
+	UserDailyTokens    int     // Tokens a user may use per day across all services (0 = unlimited)
+	ServiceDailyTokens int     // Tokens the service may use per day (0 = unlimited)
+	PromptPrice        float64 // USD per million prompt tokens
+	CompletionPrice    float64 // USD per million completion tokens
+}
+
+// HasBudget reports whether c sets a daily token budget.
+func (c Config) HasBudget() bool {
+	return c.UserDailyTokens > 0 || c.ServiceDailyTokens > 0
+}
+
+// Recorder is the llm.Accountant on the ai_usage table (ai_job_processing migration 022).
+// Budgets are checked against the tokens recorded since midnight UTC, so concurrent calls
+// can overshoot a budget by their own usage. Errors are logged, never returned, so an
+// unavailable table neither blocks model calls nor fails them; see CheckTable.
+type Recorder struct {
+	db     *sqlx.DB
+	config Config
+}
+
+// New creates a recorder with the given budgets and prices.
+func New(db *sqlx.DB, cfg Config) *Recorder {
+	return &Recorder{db: db, config: cfg}
+}
+
+// CheckTable returns an error if the ai_usage table does not exist. Allow lets calls through
+// when it cannot read the table, so services with a budget check for it at startup.
+func CheckTable(ctx context.Context, db *sqlx.DB) error {
+	var exists bool
+	if err := db.GetContext(ctx, &exists, `SELECT to_regclass('ai_usage') IS NOT NULL`); err != nil {
+		return fmt.Errorf("failed to look up the ai_usage table: %w", err)
+	}
+	if !exists {
+		return errors.New("ai_usage table not found: the daily token budgets need ai_job_processing's migrations (022_create_ai_usage)")
+	}
+	return nil
+}
+
+// Allow implements llm.Accountant.
+func (r *Recorder) Allow(ctx context.Context, call llm.Call) error {
+	if limit := r.config.ServiceDailyTokens; limit > 0 {
+		var used int
+		err := r.db.GetContext(ctx, &used, `
+			SELECT COALESCE(SUM(prompt_tokens + completion_tokens), 0)
+			FROM ai_usage
+			WHERE service = $1 AND created_at >= date_trunc('day', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'`,
+			call.Service,
+		)
+		if err != nil {
+			slog.Warn("AI budget check failed", "service", call.Service, "error", err)
+			return nil
+		}
+		if used >= limit {
+			slog.Warn("AI service budget exceeded", "service", call.Service, "used", used, "limit", limit)
+			return fmt.Errorf("%w: %s used %d of %d tokens today", llm.ErrBudgetExceeded, call.Service, used, limit)
+		}
+	}
+
+	if limit := r.config.UserDailyTokens; limit > 0 && call.UserID != "" {
+		var used int
+		err := r.db.GetContext(ctx, &used, `
+			SELECT COALESCE(SUM(prompt_tokens + completion_tokens), 0)
+			FROM ai_usage
+			WHERE user_id = $1 AND created_at >= date_trunc('day', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'`,
+			call.UserID,
+		)
+		if err != nil {
+			slog.Warn("AI budget check failed", "user_id", call.UserID, "error", err)
+			return nil
+		}
+		if used >= limit {
+			slog.Warn("AI user budget exceeded", "user_id", call.UserID, "used", used, "limit", limit)
+			return fmt.Errorf("%w: user used %d of %d tokens today", llm.ErrBudgetExceeded, used, limit)
+		}
+	}
+
+	return nil
+}
+
+// Record implements llm.Accountant.
+func (r *Recorder) Record(ctx context.Context, call llm.Call) {
+	cost := (float64(call.Usage.PromptTokens)*r.config.PromptPrice +
+		float64(call.Usage.CompletionTokens)*r.config.CompletionPrice) / 1e6
+
+	var errMsg *string
+	if call.Err != nil {
+		msg := call.Err.Error()
+		errMsg = &msg
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO ai_usage (
+			service, provider, model, method, operation, user_id, job_id,
+			prompt_tokens, completion_tokens, estimated, cost_usd, latency_ms, success, error
+		) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8, $9, $10, $11, $12, $13, $14)`,
+		call.Service, call.Provider, call.Model, call.Method, call.Operation, call.UserID, call.JobID,
+		call.Usage.PromptTokens, call.Usage.CompletionTokens, call.Usage.Estimated, cost,
+		call.Latency.Milliseconds(), call.Err == nil, errMsg,
+	)
+	if err != nil {
+		slog.Warn("failed to record AI usage", "service", call.Service, "operation", call.Operation, "error", err)
+		return
+	}
+
+	slog.Debug("AI call recorded",
+		"operation", call.Operation,
+		"model", call.Model,
+		"prompt_tokens", call.Usage.PromptTokens,
+		"completion_tokens", call.Usage.CompletionTokens,
+		"duration_ms", call.Latency.Milliseconds(),
+	)
+}
+
+// Groupings of a usage summary, by the name used in the API and CLI.
+var groupings = map[string]string{
+	"service":   "service",
+	"user":      "COALESCE(user_id, '')",
+	"model":     "provider || '/' || model",
+	"operation": "service || '/' || operation",
+	"day":       "to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')",
+}
+
+// ValidGrouping reports whether by names a grouping of Summarize.
+func ValidGrouping(by string) bool {
+	_, ok := groupings[by]
+	return ok
+}
+
+// Filter restricts a usage summary.
+type Filter struct {
+	Since   time.Time // Only calls made since then
+	Service string    // Only calls of this service ("" = all)
+	UserID  string    // Only calls made for this user ("" = all)
+}
+
+// Since returns midnight UTC of the first of the last days days, today included.
+func Since(days int) time.Time {
+	return time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+}
+
+// Summary is the usage of one group, e.g. one service or one day.
+type Summary struct {
+	Key              string  `db:"key" json:"key"`
+	Calls            int     `db:"calls" json:"calls"`
+	Failed           int     `db:"failed" json:"failed"`
+	PromptTokens     int64   `db:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int64   `db:"completion_tokens" json:"completion_tokens"`
+	CostUSD          float64 `db:"cost_usd" json:"cost_usd"`
+	AvgLatencyMs     int     `db:"avg_latency_ms" json:"avg_latency_ms"`
+}
+
+// Summarize returns the usage matching f grouped by service, user, model, operation or day,
+// most tokens first (by day: newest first).
+func (r *Recorder) Summarize(ctx context.Context, by string, f Filter) ([]Summary, error) {
+	key, ok := groupings[by]
+	if !ok {
+		return nil, fmt.Errorf("unknown usage grouping %q (expected service, user, model, operation or day)", by)
+	}
+	order := "SUM(prompt_tokens + completion_tokens) DESC, key"
+	if by == "day" {
+		order = "key DESC"
+	}
+
+	summaries := make([]Summary, 0)
+	err := r.db.SelectContext(ctx, &summaries, fmt.Sprintf(`
+		SELECT %s AS key,
+		       COUNT(*) AS calls,
+		       COUNT(*) FILTER (WHERE NOT success) AS failed,
+		       COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens,
+		       COALESCE(SUM(completion_tokens), 0) AS completion_tokens,
+		       COALESCE(SUM(cost_usd), 0)::float8 AS cost_usd,
+		       COALESCE(AVG(latency_ms), 0)::int AS avg_latency_ms
+		FROM ai_usage
+		WHERE created_at >= $1
+		  AND ($2 = '' OR service = $2)
+		  AND ($3 = '' OR user_id = $3)
+		GROUP BY 1
+		ORDER BY %s`, key, order),
+		f.Since, f.Service, f.UserID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize AI usage: %w", err)
+	}
+	return summaries, nil
+}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/joho/godotenv"
 
+	"llmkit/llm"
+	"llmkit/llmusage"
 	"matching_service/internal/api"
 	"matching_service/internal/auth"
 	"matching_service/internal/config"
@@ -64,6 +66,7 @@ Commands:
 Environment Variables:
   DATABASE_URL          PostgreSQL connection string (required)
   GEMINI_API_KEY        Gemini API key (required for AI scoring)
+  LLM_PROVIDER          gemini, openai or fake (default: gemini)
   AUTH_SERVICE_URL      URL of auth_service (default: http://localhost:8082)
   PORT                  Server port (default: 8086)`)
 }
@@ -86,24 +89,30 @@ func runServer(cfg *config.Config) {
 	defer dbConn.Close()
 	slog.Info("Database connected")
 
+	// The daily token budgets are kept in ai_usage, created by ai_job_processing's migrations
+	if cfg.Usage.HasBudget() {
+		if err := llmusage.CheckTable(ctx, dbConn); err != nil {
+			slog.Error("Failed to enable the AI token budgets", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// Auth client
 	authClient := auth.NewClient(cfg.AuthServiceURL)
 	slog.Info("Auth client initialized", "url", cfg.AuthServiceURL)
 
-	// Gemini client (optional)
+	// LLM client (optional); calls are recorded in ai_usage and checked against the daily
+	// token budgets
 	var geminiClient *gemini.Client
-	if cfg.GeminiAPIKey != "" {
-		geminiClient, err = gemini.NewClient(ctx, gemini.ClientConfig{
-			APIKey:         cfg.GeminiAPIKey,
-			Model:          cfg.GeminiModel,
-			EmbeddingModel: cfg.EmbeddingModel,
-			Temperature:    cfg.GeminiTemperature,
-		})
+	if cfg.LLM.Enabled() {
+		provider, err := llm.New(ctx, cfg.LLM)
 		if err != nil {
-			slog.Warn("Failed to initialize Gemini, AI features disabled", "error", err)
+			slog.Warn("Failed to initialize LLM provider, AI features disabled", "error", err)
 		} else {
+			provider = llm.Metered(provider, "matching_service", llmusage.New(dbConn, cfg.Usage))
+			geminiClient = gemini.NewClient(provider)
 			defer geminiClient.Close()
-			slog.Info("Gemini client initialized (AI matching enabled)")
+			slog.Info("LLM client initialized (AI matching enabled)", "provider", provider.Name(), "model", provider.Model())
 		}
 	} else {
 		slog.Info("GEMINI_API_KEY not set, using rule-based matching")
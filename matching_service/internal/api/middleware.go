@@ -8,6 +8,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 
+	"llmkit/llm"
 	"matching_service/internal/models"
 )
 
@@ -49,6 +50,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		if userIDStr, ok := claims["user_id"].(string); ok {
 			if userID, err := uuid.Parse(userIDStr); err == nil {
 				c.Set("user_id", userID)
+				c.Request = c.Request.WithContext(llm.WithUser(c.Request.Context(), userID.String()))
 			}
 		}
 
@@ -3,6 +3,9 @@ package config
 import (
 	"os"
 	"strconv"
+
+	"llmkit/llm"
+	"llmkit/llmusage"
 )
 
 // Config holds all application configuration.
@@ -13,10 +16,12 @@ type Config struct {
 	DatabaseURL    string
 	AuthServiceURL string
 
-	GeminiAPIKey      string
-	GeminiModel       string
-	EmbeddingModel    string
-	GeminiTemperature float32
+	// LLM provider (gemini, openai or fake); GEMINI_* configure the gemini provider
+	LLM llm.Config
+
+	// AI usage accounting in ai_usage: daily token budgets (0 = unlimited) and prices in
+	// USD per million tokens
+	Usage llmusage.Config
 
 	CacheEnabled bool
 	CacheTTL     int // seconds
@@ -27,18 +32,33 @@ type Config struct {
 
 func Load() *Config {
 	return &Config{
-		Port:              GetEnv("PORT", "8086"),
-		Host:              GetEnv("HOST", "0.0.0.0"),
-		DatabaseURL:       GetEnv("DATABASE_URL", ""),
-		AuthServiceURL:    GetEnv("AUTH_SERVICE_URL", "http://localhost:8082"),
-		GeminiAPIKey:      GetEnv("GEMINI_API_KEY", ""),
-		GeminiModel:       GetEnv("GEMINI_MODEL", "gemini-2.0-flash"),
-		EmbeddingModel:    GetEnv("EMBEDDING_MODEL", "text-embedding-004"),
-		GeminiTemperature: GetEnvFloat32("GEMINI_TEMPERATURE", 0.3),
-		CacheEnabled:      GetEnvBool("CACHE_ENABLED", true),
-		CacheTTL:          GetEnvInt("CACHE_TTL", 3600),
-		LogLevel:          GetEnv("LOG_LEVEL", "INFO"),
-		LogFormat:         GetEnv("LOG_FORMAT", "json"),
+		Port:           GetEnv("PORT", "8086"),
+		Host:           GetEnv("HOST", "0.0.0.0"),
+		DatabaseURL:    GetEnv("DATABASE_URL", ""),
+		AuthServiceURL: GetEnv("AUTH_SERVICE_URL", "http://localhost:8082"),
+		LLM: llm.Config{
+			Provider:       GetEnv("LLM_PROVIDER", llm.ProviderGemini),
+			APIKey:         GetEnv("LLM_API_KEY", ""),
+			BaseURL:        GetEnv("LLM_BASE_URL", ""),
+			Model:          GetEnv("LLM_MODEL", ""),
+			EmbeddingModel: GetEnv("LLM_EMBEDDING_MODEL", ""),
+			Temperature:    GetEnvFloat32("GEMINI_TEMPERATURE", 0.3),
+		}.WithGeminiDefaults(
+			GetEnv("GEMINI_API_KEY", ""),
+			GetEnv("GEMINI_MODEL", "gemini-2.0-flash"),
+			GetEnv("EMBEDDING_MODEL", "text-embedding-004"),
+		),
+		CacheEnabled: GetEnvBool("CACHE_ENABLED", true),
+		CacheTTL:     GetEnvInt("CACHE_TTL", 3600),
+		LogLevel:     GetEnv("LOG_LEVEL", "INFO"),
+		LogFormat:    GetEnv("LOG_FORMAT", "json"),
+
+		Usage: llmusage.Config{
+			UserDailyTokens:    GetEnvInt("LLM_USER_DAILY_TOKENS", 0),
+			ServiceDailyTokens: GetEnvInt("LLM_SERVICE_DAILY_TOKENS", 0),
+			PromptPrice:        GetEnvFloat64("LLM_PRICE_PROMPT", 0),
+			CompletionPrice:    GetEnvFloat64("LLM_PRICE_COMPLETION", 0),
+		},
 	}
 }
 
@@ -67,6 +87,16 @@ func GetEnvFloat32(key string, defaultValue float32) float32 {
 	return defaultValue
 }
 
+// GetEnvFloat64 returns the float64 value of an environment variable or a default value.
+func GetEnvFloat64(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
 func GetEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolVal, err := strconv.ParseBool(value); err == nil {
@@ -8,63 +8,28 @@ import (
 	"strings"
 	"time"
 
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
-
+	"llmkit/llm"
 	"matching_service/internal/models"
 )
 
-type ClientConfig struct {
-	APIKey         string
-	Model          string
-	EmbeddingModel string
-	Temperature    float32
-}
-
+// Client runs the profile analysis, match scoring and embedding calls on an LLM provider
+// (Gemini by default, see LLM_PROVIDER).
 type Client struct {
-	client         *genai.Client
-	model          *genai.GenerativeModel
-	embeddingModel *genai.EmbeddingModel
-	config         ClientConfig
+	llm llm.Provider
 }
 
-func NewClient(ctx context.Context, cfg ClientConfig) (*Client, error) {
-	if cfg.APIKey == "" {
-		return nil, fmt.Errorf("GEMINI_API_KEY is required")
-	}
-
-	if cfg.Model == "" {
-		cfg.Model = "gemini-2.0-flash"
-	}
-	if cfg.EmbeddingModel == "" {
-		cfg.EmbeddingModel = "text-embedding-004"
-	}
-
-	client, err := genai.NewClient(ctx, option.WithAPIKey(cfg.APIKey))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
-	}
-
-	model := client.GenerativeModel(cfg.Model)
-	model.SetTemperature(cfg.Temperature)
-
-	embeddingModel := client.EmbeddingModel(cfg.EmbeddingModel)
-
-	return &Client{
-		client:         client,
-		model:          model,
-		embeddingModel: embeddingModel,
-		config:         cfg,
-	}, nil
+func NewClient(provider llm.Provider) *Client {
+	return &Client{llm: provider}
 }
 
 func (c *Client) Close() error {
-	return c.client.Close()
+	return c.llm.Close()
 }
 
 // AnalyzeProfile analyzes a user profile and extracts insights.
 func (c *Client) AnalyzeProfile(ctx context.Context, profile *models.UserProfile) (*models.ProfileAnalysis, error) {
 	start := time.Now()
+	ctx = llm.WithOperation(ctx, "profile_analysis")
 
 	skillNames := make([]string, len(profile.Skills))
 	for i, s := range profile.Skills {
@@ -105,17 +70,10 @@ Return ONLY valid JSON.`,
 		strings.Join(expDescriptions, "; "),
 	)
 
-	c.model.ResponseMIMEType = "application/json"
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
+	text, err := c.llm.GenerateJSON(ctx, prompt)
 	if err != nil {
-		return nil, fmt.Errorf("Gemini API error: %w", err)
-	}
-
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("empty response from Gemini")
+		return nil, err
 	}
-
-	text := extractText(resp.Candidates[0].Content.Parts)
 	text = cleanJSON(text)
 
 	var analysis models.ProfileAnalysis
@@ -133,6 +91,7 @@ Return ONLY valid JSON.`,
 // ScoreJobMatch uses AI to score how well a job matches a profile.
 func (c *Client) ScoreJobMatch(ctx context.Context, profile *models.UserProfile, jobTitle, jobDescription string) (*models.JobScoreResponse, error) {
 	start := time.Now()
+	ctx = llm.WithOperation(ctx, "job_score")
 
 	skillNames := make([]string, len(profile.Skills))
 	for i, s := range profile.Skills {
@@ -169,17 +128,10 @@ All scores 0-100. Return ONLY valid JSON.`,
 		jobTitle, jobDescription,
 	)
 
-	c.model.ResponseMIMEType = "application/json"
-	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
+	text, err := c.llm.GenerateJSON(ctx, prompt)
 	if err != nil {
-		return nil, fmt.Errorf("Gemini API error: %w", err)
-	}
-
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("empty response from Gemini")
+		return nil, err
 	}
-
-	text := extractText(resp.Candidates[0].Content.Parts)
 	text = cleanJSON(text)
 
 	var score models.JobScoreResponse
@@ -193,26 +145,12 @@ All scores 0-100. Return ONLY valid JSON.`,
 
 // GenerateEmbedding generates a vector embedding for text.
 func (c *Client) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
-	resp, err := c.embeddingModel.EmbedContent(ctx, genai.Text(text))
+	embedding, err := c.llm.Embed(llm.WithOperation(ctx, "embedding"), text)
 	if err != nil {
 		return nil, fmt.Errorf("embedding error: %w", err)
 	}
 
-	if resp.Embedding == nil || len(resp.Embedding.Values) == 0 {
-		return nil, fmt.Errorf("empty embedding response")
-	}
-
-	return resp.Embedding.Values, nil
-}
-
-func extractText(parts []genai.Part) string {
-	var texts []string
-	for _, part := range parts {
-		if text, ok := part.(genai.Text); ok {
-			texts = append(texts, string(text))
-		}
-	}
-	return strings.Join(texts, "")
+	return embedding, nil
 }
 
 func cleanJSON(text string) string {
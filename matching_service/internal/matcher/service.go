@@ -3,6 +3,7 @@ package matcher
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
@@ -12,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
+	"llmkit/llm"
 	"matching_service/internal/auth"
 	"matching_service/internal/gemini"
 	"matching_service/internal/models"
@@ -121,7 +123,7 @@ func (s *Service) ScoreJob(ctx context.Context, accessToken, jobID string) (*mod
 
 	// Use AI for detailed scoring
 	if s.geminiClient != nil {
-		score, err := s.geminiClient.ScoreJobMatch(ctx, profile, job.Title, job.Description)
+		score, err := s.geminiClient.ScoreJobMatch(llm.WithJob(ctx, jobID), profile, job.Title, job.Description)
 		if err == nil {
 			score.JobID = jobID
 			score.JobTitle = job.Title
@@ -153,7 +155,11 @@ func (s *Service) AnalyzeProfile(ctx context.Context, accessToken string) (*mode
 	}
 
 	if s.geminiClient != nil {
-		return s.geminiClient.AnalyzeProfile(ctx, profile)
+		analysis, err := s.geminiClient.AnalyzeProfile(ctx, profile)
+		if !errors.Is(err, llm.ErrBudgetExceeded) {
+			return analysis, err
+		}
+		slog.Warn("AI budget exceeded, using basic profile analysis", "error", err)
 	}
 
 	// Basic analysis without AI
@@ -0,0 +1,1059 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"scrapper/internal/config"
+	"scrapper/internal/db"
+	"scrapper/internal/logger"
+	"scrapper/internal/metrics"
+	"scrapper/internal/models"
+	"scrapper/internal/scheduler"
+	"scrapper/internal/scraper"
+	"scrapper/internal/store"
+)
+
+var (
+	version = "1.0.0"
+	commit  = "dev"
+)
+
+func main() {
+	// Load .env file if present
+	godotenv.Load()
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Initialize logger
+	log := logger.New(&logger.Config{
+		Level:  logger.Level(cfg.LogLevel),
+		Format: logger.Format(cfg.LogFormat),
+	})
+	log.SetDefault()
+
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "scrape":
+		runScrape(cfg, os.Args[2:])
+	case "resume":
+		runResume(cfg, os.Args[2:])
+	case "serve":
+		runServe(cfg, os.Args[2:])
+	case "profiles":
+		runProfiles(cfg, os.Args[2:])
+	case "jobs":
+		runJobs(cfg, os.Args[2:])
+	case "revisions":
+		runRevisions(cfg, os.Args[2:])
+	case "duplicates":
+		runDuplicates(cfg, os.Args[2:])
+	case "dedupe":
+		runDedupe(cfg, os.Args[2:])
+	case "runs":
+		runRuns(cfg, os.Args[2:])
+	case "migrate":
+		runMigrate(cfg, os.Args[2:])
+	case "version":
+		fmt.Printf("scrapper %s (%s)\n", version, commit)
+	case "help", "--help", "-h":
+		printUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown command '%s'\n\n", os.Args[1])
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println(`Job Scrapper - job-room.ch and career pages to PostgreSQL
+
+Usage:
+  scrapper <command> [options]
+
+Commands:
+  scrape    Run a scraping job
+  resume    Continue an interrupted run from its last checkpoint
+  serve     Run scrape profiles on their schedules (daemon)
+  profiles  Manage scheduled scrape profiles (list, add, remove)
+  jobs      List jobs from database
+  revisions Show the change history of a job
+  duplicates Show the duplicate cluster of a job
+  dedupe    Fingerprint and cluster jobs stored before duplicate detection
+  runs      List scrape runs, or show one run's timeline
+  migrate   Run database migrations
+  version   Show version information
+  help      Show this help message
+
+Run 'scrapper <command> --help' for command options.
+
+Environment Variables:
+  DATABASE_URL               PostgreSQL connection string (required)
+  LOG_LEVEL                  DEBUG, INFO, WARN, ERROR (default: INFO)
+  LOG_FORMAT                 text or json (default: text)
+  SCRAPER_DELAY_MIN_MS       Minimum delay between requests (default: 2000)
+  SCRAPER_DELAY_MAX_MS       Maximum delay between requests (default: 5000)
+  SCRAPER_DEFAULT_MAX_PAGES  Default max pages (default: 0 = unlimited)
+  SCRAPER_DEFAULT_DAYS_BACK  Default days back filter (default: 60)
+  SCRAPER_WORKERS            Concurrent job detail fetches per run (default: 1)
+  SCRAPER_EXPIRE_AFTER_RUNS  Missed complete runs before a job is expired (default: 3, 0 = off)
+  SCRAPER_EXPIRE_RECHECK_LIMIT  Suspect jobs re-fetched per reconciliation (default: 20)
+  SCRAPER_STALE_RUN_MINUTES  Age after which a running run no longer blocks its profile (default: 360)
+  METRICS_ADDR               serve: listen address of the /metrics endpoint (optional)
+  METRICS_PUSH_URL           scrape/resume: Pushgateway URL to push metrics to (optional)
+  METRICS_TEXTFILE           scrape/resume: file to write metrics to (optional)
+  AI_PROCESSING_MODE         Queue stored jobs for ai_job_processing: none, process, normalize, translate (default: none)`)
+}
+
+// requireDatabaseURL exits if no connection string was provided.
+func requireDatabaseURL(databaseURL string) {
+	if databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: DATABASE_URL is required. Set via environment variable or --database flag.")
+		os.Exit(1)
+	}
+}
+
+// openStore connects to the database and returns a store bound to it.
+func openStore(ctx context.Context, databaseURL string) (*store.Store, func()) {
+	dbConn, err := db.NewDB(ctx, databaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+	return store.NewStore(dbConn), func() { dbConn.Close() }
+}
+
+// scrapeFlags binds every ScrapeRequest field to a flag on fs.
+type scrapeFlags struct {
+	strategy    *string
+	maxPages    *int
+	startPage   *int
+	keywords    *string
+	cantons     *string
+	daysBack    *int
+	workloadMin *int
+	workloadMax *int
+	permanent   *string
+	polite      *bool
+	source      *string
+	urls        *string
+}
+
+func bindScrapeFlags(fs *flag.FlagSet, cfg *config.Config) *scrapeFlags {
+	defaults := models.DefaultScrapeRequest()
+	return &scrapeFlags{
+		strategy:    fs.String("strategy", defaults.Strategy, "Scrape strategy: full, incremental, sharded"),
+		maxPages:    fs.Int("max-pages", cfg.ScraperDefaultMaxPages, "Pages to scrape (0 = unlimited)"),
+		startPage:   fs.Int("start-page", defaults.StartPage, "Page to start from"),
+		keywords:    fs.String("keywords", "", "Search keywords"),
+		cantons:     fs.String("cantons", "", "Comma-separated cantons (e.g., ZH,BE,GE)"),
+		daysBack:    fs.Int("days-back", cfg.ScraperDefaultDaysBack, "Jobs published within N days"),
+		workloadMin: fs.Int("workload-min", defaults.WorkloadMin, "Minimum workload %"),
+		workloadMax: fs.Int("workload-max", defaults.WorkloadMax, "Maximum workload %"),
+		permanent:   fs.String("permanent", "", "Contract type: true=permanent, false=temporary (empty = both)"),
+		polite:      fs.Bool("polite", defaults.Polite, "Enable delays between requests"),
+		source:      fs.String("source", string(models.SourceJobRoom), "Job source: jobroom, careerpage"),
+		urls:        fs.String("urls", "", "Comma-separated career page or RSS/Atom feed URLs (careerpage source)"),
+	}
+}
+
+// request validates the parsed flags and builds a ScrapeRequest, exiting on invalid input.
+func (f *scrapeFlags) request() models.ScrapeRequest {
+	req := models.ScrapeRequest{
+		Strategy:    *f.strategy,
+		MaxPages:    *f.maxPages,
+		StartPage:   *f.startPage,
+		Keywords:    strings.TrimSpace(*f.keywords),
+		WorkloadMin: *f.workloadMin,
+		WorkloadMax: *f.workloadMax,
+		DaysBack:    *f.daysBack,
+		Polite:      *f.polite,
+		Source:      strings.ToLower(strings.TrimSpace(*f.source)),
+	}
+
+	switch req.Strategy {
+	case models.StrategyFull, models.StrategyIncremental, models.StrategySharded:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Invalid strategy '%s'. Use 'full', 'incremental' or 'sharded'.\n", req.Strategy)
+		os.Exit(1)
+	}
+
+	if *f.cantons != "" {
+		for _, code := range strings.Split(*f.cantons, ",") {
+			code = strings.ToUpper(strings.TrimSpace(code))
+			if code == "" {
+				continue
+			}
+			if !models.ValidateCanton(code) {
+				fmt.Fprintf(os.Stderr, "Error: Invalid canton code '%s'\n", code)
+				os.Exit(1)
+			}
+			req.Cantons = append(req.Cantons, code)
+		}
+	}
+
+	if *f.permanent != "" {
+		value, err := strconv.ParseBool(*f.permanent)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid --permanent value '%s'. Use 'true' or 'false'.\n", *f.permanent)
+			os.Exit(1)
+		}
+		req.Permanent = &value
+	}
+
+	for _, u := range strings.Split(*f.urls, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			req.URLs = append(req.URLs, u)
+		}
+	}
+
+	switch models.JobSource(req.Source) {
+	case models.SourceJobRoom:
+		if len(req.URLs) > 0 {
+			fmt.Fprintln(os.Stderr, "Error: --urls is only used with --source careerpage")
+			os.Exit(1)
+		}
+	case models.SourceCareerPage:
+		if len(req.URLs) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: --source careerpage requires --urls")
+			os.Exit(1)
+		}
+		if req.Strategy == models.StrategySharded {
+			fmt.Fprintln(os.Stderr, "Error: The sharded strategy only applies to the jobroom source")
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Invalid source '%s'. Use 'jobroom' or 'careerpage'.\n", req.Source)
+		os.Exit(1)
+	}
+
+	return req
+}
+
+// newClientConfig builds the scraper client configuration from the environment.
+func newClientConfig(cfg *config.Config, polite bool) scraper.ClientConfig {
+	clientCfg := scraper.DefaultClientConfig()
+	clientCfg.Polite = polite
+	clientCfg.DelayMinMs = cfg.ScraperDelayMinMs
+	clientCfg.DelayMaxMs = cfg.ScraperDelayMaxMs
+	return clientCfg
+}
+
+// newSource creates the scraper source for req, exiting on invalid configuration.
+func newSource(clientCfg scraper.ClientConfig, req *models.ScrapeRequest) scraper.Source {
+	source, err := scraper.NewSource(req, clientCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return source
+}
+
+// aiHandoffMode returns the mode stored jobs are queued for AI processing with, or "" if AI
+// processing is disabled. An unknown AI_PROCESSING_MODE is fatal rather than queuing jobs
+// the AI worker can only dead-letter.
+func aiHandoffMode(cfg *config.Config) string {
+	if !cfg.AIProcessingMode.Valid() {
+		fmt.Fprintf(os.Stderr, "Error: Unknown AI_PROCESSING_MODE '%s' (use none, process, normalize or translate)\n", cfg.AIProcessingMode)
+		os.Exit(1)
+	}
+	if !cfg.IsAIEnabled() {
+		return ""
+	}
+	return string(cfg.AIProcessingMode)
+}
+
+// metricsExport holds where a one-shot run sends its metrics when it finishes.
+type metricsExport struct {
+	pushURL  *string
+	textfile *string
+}
+
+// bindMetricsFlags registers the metrics export flags of one-shot commands.
+func bindMetricsFlags(fs *flag.FlagSet, cfg *config.Config) *metricsExport {
+	return &metricsExport{
+		pushURL:  fs.String("metrics-push", cfg.MetricsPushURL, "Push Prometheus metrics to this Pushgateway URL after the run"),
+		textfile: fs.String("metrics-textfile", cfg.MetricsTextfile, "Write Prometheus metrics to this file after the run (node_exporter textfile collector)"),
+	}
+}
+
+// export pushes and writes the run's metrics as configured. The run itself already succeeded
+// or failed, so export errors are only logged.
+func (m *metricsExport) export(ctx context.Context) {
+	if *m.pushURL != "" {
+		instance, _ := os.Hostname()
+		if err := metrics.Push(context.WithoutCancel(ctx), *m.pushURL, instance); err != nil {
+			slog.Warn("metrics export failed", "error", err)
+		}
+	}
+	if *m.textfile != "" {
+		if err := metrics.WriteTextfile(*m.textfile); err != nil {
+			slog.Warn("metrics export failed", "error", err)
+		}
+	}
+}
+
+func runScrape(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("scrape", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	workers := fs.Int("workers", cfg.ScraperWorkers, "Concurrent job detail fetches (polite delays still apply globally)")
+	record := fs.String("record", "", "Record job-room API exchanges as fixtures into this directory")
+	replay := fs.String("replay", "", "Serve job-room API responses from fixtures in this directory (offline)")
+	export := bindMetricsFlags(fs, cfg)
+	flags := bindScrapeFlags(fs, cfg)
+	fs.Parse(args)
+
+	requireDatabaseURL(*databaseURL)
+	req := flags.request()
+	clientCfg := newClientConfig(cfg, req.Polite)
+	switch {
+	case *record != "" && *replay != "":
+		fmt.Fprintln(os.Stderr, "Error: --record and --replay cannot be combined")
+		os.Exit(1)
+	case *record != "":
+		clientCfg.FixtureMode, clientCfg.FixtureDir = scraper.FixtureRecord, *record
+	case *replay != "":
+		clientCfg.FixtureMode, clientCfg.FixtureDir = scraper.FixtureReplay, *replay
+	}
+	if clientCfg.FixtureMode != "" && models.JobSource(req.Source) != models.SourceJobRoom {
+		fmt.Fprintln(os.Stderr, "Error: --record and --replay only apply to the jobroom source")
+		os.Exit(1)
+	}
+	source := newSource(clientCfg, &req)
+
+	// Cancel the run cleanly on Ctrl+C / SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	st, closeDB := openStore(ctx, *databaseURL)
+	defer closeDB()
+
+	filters, err := req.ToJSON()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to encode filters: %v\n", err)
+		os.Exit(1)
+	}
+
+	runID, err := st.CreateRun(ctx, req.Strategy, filters)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create run: %v\n", err)
+		os.Exit(1)
+	}
+
+	runner := scraper.NewRunner(st, source)
+	runner.SetWorkers(*workers)
+	runner.SetExpiry(cfg.ScraperExpireAfterRuns, cfg.ScraperExpireRecheckLimit)
+	runner.SetAIHandoff(aiHandoffMode(cfg))
+
+	result := runner.Run(ctx, req, runID)
+	printRunResult(cfg, result)
+	export.export(ctx)
+
+	if result.Status == "failed" {
+		os.Exit(1)
+	}
+}
+
+// printRunResult prints the summary of a finished scrape run.
+func printRunResult(cfg *config.Config, result scraper.RunResult) {
+	fmt.Printf("\nRun #%d %s\n", result.RunID, result.Status)
+	fmt.Printf("  Pages scraped:  %d\n", result.PagesScraped)
+	fmt.Printf("  Jobs processed: %d\n", result.JobsProcessed)
+	fmt.Printf("  Jobs inserted:  %d\n", result.JobsInserted)
+	fmt.Printf("  Jobs updated:   %d\n", result.JobsUpdated)
+	fmt.Printf("  Jobs skipped:   %d\n", result.JobsSkipped)
+	if result.JobsExpired > 0 {
+		fmt.Printf("  Jobs expired:   %d\n", result.JobsExpired)
+	}
+	if len(result.Shards) > 0 {
+		capped := 0
+		for _, shard := range result.Shards {
+			if shard.Status == "capped" {
+				capped++
+			}
+		}
+		fmt.Printf("  Shards:         %d (%d still capped)\n", len(result.Shards), capped)
+	}
+	if cfg.IsAIEnabled() {
+		fmt.Printf("  AI queued:      %d (already queued %d, failed %d)\n", result.AIJobsQueued, result.AIJobsSkipped, result.AIJobsFailed)
+	}
+	if result.StopReason != "" {
+		fmt.Printf("  Stop reason:    %s\n", result.StopReason)
+	}
+	if len(result.Errors) > 0 {
+		fmt.Printf("  Errors:         %d\n", len(result.Errors))
+	}
+	// Sharded runs do not checkpoint, so only plain runs can be resumed. A resume after the
+	// 412 would start at the refused page and hit the same result cap again.
+	switch {
+	case len(result.Shards) > 0:
+	case result.Status != "completed":
+		fmt.Printf("  Resume with:    scrapper resume %d\n", result.RunID)
+	case result.StopReason == "API limit reached (412)":
+		fmt.Printf("  Result cap hit: rerun with --strategy sharded to split the search under the cap\n")
+	}
+}
+
+func runResume(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("resume", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	force := fs.Bool("force", false, "Resume even if the run is still marked as running")
+	workers := fs.Int("workers", cfg.ScraperWorkers, "Concurrent job detail fetches (polite delays still apply globally)")
+	export := bindMetricsFlags(fs, cfg)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: scrapper resume [options] <run_id>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	requireDatabaseURL(*databaseURL)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	runID, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid run ID '%s'\n", fs.Arg(0))
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	st, closeDB := openStore(ctx, *databaseURL)
+	defer closeDB()
+
+	parent, err := st.GetRun(ctx, runID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if parent.Status == "running" && !*force {
+		fmt.Fprintf(os.Stderr, "Error: Run #%d is still marked as running. Use --force if it crashed.\n", parent.ID)
+		os.Exit(1)
+	}
+
+	req, done, err := parent.ResumeRequest()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot resume: %v\n", err)
+		os.Exit(1)
+	}
+	if done {
+		fmt.Printf("Run #%d already reached its page limit, nothing to resume\n", parent.ID)
+		return
+	}
+	source := newSource(newClientConfig(cfg, req.Polite), &req)
+
+	filters, err := req.ToJSON()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to encode filters: %v\n", err)
+		os.Exit(1)
+	}
+
+	newRunID, err := st.CreateResumedRun(ctx, parent, filters)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create run: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Resuming run #%d from page %d as run #%d\n", parent.ID, req.StartPage, newRunID)
+
+	runner := scraper.NewRunner(st, source)
+	runner.SetWorkers(*workers)
+	runner.SetExpiry(cfg.ScraperExpireAfterRuns, cfg.ScraperExpireRecheckLimit)
+	runner.SetAIHandoff(aiHandoffMode(cfg))
+
+	result := runner.Run(ctx, req, newRunID)
+	printRunResult(cfg, result)
+	export.export(ctx)
+
+	if result.Status == "failed" {
+		os.Exit(1)
+	}
+}
+
+func runServe(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	metricsAddr := fs.String("metrics-addr", cfg.MetricsAddr, "Serve Prometheus metrics on this address, e.g. :9090 (empty = disabled)")
+	fs.Parse(args)
+
+	requireDatabaseURL(*databaseURL)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *metricsAddr != "" {
+		if err := metrics.Listen(ctx, *metricsAddr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	st, closeDB := openStore(ctx, *databaseURL)
+	defer closeDB()
+
+	sched := scheduler.New(st, scheduler.Config{
+		ClientConfig: newClientConfig(cfg, true),
+		AIMode:       aiHandoffMode(cfg),
+		Workers:      cfg.ScraperWorkers,
+		ExpireAfter:  cfg.ScraperExpireAfterRuns,
+		RecheckLimit: cfg.ScraperExpireRecheckLimit,
+		StaleAfter:   time.Duration(cfg.ScraperStaleRunMinutes) * time.Minute,
+	})
+
+	slog.Info("Starting scrapper daemon", "version", version)
+	if err := sched.Run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runProfiles(cfg *config.Config, args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: scrapper profiles <list|add|remove> [options]")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		runProfilesList(cfg, args[1:])
+	case "add":
+		runProfilesAdd(cfg, args[1:])
+	case "remove":
+		runProfilesRemove(cfg, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown profiles command '%s'. Use list, add or remove.\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func runProfilesList(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("profiles list", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	fs.Parse(args)
+
+	requireDatabaseURL(*databaseURL)
+
+	ctx := context.Background()
+	st, closeDB := openStore(ctx, *databaseURL)
+	defer closeDB()
+
+	profiles, err := st.ListProfiles(ctx, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		printJSON(profiles)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSCHEDULE\tENABLED\tREQUEST")
+	for _, p := range profiles {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", p.Name, p.Schedule, p.Enabled, p.Request)
+	}
+	w.Flush()
+}
+
+func runProfilesAdd(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("profiles add", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	name := fs.String("name", "", "Profile name (required, unique)")
+	schedule := fs.String("schedule", "", "Cron expression or descriptor, e.g. '*/30 * * * *' or '@weekly' (required)")
+	disabled := fs.Bool("disabled", false, "Create the profile disabled")
+	flags := bindScrapeFlags(fs, cfg)
+	fs.Parse(args)
+
+	requireDatabaseURL(*databaseURL)
+
+	if *name == "" || *schedule == "" {
+		fmt.Fprintln(os.Stderr, "Error: --name and --schedule are required")
+		os.Exit(1)
+	}
+	if err := scheduler.ValidateSchedule(*schedule); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	req := flags.request()
+	requestJSON, err := req.ToJSON()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to encode request: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	st, closeDB := openStore(ctx, *databaseURL)
+	defer closeDB()
+
+	if _, err := st.UpsertProfile(ctx, *name, *schedule, requestJSON, !*disabled); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Profile '%s' saved (%s)\n", *name, *schedule)
+}
+
+func runProfilesRemove(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("profiles remove", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	name := fs.String("name", "", "Profile name (required)")
+	fs.Parse(args)
+
+	requireDatabaseURL(*databaseURL)
+
+	if *name == "" {
+		fmt.Fprintln(os.Stderr, "Error: --name is required")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	st, closeDB := openStore(ctx, *databaseURL)
+	defer closeDB()
+
+	if err := st.DeleteProfile(ctx, *name); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Profile '%s' removed\n", *name)
+}
+
+func runJobs(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("jobs", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	limit := fs.Int("limit", 20, "Number of jobs to list")
+	offset := fs.Int("offset", 0, "Pagination offset")
+	collapse := fs.Bool("collapse", false, "List only the canonical job of each duplicate cluster")
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	fs.Parse(args)
+
+	requireDatabaseURL(*databaseURL)
+
+	ctx := context.Background()
+	st, closeDB := openStore(ctx, *databaseURL)
+	defer closeDB()
+
+	jobs, err := st.ListJobs(ctx, *limit, *offset, *collapse)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		printJSON(jobs)
+		return
+	}
+
+	total, err := st.CountJobs(ctx, *collapse)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tSOURCE\tCREATED\tUPDATED\tDUPLICATES")
+	for _, job := range jobs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n", job.ID, job.Status, job.Source, job.CreatedTime, job.UpdatedTime, job.Duplicates)
+	}
+	w.Flush()
+
+	fmt.Printf("\nShowing %d of %d jobs (offset %d)\n", len(jobs), total, *offset)
+}
+
+func runRevisions(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("revisions", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: scrapper revisions [options] <job_id>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	requireDatabaseURL(*databaseURL)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	jobID := fs.Arg(0)
+
+	ctx := context.Background()
+	st, closeDB := openStore(ctx, *databaseURL)
+	defer closeDB()
+
+	revisions, err := st.ListJobRevisions(ctx, jobID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		printJSON(revisions)
+		return
+	}
+
+	if len(revisions) == 0 {
+		fmt.Printf("No revisions recorded for job %s\n", jobID)
+		return
+	}
+
+	for _, rev := range revisions {
+		changes, err := rev.FieldChanges()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Revision %d has invalid changes: %v\n", rev.ID, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%s -> %s (%d changes)\n",
+			rev.PreviousUpdatedTime.Format(time.RFC3339), rev.UpdatedTime.Format(time.RFC3339), len(changes))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, change := range changes {
+			fmt.Fprintf(w, "  %s\t%s\t->\t%s\n", change.Field, formatValue(change.Old), formatValue(change.New))
+		}
+		w.Flush()
+		fmt.Println()
+	}
+}
+
+func runDuplicates(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("duplicates", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: scrapper duplicates [options] <job_id>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	requireDatabaseURL(*databaseURL)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	jobID := fs.Arg(0)
+
+	ctx := context.Background()
+	st, closeDB := openStore(ctx, *databaseURL)
+	defer closeDB()
+
+	cluster, members, err := st.GetJobCluster(ctx, jobID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if cluster == nil {
+		fmt.Printf("Job %s is not clustered (run 'scrapper dedupe' to cluster older jobs)\n", jobID)
+		return
+	}
+
+	if *asJSON {
+		printJSON(map[string]interface{}{"cluster": cluster, "members": members})
+		return
+	}
+
+	canonical := ""
+	if cluster.CanonicalJobID != nil {
+		canonical = *cluster.CanonicalJobID
+	}
+	fmt.Printf("Cluster %d (%d jobs)\n", cluster.ID, cluster.Size)
+	for _, id := range members {
+		marker := ""
+		if id == canonical {
+			marker = " (canonical)"
+		}
+		fmt.Printf("  %s%s\n", id, marker)
+	}
+}
+
+func runDedupe(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("dedupe", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	batch := fs.Int("batch", 500, "Jobs loaded per query")
+	fs.Parse(args)
+
+	requireDatabaseURL(*databaseURL)
+
+	if *batch < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --batch must be at least 1")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	st, closeDB := openStore(ctx, *databaseURL)
+	defer closeDB()
+
+	n, err := st.BackfillClusters(ctx, *batch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v (%d jobs clustered before the failure)\n", err, n)
+		os.Exit(1)
+	}
+	fmt.Printf("Clustered %d jobs\n", n)
+}
+
+// formatValue renders a changed field value on a single line, truncating long text.
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "-"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return truncate(string(data), 60)
+}
+
+// truncate shortens s to at most maxLen runes, marking the cut with "...".
+func truncate(s string, maxLen int) string {
+	if runes := []rune(s); len(runes) > maxLen {
+		return string(runes[:maxLen-3]) + "..."
+	}
+	return s
+}
+
+func runRuns(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("runs", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	limit := fs.Int("limit", 20, "Number of runs to list, or timeline events to show for a run (0 = all)")
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: scrapper runs [options] [run_id]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	requireDatabaseURL(*databaseURL)
+
+	ctx := context.Background()
+	st, closeDB := openStore(ctx, *databaseURL)
+	defer closeDB()
+
+	if fs.NArg() > 0 {
+		runID, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid run ID '%s'\n", fs.Arg(0))
+			os.Exit(1)
+		}
+		showRun(ctx, st, runID, *limit, *asJSON)
+		return
+	}
+
+	runs, err := st.ListRuns(ctx, *limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		printJSON(runs)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTRATEGY\tSTATUS\tSTARTED\tDURATION\tPAGES\tPROCESSED\tINSERTED\tUPDATED\tSKIPPED\tEXPIRED\tCHECKPOINT")
+	for _, run := range runs {
+		duration := "-"
+		if run.EndTime != nil {
+			duration = run.EndTime.Sub(run.StartTime).Round(time.Second).String()
+		}
+		checkpoint := "-"
+		if run.CheckpointPage != nil {
+			checkpoint = "page " + strconv.Itoa(*run.CheckpointPage)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			run.ID, run.Strategy, run.Status, run.StartTime.Format(time.RFC3339), duration,
+			run.PagesScraped, run.JobsProcessed, run.JobsInserted, run.JobsUpdated, run.JobsSkipped, run.JobsExpired, checkpoint)
+	}
+	w.Flush()
+}
+
+// showRun prints a run's counters, its errors by category and its event timeline.
+func showRun(ctx context.Context, st *store.Store, runID int64, limit int, asJSON bool) {
+	run, err := st.GetRun(ctx, runID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	counts, err := st.CountRunEvents(ctx, runID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	events, err := st.ListRunEvents(ctx, runID, limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if asJSON {
+		printJSON(map[string]interface{}{"run": run, "event_counts": counts, "events": events})
+		return
+	}
+
+	duration := "-"
+	if run.EndTime != nil {
+		duration = run.EndTime.Sub(run.StartTime).Round(time.Second).String()
+	}
+	fmt.Printf("Run #%d %s (%s, started %s, took %s)\n",
+		run.ID, run.Status, run.Strategy, run.StartTime.Format(time.RFC3339), duration)
+	fmt.Printf("  Pages:  %d scraped, %d failed\n", run.PagesScraped, run.PageErrors)
+	fmt.Printf("  Jobs:   %d processed, %d inserted, %d updated, %d skipped, %d expired\n",
+		run.JobsProcessed, run.JobsInserted, run.JobsUpdated, run.JobsSkipped, run.JobsExpired)
+	fmt.Printf("  AI:     %d queued, %d already queued, %d failed\n", run.AIJobsQueued, run.AIJobsSkipped, run.AIJobsFailed)
+
+	fmt.Println("\nErrors by category:")
+	failures := 0
+	for _, c := range counts {
+		if c.Type.IsFailure() {
+			fmt.Printf("  %-14s %d\n", c.Type, c.Count)
+			failures += c.Count
+		}
+	}
+	if failures == 0 {
+		fmt.Println("  none")
+	}
+
+	if len(events) == 0 {
+		fmt.Println("\nNo events recorded for this run")
+		return
+	}
+
+	fmt.Println("\nTimeline:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TIME\tEVENT\tPAGE\tJOB\tDURATION\tMESSAGE")
+	for _, e := range events {
+		page, jobID, took, message := "-", "-", "-", ""
+		if e.Page != nil {
+			page = strconv.Itoa(*e.Page)
+		}
+		if e.JobID != nil {
+			jobID = *e.JobID
+		}
+		if e.DurationMs != nil {
+			took = (time.Duration(*e.DurationMs) * time.Millisecond).String()
+		}
+		if e.Message != nil {
+			message = truncate(strings.Join(strings.Fields(*e.Message), " "), 80)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\n",
+			e.CreatedAt.Format("15:04:05.000"), e.Type, page, jobID, took, message)
+	}
+	w.Flush()
+
+	if total := sumEventCounts(counts); limit > 0 && total > len(events) {
+		fmt.Printf("\nShowing %d of %d events (use --limit 0 for all)\n", len(events), total)
+	}
+}
+
+// sumEventCounts returns the total number of events.
+func sumEventCounts(counts []models.RunEventCount) int {
+	total := 0
+	for _, c := range counts {
+		total += c.Count
+	}
+	return total
+}
+
+func runMigrate(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	databaseURL := fs.String("database", cfg.DatabaseURL, "PostgreSQL connection string")
+	direction := fs.String("direction", "up", "Migration direction: up, down")
+	steps := fs.Int("steps", 0, "Number of migrations to run (0 = all)")
+	force := fs.Int("force", -1, "Force migration version (for recovery)")
+	fs.Parse(args)
+
+	if *direction != "up" && *direction != "down" {
+		fmt.Fprintf(os.Stderr, "Error: Invalid direction '%s'. Use 'up' or 'down'.\n", *direction)
+		os.Exit(1)
+	}
+
+	requireDatabaseURL(*databaseURL)
+
+	fmt.Printf("Running migrations (%s)...\n", *direction)
+
+	migrator, err := db.NewMigrator(*databaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to create migrator: %v\n", err)
+		os.Exit(1)
+	}
+	defer migrator.Close()
+
+	// Force version if specified
+	if *force >= 0 {
+		fmt.Printf("Forcing migration version to %d...\n", *force)
+		if err := migrator.Force(*force); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to force migration version: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Migration version forced successfully.")
+		return
+	}
+
+	// Run migrations
+	if *steps != 0 {
+		n := *steps
+		if *direction == "down" {
+			n = -n
+		}
+		if err := migrator.Steps(n); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Migration failed: %v\n", err)
+			os.Exit(1)
+		}
+	} else if *direction == "down" {
+		if err := migrator.Down(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Migration down failed: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		if err := migrator.Up(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Migration up failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Show current version
+	ver, dirty, err := migrator.Version()
+	if err != nil {
+		fmt.Printf("Migrations applied successfully.\n")
+	} else {
+		dirtyStr := ""
+		if dirty {
+			dirtyStr = " (dirty)"
+		}
+		fmt.Printf("Migrations applied successfully. Current version: %d%s\n", ver, dirtyStr)
+	}
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to encode JSON: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -9,7 +9,7 @@ import (
 type AIProcessingMode string
 
 const (
-	AIProcessingModeNone      AIProcessingMode = "none"      // Don't call AI service
+	AIProcessingModeNone      AIProcessingMode = "none"      // Don't queue jobs for the AI service
 	AIProcessingModeProcess   AIProcessingMode = "process"   // Normalize + translate
 	AIProcessingModeNormalize AIProcessingMode = "normalize" // Normalize only
 	AIProcessingModeTranslate AIProcessingMode = "translate" // Translate only
@@ -29,30 +29,57 @@ type Config struct {
 	ScraperDelayMaxMs      int
 	ScraperDefaultMaxPages int
 	ScraperDefaultDaysBack int
+	ScraperWorkers         int // Concurrent detail fetch workers per run
+
+	// Expiry reconciliation
+	ScraperExpireAfterRuns    int // Consecutive complete runs a job may be missing before it is expired (0 = disabled)
+	ScraperExpireRecheckLimit int // Suspect jobs re-fetched individually after each reconciliation
+
+	// Scheduler (serve mode)
+	ScraperStaleRunMinutes int // Runs left "running" longer than this no longer block their profile
+
+	// Metrics
+	MetricsAddr     string // Listen address of the /metrics endpoint in serve mode (empty = disabled)
+	MetricsPushURL  string // Pushgateway URL one-shot runs push their metrics to (empty = disabled)
+	MetricsTextfile string // File one-shot runs write their metrics to, for the node_exporter textfile collector
 
 	// AI Processing Service Integration
-	AIServiceURL     string           // URL of the ai_job_processing microservice
-	AIProcessingMode AIProcessingMode // How to process jobs: none, process, normalize, translate
+	AIProcessingMode AIProcessingMode // How queued jobs are processed: none, process, normalize, translate
 }
 
 // Load loads configuration from environment variables.
 func Load() *Config {
 	return &Config{
-		DatabaseURL:            GetEnv("DATABASE_URL", ""),
-		LogLevel:               GetEnv("LOG_LEVEL", "INFO"),
-		LogFormat:              GetEnv("LOG_FORMAT", "text"),
-		ScraperDelayMinMs:      GetEnvInt("SCRAPER_DELAY_MIN_MS", 2000),
-		ScraperDelayMaxMs:      GetEnvInt("SCRAPER_DELAY_MAX_MS", 5000),
-		ScraperDefaultMaxPages: GetEnvInt("SCRAPER_DEFAULT_MAX_PAGES", 0),
-		ScraperDefaultDaysBack: GetEnvInt("SCRAPER_DEFAULT_DAYS_BACK", 60),
-		AIServiceURL:           GetEnv("AI_SERVICE_URL", ""),
-		AIProcessingMode:       AIProcessingMode(GetEnv("AI_PROCESSING_MODE", "none")),
+		DatabaseURL:               GetEnv("DATABASE_URL", ""),
+		LogLevel:                  GetEnv("LOG_LEVEL", "INFO"),
+		LogFormat:                 GetEnv("LOG_FORMAT", "text"),
+		ScraperDelayMinMs:         GetEnvInt("SCRAPER_DELAY_MIN_MS", 2000),
+		ScraperDelayMaxMs:         GetEnvInt("SCRAPER_DELAY_MAX_MS", 5000),
+		ScraperDefaultMaxPages:    GetEnvInt("SCRAPER_DEFAULT_MAX_PAGES", 0),
+		ScraperDefaultDaysBack:    GetEnvInt("SCRAPER_DEFAULT_DAYS_BACK", 60),
+		ScraperWorkers:            GetEnvInt("SCRAPER_WORKERS", 1),
+		ScraperStaleRunMinutes:    GetEnvInt("SCRAPER_STALE_RUN_MINUTES", 360),
+		ScraperExpireAfterRuns:    GetEnvInt("SCRAPER_EXPIRE_AFTER_RUNS", 3),
+		ScraperExpireRecheckLimit: GetEnvInt("SCRAPER_EXPIRE_RECHECK_LIMIT", 20),
+		MetricsAddr:               GetEnv("METRICS_ADDR", ""),
+		MetricsPushURL:            GetEnv("METRICS_PUSH_URL", ""),
+		MetricsTextfile:           GetEnv("METRICS_TEXTFILE", ""),
+		AIProcessingMode:          AIProcessingMode(GetEnv("AI_PROCESSING_MODE", "none")),
 	}
 }
 
-// IsAIEnabled returns true if AI processing is configured.
+// IsAIEnabled returns true if stored jobs are queued for AI processing.
 func (c *Config) IsAIEnabled() bool {
-	return c.AIServiceURL != "" && c.AIProcessingMode != AIProcessingModeNone
+	return c.AIProcessingMode != "" && c.AIProcessingMode != AIProcessingModeNone
+}
+
+// Valid reports whether m is a known processing mode.
+func (m AIProcessingMode) Valid() bool {
+	switch m {
+	case AIProcessingModeNone, AIProcessingModeProcess, AIProcessingModeNormalize, AIProcessingModeTranslate:
+		return true
+	}
+	return false
 }
 
 // GetEnv returns the value of an environment variable or a default value.
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// PushJobName is the job label of metrics pushed to a Pushgateway.
+const PushJobName = "scrapper"
+
+// Registry holds the scraper metrics. It is separate from the default registry so one-shot
+// runs push and write only scraper metrics, not Go runtime metrics of a short-lived process.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	// PagesFetched counts listing pages fetched successfully.
+	PagesFetched = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "scrapper",
+		Name:      "pages_fetched_total",
+		Help:      "Listing pages fetched successfully.",
+	}, []string{"source"})
+
+	// PageErrors counts listing pages that could not be fetched, excluding the 412 result cap.
+	PageErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "scrapper",
+		Name:      "page_errors_total",
+		Help:      "Listing pages that could not be fetched.",
+	}, []string{"source"})
+
+	// Jobs counts listed jobs by outcome: inserted, updated or skipped.
+	Jobs = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "scrapper",
+		Name:      "jobs_total",
+		Help:      "Listed jobs by outcome (inserted, updated, skipped).",
+	}, []string{"source", "outcome"})
+
+	// HTTPRequests counts HTTP attempts by endpoint and status code ("error" for transport failures).
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "scrapper",
+		Name:      "http_requests_total",
+		Help:      "HTTP request attempts by endpoint and status code.",
+	}, []string{"endpoint", "code"})
+
+	// HTTPRequestDuration observes the latency of HTTP attempts by endpoint.
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "scrapper",
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP request attempts by endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	// HTTPRetries counts retried HTTP requests by endpoint.
+	HTTPRetries = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "scrapper",
+		Name:      "http_retries_total",
+		Help:      "HTTP requests retried after a transport error, 429 or 5xx.",
+	}, []string{"endpoint"})
+
+	// AIHandoffs counts stored jobs handed to the AI queue by outcome: queued, skipped
+	// (already waiting in the queue) or failed.
+	AIHandoffs = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "scrapper",
+		Name:      "ai_handoffs_total",
+		Help:      "Stored jobs handed to the AI queue by outcome (queued, skipped, failed).",
+	}, []string{"outcome"})
+
+	// RunDuration observes the wall time of scrape runs.
+	RunDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "scrapper",
+		Name:      "run_duration_seconds",
+		Help:      "Wall time of scrape runs by source, strategy and final status.",
+		Buckets:   []float64{10, 30, 60, 300, 600, 1800, 3600, 7200, 14400},
+	}, []string{"source", "strategy", "status"})
+
+	// LastRun records when a run last finished with each status, for staleness alerts.
+	LastRun = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "scrapper",
+		Name:      "last_run_timestamp_seconds",
+		Help:      "Unix time a run last finished, by source, strategy and final status.",
+	}, []string{"source", "strategy", "status"})
+)
+
+// Handler serves the scraper metrics together with the Go runtime and process metrics.
+func Handler() http.Handler {
+	gatherers := prometheus.Gatherers{Registry, prometheus.DefaultGatherer}
+	return promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})
+}
+
+// Listen exposes /metrics on addr in the background until ctx is cancelled.
+// It returns once the listener is bound, so a busy port is reported to the caller.
+func Listen(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics listener failed", "addr", addr, "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("failed to stop metrics listener", "error", err)
+		}
+	}()
+
+	slog.Info("metrics listener started", "addr", ln.Addr().String())
+	return nil
+}
+
+// Push replaces the metrics of this instance on a Pushgateway-compatible endpoint.
+// Grouping by instance keeps pushes from different hosts from overwriting each other.
+func Push(ctx context.Context, url, instance string) error {
+	pusher := push.New(url, PushJobName).Gatherer(Registry)
+	if instance != "" {
+		pusher = pusher.Grouping("instance", instance)
+	}
+	if err := pusher.PushContext(ctx); err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	return nil
+}
+
+// WriteTextfile writes the metrics in text format for the node_exporter textfile collector.
+// The file is replaced atomically, so the collector never reads a partial file.
+func WriteTextfile(path string) error {
+	if err := prometheus.WriteToTextfile(path, Registry); err != nil {
+		return fmt.Errorf("failed to write metrics textfile: %w", err)
+	}
+	return nil
+}
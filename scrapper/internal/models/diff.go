@@ -0,0 +1,174 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// FieldChange is a single changed field between two versions of a job.
+// Field is a dotted JSON path such as "jobContent.employment.workloadPercentageMax" or
+// "jobContent.jobDescriptions[de].title"; Old or New is nil when the field was added or removed.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// JobRevision records what changed in a job between two updatedTime values.
+type JobRevision struct {
+	ID                  int64     `json:"id" db:"id"`
+	JobID               string    `json:"job_id" db:"job_id"`
+	PreviousUpdatedTime time.Time `json:"previous_updated_time" db:"previous_updated_time"`
+	UpdatedTime         time.Time `json:"updated_time" db:"updated_time"`
+	Changes             string    `json:"changes" db:"changes"` // JSON-encoded []FieldChange
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+}
+
+// FieldChanges decodes the revision's stored changes.
+func (r *JobRevision) FieldChanges() ([]FieldChange, error) {
+	var changes []FieldChange
+	if err := json.Unmarshal([]byte(r.Changes), &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// diffIgnoredFields are bookkeeping fields that change on every update.
+var diffIgnoredFields = map[string]bool{
+	"updatedTime": true,
+}
+
+// diffListKeys identifies list elements by a field instead of their position,
+// so reordering a list does not show up as a change.
+var diffListKeys = map[string]string{
+	"jobContent.jobDescriptions": "languageIsoCode",
+	"jobContent.occupations":     "avamOccupationCode",
+}
+
+// DiffJobs returns the fields that differ between two versions of a job, sorted by path.
+func DiffJobs(prev, next *JobDetail) ([]FieldChange, error) {
+	prevTree, err := jsonTree(prev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode previous job: %w", err)
+	}
+	nextTree, err := jsonTree(next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode new job: %w", err)
+	}
+
+	var changes []FieldChange
+	diffValues("", prevTree, nextTree, &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// RevisionChanges returns the changes to record as a revision when prev is replaced by next,
+// or none if next carries the same updatedTime or only bookkeeping fields changed.
+func RevisionChanges(prev, next *JobDetail) ([]FieldChange, error) {
+	if prev.UpdatedTime == next.UpdatedTime {
+		return nil, nil
+	}
+	return DiffJobs(prev, next)
+}
+
+// jsonTree converts v into its generic JSON representation.
+func jsonTree(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// diffValues appends the differences between a and b at path to changes.
+func diffValues(path string, a, b interface{}, changes *[]FieldChange) {
+	if diffIgnoredFields[path] {
+		return
+	}
+
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			diffObjects(path, av, bv, changes)
+			return
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			diffLists(path, av, bv, changes)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, FieldChange{Field: path, Old: a, New: b})
+	}
+}
+
+func diffObjects(path string, a, b map[string]interface{}, changes *[]FieldChange) {
+	for key, av := range a {
+		diffValues(joinPath(path, key), av, b[key], changes)
+	}
+	for key, bv := range b {
+		if _, ok := a[key]; !ok {
+			diffValues(joinPath(path, key), nil, bv, changes)
+		}
+	}
+}
+
+func diffLists(path string, a, b []interface{}, changes *[]FieldChange) {
+	keyField, keyed := diffListKeys[path]
+	if !keyed {
+		for i := 0; i < max(len(a), len(b)); i++ {
+			var av, bv interface{}
+			if i < len(a) {
+				av = a[i]
+			}
+			if i < len(b) {
+				bv = b[i]
+			}
+			diffValues(path+"["+strconv.Itoa(i)+"]", av, bv, changes)
+		}
+		return
+	}
+
+	aByKey := indexList(a, keyField)
+	bByKey := indexList(b, keyField)
+	for key, av := range aByKey {
+		diffValues(path+"["+key+"]", av, bByKey[key], changes)
+	}
+	for key, bv := range bByKey {
+		if _, ok := aByKey[key]; !ok {
+			diffValues(path+"["+key+"]", nil, bv, changes)
+		}
+	}
+}
+
+// indexList maps list elements by the string value of keyField, falling back to the position.
+func indexList(list []interface{}, keyField string) map[string]interface{} {
+	indexed := make(map[string]interface{}, len(list))
+	for i, item := range list {
+		key := strconv.Itoa(i)
+		if obj, ok := item.(map[string]interface{}); ok {
+			if k, ok := obj[keyField].(string); ok && k != "" {
+				key = k
+			}
+		}
+		indexed[key] = item
+	}
+	return indexed
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
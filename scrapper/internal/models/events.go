@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// RunEventType is the kind of a scrape run event.
+type RunEventType string
+
+const (
+	EventPageFetched  RunEventType = "page_fetched"  // A listing page was fetched
+	EventPageFailed   RunEventType = "page_failed"   // A listing page could not be fetched (incl. 412)
+	EventJobInserted  RunEventType = "job_inserted"  // A new job was stored
+	EventJobUpdated   RunEventType = "job_updated"   // An existing job was stored again
+	EventDetailFailed RunEventType = "detail_failed" // A job detail could not be fetched
+	EventStoreFailed  RunEventType = "store_failed"  // A job could not be checked or stored
+	EventAIFailed     RunEventType = "ai_failed"     // Stored jobs could not be handed to the AI queue
+	EventStopped      RunEventType = "stopped"       // The run finished; the message holds the stop reason
+)
+
+// IsFailure reports whether the event type records an error.
+func (t RunEventType) IsFailure() bool {
+	return strings.HasSuffix(string(t), "_failed")
+}
+
+// RunEvent is one entry of a scrape run's timeline.
+type RunEvent struct {
+	ID         int64        `json:"id" db:"id"`
+	RunID      int64        `json:"run_id" db:"run_id"`
+	Type       RunEventType `json:"type" db:"type"`
+	Page       *int         `json:"page,omitempty" db:"page"`
+	JobID      *string      `json:"job_id,omitempty" db:"job_id"`
+	DurationMs *int64       `json:"duration_ms,omitempty" db:"duration_ms"` // Time spent on the fetch or store
+	Message    *string      `json:"message,omitempty" db:"message"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+}
+
+// NewRunEvent creates an event timestamped now. A negative page, empty jobID or message
+// and zero duration are left unset.
+func NewRunEvent(runID int64, typ RunEventType, page int, jobID string, took time.Duration, message string) RunEvent {
+	event := RunEvent{RunID: runID, Type: typ, CreatedAt: time.Now()}
+	if page >= 0 {
+		event.Page = &page
+	}
+	if jobID != "" {
+		event.JobID = &jobID
+	}
+	if took > 0 {
+		ms := took.Milliseconds()
+		event.DurationMs = &ms
+	}
+	if message != "" {
+		event.Message = &message
+	}
+	return event
+}
+
+// RunEventCount is the number of events of one type in a run.
+type RunEventCount struct {
+	Type  RunEventType `json:"type" db:"type"`
+	Count int          `json:"count" db:"count"`
+}
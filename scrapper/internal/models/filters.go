@@ -1,6 +1,8 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/url"
 	"strconv"
@@ -8,9 +10,17 @@ import (
 	"time"
 )
 
+// Scrape strategies.
+const (
+	StrategyFull        = "full"        // Scrape all pages up to MaxPages
+	StrategyIncremental = "incremental" // Stop at the first unchanged job
+	StrategySharded     = "sharded"     // Split into sub-queries that fit under the API result cap
+)
+
 // ScrapeRequest contains all filtering options for job scraping.
 type ScrapeRequest struct {
-	// Strategy: "full" scrapes all pages, "incremental" stops when existing jobs found
+	// Strategy: "full" scrapes all pages, "incremental" stops when existing jobs found,
+	// "sharded" splits the query until each part fits under the API result cap
 	Strategy string `json:"strategy"`
 
 	// MaxPages is the maximum number of pages to scrape (0 = unlimited)
@@ -39,6 +49,12 @@ type ScrapeRequest struct {
 
 	// Polite enables random delays between requests
 	Polite bool `json:"polite"`
+
+	// Source selects the job board: "jobroom" (default) or "careerpage"
+	Source string `json:"source,omitempty"`
+
+	// URLs lists the career pages or RSS/Atom feeds to ingest (careerpage source only)
+	URLs []string `json:"urls,omitempty"`
 }
 
 // ToJSON converts the request to JSON for storage.
@@ -50,6 +66,19 @@ func (r *ScrapeRequest) ToJSON() (string, error) {
 	return string(data), nil
 }
 
+// FiltersHash returns a stable hash of the search filters.
+// Pagination and politeness are excluded so a resumed run hashes the same as the run it continues.
+func (r *ScrapeRequest) FiltersHash() string {
+	filters := *r
+	filters.MaxPages = 0
+	filters.StartPage = 0
+	filters.Polite = false
+
+	data, _ := json.Marshal(filters)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // BuildQuery constructs URL query parameters from the ScrapeRequest.
 func (r *ScrapeRequest) BuildQuery(page int) url.Values {
 	q := url.Values{}
@@ -163,6 +192,72 @@ func (r *ScrapeRequest) BuildSearchBody() SearchRequestBody {
 	}
 }
 
+// MinWorkloadBand is the narrowest workload range Split will produce.
+const MinWorkloadBand = 10
+
+// Split divides the request into narrower sub-requests that together cover the same jobs.
+// Dimensions are tried in order: canton (via AllSwissCantons when none is set), contract type,
+// then workload band. Returns nil when the request cannot be narrowed further.
+//
+// The search API only accepts an upper age bound (onlineSince), so the publication date
+// cannot be split into disjoint windows and is left unchanged.
+func (r *ScrapeRequest) Split() []ScrapeRequest {
+	if len(r.Cantons) != 1 {
+		cantons := r.Cantons
+		if len(cantons) == 0 {
+			cantons = AllSwissCantons()
+		}
+		shards := make([]ScrapeRequest, 0, len(cantons))
+		for _, canton := range cantons {
+			shard := *r
+			shard.Cantons = []string{canton}
+			shards = append(shards, shard)
+		}
+		return shards
+	}
+
+	if r.Permanent == nil {
+		permanent, temporary := true, false
+		shardA, shardB := *r, *r
+		shardA.Permanent = &permanent
+		shardB.Permanent = &temporary
+		return []ScrapeRequest{shardA, shardB}
+	}
+
+	body := r.BuildSearchBody()
+	workloadMin, workloadMax := body.WorkloadPercentageMin, body.WorkloadPercentageMax
+	if workloadMax-workloadMin > MinWorkloadBand {
+		// Bands share their boundary so jobs on the edge are not lost; duplicates are removed by ID
+		mid := (workloadMin + workloadMax) / 2 / MinWorkloadBand * MinWorkloadBand
+		if mid <= workloadMin {
+			mid = workloadMin + MinWorkloadBand
+		}
+		lower, upper := *r, *r
+		lower.WorkloadMin, lower.WorkloadMax = workloadMin, mid
+		upper.WorkloadMin, upper.WorkloadMax = mid, workloadMax
+		return []ScrapeRequest{lower, upper}
+	}
+
+	return nil
+}
+
+// ShardKey returns a short human-readable description of the request's partition.
+func (r *ScrapeRequest) ShardKey() string {
+	body := r.BuildSearchBody()
+	key := strings.Join(r.Cantons, ",")
+	if key == "" {
+		key = "all"
+	}
+	if r.Permanent != nil {
+		if *r.Permanent {
+			key += "/permanent"
+		} else {
+			key += "/temporary"
+		}
+	}
+	return key + "/" + strconv.Itoa(body.WorkloadPercentageMin) + "-" + strconv.Itoa(body.WorkloadPercentageMax) + "%"
+}
+
 // AllSwissCantons returns all 26 Swiss canton codes.
 func AllSwissCantons() []string {
 	return []string{
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxSimhashDistance is the largest number of differing description simhash bits
+// for two jobs with the same fingerprint key to be treated as the same vacancy.
+// Unrelated texts differ in about 32 of 64 bits; a reworded or extended posting in far fewer.
+const MaxSimhashDistance = 12
+
+// shingleSize is the number of consecutive words per description shingle.
+const shingleSize = 3
+
+var (
+	// titleNoisePattern matches gender markers and workload percentages such as "(m/w/d)" or "80-100%".
+	titleNoisePattern = regexp.MustCompile(`\((?:[mwfdhx]\s*/\s*)+[mwfdhx]\)|\b\d{1,3}\s*(?:-|–|bis)?\s*\d{0,3}\s*%`)
+
+	// htmlTagPattern matches HTML tags in descriptions.
+	htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+	// nonWordPattern matches everything except letters, digits and spaces.
+	nonWordPattern = regexp.MustCompile(`[^\p{L}\p{N} ]+`)
+
+	// diacritics folds common accented characters so spellings across sources compare equal.
+	diacritics = strings.NewReplacer(
+		"ä", "a", "à", "a", "â", "a", "á", "a",
+		"ö", "o", "ô", "o", "ó", "o",
+		"ü", "u", "ù", "u", "û", "u", "ú", "u",
+		"é", "e", "è", "e", "ê", "e", "ë", "e",
+		"î", "i", "ï", "i", "í", "i",
+		"ç", "c", "ß", "ss",
+	)
+
+	// companySuffixes are legal forms dropped from company names.
+	companySuffixes = map[string]bool{
+		"ag": true, "sa": true, "gmbh": true, "sarl": true, "sagl": true, "ltd": true,
+		"inc": true, "co": true, "kg": true, "llc": true, "plc": true, "se": true,
+	}
+)
+
+// Fingerprint identifies a vacancy independently of its source and ID.
+// Key is a hash of the normalized company, title and location, so exact matches are cheap to find;
+// Simhash summarizes the description shingles so near-identical texts can be compared.
+type Fingerprint struct {
+	Key     string
+	Simhash uint64
+}
+
+// String encodes the fingerprint for the jobs.fingerprint column as "<key>:<simhash>".
+func (f Fingerprint) String() string {
+	return f.Key + ":" + fmt.Sprintf("%016x", f.Simhash)
+}
+
+// ParseFingerprint decodes a value produced by Fingerprint.String.
+func ParseFingerprint(s string) (Fingerprint, error) {
+	key, sim, ok := strings.Cut(s, ":")
+	if !ok {
+		return Fingerprint{}, fmt.Errorf("invalid fingerprint %q", s)
+	}
+	simhash, err := strconv.ParseUint(sim, 16, 64)
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("invalid fingerprint %q: %w", s, err)
+	}
+	return Fingerprint{Key: key, Simhash: simhash}, nil
+}
+
+// Distance returns the number of differing description simhash bits.
+func (f Fingerprint) Distance(other Fingerprint) int {
+	return bits.OnesCount64(f.Simhash ^ other.Simhash)
+}
+
+// Near reports whether two fingerprints describe the same vacancy.
+func (f Fingerprint) Near(other Fingerprint) bool {
+	return f.Key == other.Key && f.Distance(other) <= MaxSimhashDistance
+}
+
+// ComputeFingerprint derives the job's fingerprint from company, title, location and description.
+func (j *JobDetail) ComputeFingerprint() Fingerprint {
+	desc := j.primaryDescription()
+
+	location := strings.TrimSpace(j.JobContent.Location.PostalCode)
+	if location == "" {
+		location = normalizeText(j.JobContent.Location.City)
+	}
+
+	key := normalizeCompany(j.JobContent.Company.Name) + "|" + normalizeTitle(desc.Title) + "|" + location
+	sum := sha256.Sum256([]byte(key))
+
+	return Fingerprint{
+		Key:     hex.EncodeToString(sum[:16]),
+		Simhash: simhash(shingles(normalizeText(htmlTagPattern.ReplaceAllString(desc.Description, " ")))),
+	}
+}
+
+// primaryDescription picks the description used for fingerprinting, preferring German like job-room.ch.
+func (j *JobDetail) primaryDescription() JobDescription {
+	descriptions := j.JobContent.JobDescriptions
+	for _, lang := range []string{"de", "fr", "it", "en"} {
+		for _, d := range descriptions {
+			if strings.EqualFold(d.LanguageIsoCode, lang) {
+				return d
+			}
+		}
+	}
+	if len(descriptions) > 0 {
+		return descriptions[0]
+	}
+	return JobDescription{}
+}
+
+// normalizeText lowercases, folds diacritics, strips punctuation and collapses whitespace.
+func normalizeText(s string) string {
+	s = diacritics.Replace(strings.ToLower(s))
+	s = nonWordPattern.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// normalizeTitle removes gender markers and workload percentages before normalizing.
+func normalizeTitle(title string) string {
+	return normalizeText(titleNoisePattern.ReplaceAllString(strings.ToLower(title), " "))
+}
+
+// normalizeCompany drops legal forms such as "AG" or "GmbH" before normalizing.
+func normalizeCompany(name string) string {
+	words := strings.Fields(normalizeText(name))
+	kept := words[:0]
+	for _, w := range words {
+		if !companySuffixes[w] {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// shingles returns the overlapping word n-grams of normalized text.
+func shingles(text string) []string {
+	words := strings.Fields(text)
+	if len(words) < shingleSize {
+		if len(words) == 0 {
+			return nil
+		}
+		return []string{strings.Join(words, " ")}
+	}
+	out := make([]string, 0, len(words)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		out = append(out, strings.Join(words[i:i+shingleSize], " "))
+	}
+	return out
+}
+
+// simhash computes a 64-bit simhash over the shingles; similar sets give hashes with few differing bits.
+func simhash(shingles []string) uint64 {
+	var weights [64]int
+	for _, sh := range shingles {
+		h := fnv.New64a()
+		h.Write([]byte(sh))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var result uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			result |= 1 << bit
+		}
+	}
+	return result
+}
+
+// JobCluster groups near-duplicate jobs; CanonicalJobID is the member to show when collapsing.
+type JobCluster struct {
+	ID             int64     `json:"id" db:"id"`
+	FingerprintKey string    `json:"fingerprint_key" db:"fingerprint_key"`
+	CanonicalJobID *string   `json:"canonical_job_id,omitempty" db:"canonical_job_id"`
+	Size           int       `json:"size" db:"size"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
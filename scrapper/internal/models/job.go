@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -9,8 +10,9 @@ import (
 type JobSource string
 
 const (
-	SourceJobRoom  JobSource = "jobroom"
-	SourcePlatform JobSource = "platform"
+	SourceJobRoom    JobSource = "jobroom"
+	SourcePlatform   JobSource = "platform"
+	SourceCareerPage JobSource = "careerpage" // schema.org JobPosting pages and RSS/Atom feeds
 )
 
 // JobStatus represents the status of a job.
@@ -38,6 +40,7 @@ type JobDetail struct {
 	JobContent          JobContent  `json:"jobContent"`
 	Publication         Publication `json:"publication"`
 	RawData             string      `json:"-" db:"raw_data"` // Stores the original JSON
+	Source              JobSource   `json:"-" db:"source"`   // Set by the scraper source; empty means jobroom
 }
 
 // ToRawJSON marshals the job to JSON for storage.
@@ -190,18 +193,102 @@ type Publication struct {
 
 // ScrapeRun represents a scraping run for telemetry.
 type ScrapeRun struct {
-	ID            int64      `json:"id" db:"id"`
-	Strategy      string     `json:"strategy" db:"strategy"`
-	StartTime     time.Time  `json:"start_time" db:"start_time"`
-	EndTime       *time.Time `json:"end_time" db:"end_time"`
-	Status        string     `json:"status" db:"status"`
-	JobsProcessed int        `json:"jobs_processed" db:"jobs_processed"`
-	JobsInserted  int        `json:"jobs_inserted" db:"jobs_inserted"`
-	JobsUpdated   int        `json:"jobs_updated" db:"jobs_updated"`
-	JobsSkipped   int        `json:"jobs_skipped" db:"jobs_skipped"`
-	PagesScraped  int        `json:"pages_scraped" db:"pages_scraped"`
-	Filters       *string    `json:"filters" db:"filters"`
-	ErrorLog      *string    `json:"error_log" db:"error_log"`
+	ID        int64      `json:"id" db:"id"`
+	ProfileID *int64     `json:"profile_id,omitempty" db:"profile_id"`
+	Strategy  string     `json:"strategy" db:"strategy"`
+	StartTime time.Time  `json:"start_time" db:"start_time"`
+	EndTime   *time.Time `json:"end_time" db:"end_time"`
+	Status    string     `json:"status" db:"status"`
+
+	RunCounters
+
+	Filters  *string `json:"filters" db:"filters"`
+	ErrorLog *string `json:"error_log" db:"error_log"`
+
+	// Checkpoint of the last fully processed page, used to resume interrupted runs
+	CheckpointPage  *int    `json:"checkpoint_page,omitempty" db:"checkpoint_page"`
+	CheckpointJobID *string `json:"checkpoint_job_id,omitempty" db:"checkpoint_job_id"`
+	FiltersHash     *string `json:"filters_hash,omitempty" db:"filters_hash"`
+	ResumedFrom     *int64  `json:"resumed_from,omitempty" db:"resumed_from"`
+
+	// Shards is the JSON-encoded []ScrapeShard for sharded runs
+	Shards *string `json:"shards,omitempty" db:"shards"`
+}
+
+// RunCounters are the metrics of a scrape run, stored on its scrape_runs row.
+type RunCounters struct {
+	JobsProcessed int `json:"jobs_processed" db:"jobs_processed"`
+	JobsInserted  int `json:"jobs_inserted" db:"jobs_inserted"`
+	JobsUpdated   int `json:"jobs_updated" db:"jobs_updated"`
+	JobsSkipped   int `json:"jobs_skipped" db:"jobs_skipped"`
+	PagesScraped  int `json:"pages_scraped" db:"pages_scraped"`
+	PageErrors    int `json:"page_errors" db:"page_errors"`         // Listing pages that could not be fetched
+	JobsExpired   int `json:"jobs_expired" db:"jobs_expired"`       // Jobs marked expired by the reconciliation pass
+	AIJobsQueued  int `json:"ai_jobs_queued" db:"ai_jobs_queued"`   // Stored jobs handed to the AI queue
+	AIJobsSkipped int `json:"ai_jobs_skipped" db:"ai_jobs_skipped"` // Stored jobs already waiting in the AI queue
+	AIJobsFailed  int `json:"ai_jobs_failed" db:"ai_jobs_failed"`   // Stored jobs that could not be queued
+}
+
+// ScrapeShard records the outcome of one sub-query of a sharded run.
+type ScrapeShard struct {
+	Key          string `json:"key"`
+	Depth        int    `json:"depth"`
+	Status       string `json:"status"` // completed, split, capped, cancelled
+	PagesScraped int    `json:"pages_scraped"`
+	JobsListed   int    `json:"jobs_listed"`
+	JobsInserted int    `json:"jobs_inserted"`
+	JobsUpdated  int    `json:"jobs_updated"`
+	Duplicates   int    `json:"duplicates"` // Jobs already handled by an earlier shard
+	StopReason   string `json:"stop_reason,omitempty"`
+}
+
+// ResumeRequest rebuilds the run's ScrapeRequest so that it continues after the checkpoint.
+// It returns done=true if the run already reached its MaxPages limit.
+func (r *ScrapeRun) ResumeRequest() (req ScrapeRequest, done bool, err error) {
+	if r.Filters == nil {
+		return req, false, fmt.Errorf("run %d has no stored filters", r.ID)
+	}
+	if r.CheckpointPage == nil {
+		return req, false, fmt.Errorf("run %d has no checkpoint", r.ID)
+	}
+	if err := json.Unmarshal([]byte(*r.Filters), &req); err != nil {
+		return req, false, fmt.Errorf("run %d: invalid filters: %w", r.ID, err)
+	}
+	if r.FiltersHash != nil && *r.FiltersHash != req.FiltersHash() {
+		return req, false, fmt.Errorf("run %d: filters do not match checkpoint hash", r.ID)
+	}
+
+	nextPage := *r.CheckpointPage + 1
+	if req.MaxPages > 0 {
+		remaining := req.StartPage + req.MaxPages - nextPage
+		if remaining <= 0 {
+			return req, true, nil
+		}
+		req.MaxPages = remaining
+	}
+	req.StartPage = nextPage
+
+	return req, false, nil
+}
+
+// ScrapeProfile is a named ScrapeRequest executed on a cron schedule.
+type ScrapeProfile struct {
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Schedule  string    `json:"schedule" db:"schedule"`
+	Request   string    `json:"request" db:"request"` // JSON-encoded ScrapeRequest
+	Enabled   bool      `json:"enabled" db:"enabled"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ScrapeRequest decodes the profile's stored request.
+func (p *ScrapeProfile) ScrapeRequest() (ScrapeRequest, error) {
+	var req ScrapeRequest
+	if err := json.Unmarshal([]byte(p.Request), &req); err != nil {
+		return req, err
+	}
+	return req, nil
 }
 
 // JobSummary is a lightweight representation of a job for listing.
@@ -211,4 +298,5 @@ type JobSummary struct {
 	Source      string `json:"source" db:"source"`
 	CreatedTime string `json:"created_time" db:"created_time"`
 	UpdatedTime string `json:"updated_time" db:"updated_time"`
+	Duplicates  int    `json:"duplicates" db:"duplicates"`
 }
@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"scrapper/internal/models"
+	"scrapper/internal/scraper"
+	"scrapper/internal/store"
+)
+
+// Config holds configuration for the scheduler.
+type Config struct {
+	ClientConfig scraper.ClientConfig // Base client config; Polite is taken from each profile
+	AIMode       string               // AI processing mode stored jobs are queued with ("" = none)
+	Workers      int                  // Concurrent detail fetch workers per run
+	ExpireAfter  int                  // Missed runs before a job is expired (0 = no reconciliation)
+	RecheckLimit int                  // Suspect jobs re-fetched per reconciliation
+	StaleAfter   time.Duration        // Running runs older than this do not block a profile
+}
+
+// Scheduler runs scrape profiles on their cron schedules.
+type Scheduler struct {
+	store   *store.Store
+	config  Config
+	cron    *cron.Cron
+	mu      sync.Mutex
+	running map[int64]bool
+}
+
+// New creates a new Scheduler instance.
+func New(s *store.Store, cfg Config) *Scheduler {
+	return &Scheduler{
+		store:   s,
+		config:  cfg,
+		cron:    cron.New(),
+		running: make(map[int64]bool),
+	}
+}
+
+// ValidateSchedule checks that a cron expression can be parsed.
+// Accepts standard 5-field expressions and descriptors such as "@hourly" or "@every 30m".
+func ValidateSchedule(schedule string) error {
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+	return nil
+}
+
+// Run registers all enabled profiles and blocks until ctx is cancelled.
+// On shutdown it waits for in-flight runs to record their final status.
+func (s *Scheduler) Run(ctx context.Context) error {
+	profiles, err := s.store.ListProfiles(ctx, true)
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		return fmt.Errorf("no enabled scrape profiles found")
+	}
+
+	for _, profile := range profiles {
+		profile := profile
+		if _, err := profile.ScrapeRequest(); err != nil {
+			return fmt.Errorf("profile %s: invalid request: %w", profile.Name, err)
+		}
+		if _, err := s.cron.AddFunc(profile.Schedule, func() { s.execute(ctx, profile) }); err != nil {
+			return fmt.Errorf("profile %s: invalid schedule %q: %w", profile.Name, profile.Schedule, err)
+		}
+		slog.Info("scheduled scrape profile", "profile", profile.Name, "schedule", profile.Schedule)
+	}
+
+	s.cron.Start()
+	slog.Info("scheduler started", "profiles", len(profiles))
+
+	<-ctx.Done()
+
+	slog.Info("scheduler stopping, waiting for running scrapes to finish")
+	<-s.cron.Stop().Done()
+	slog.Info("scheduler stopped")
+
+	return nil
+}
+
+// execute performs a single scheduled run of a profile, skipping it if a previous run is still active.
+func (s *Scheduler) execute(ctx context.Context, profile models.ScrapeProfile) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	if !s.acquire(profile.ID) {
+		slog.Warn("previous run still in progress, skipping", "profile", profile.Name)
+		return
+	}
+	defer s.release(profile.ID)
+
+	// Another process (or a run started before a restart) may still own the profile
+	active, err := s.store.HasActiveProfileRun(ctx, profile.ID, s.config.StaleAfter)
+	if err != nil {
+		slog.Error("failed to check active runs", "profile", profile.Name, "error", err)
+		return
+	}
+	if active {
+		slog.Warn("profile has a running scrape run, skipping", "profile", profile.Name)
+		return
+	}
+
+	req, err := profile.ScrapeRequest()
+	if err != nil {
+		slog.Error("failed to decode profile request", "profile", profile.Name, "error", err)
+		return
+	}
+
+	clientCfg := s.config.ClientConfig
+	clientCfg.Polite = req.Polite
+
+	source, err := scraper.NewSource(&req, clientCfg)
+	if err != nil {
+		slog.Error("failed to create source", "profile", profile.Name, "error", err)
+		return
+	}
+
+	filters, err := req.ToJSON()
+	if err != nil {
+		slog.Error("failed to encode filters", "profile", profile.Name, "error", err)
+		return
+	}
+
+	runID, err := s.store.CreateProfileRun(ctx, profile.ID, req.Strategy, filters)
+	if err != nil {
+		slog.Error("failed to create run", "profile", profile.Name, "error", err)
+		return
+	}
+
+	runner := scraper.NewRunner(s.store, source)
+	runner.SetWorkers(s.config.Workers)
+	runner.SetExpiry(s.config.ExpireAfter, s.config.RecheckLimit)
+	runner.SetAIHandoff(s.config.AIMode)
+
+	slog.Info("running scheduled scrape", "profile", profile.Name, "run_id", runID)
+	result := runner.Run(ctx, req, runID)
+	slog.Info("scheduled scrape finished",
+		"profile", profile.Name,
+		"run_id", runID,
+		"status", result.Status,
+		"jobs_processed", result.JobsProcessed,
+	)
+}
+
+// acquire marks a profile as running in this process. Returns false if it already is.
+func (s *Scheduler) acquire(profileID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[profileID] {
+		return false
+	}
+	s.running[profileID] = true
+	return true
+}
+
+// release clears the running mark for a profile.
+func (s *Scheduler) release(profileID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, profileID)
+}
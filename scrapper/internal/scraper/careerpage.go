@@ -0,0 +1,577 @@
+package scraper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"scrapper/internal/models"
+)
+
+// jsonLDPattern matches the contents of <script type="application/ld+json"> blocks.
+var jsonLDPattern = regexp.MustCompile(`(?is)<script[^>]*type\s*=\s*["']application/ld\+json["'][^>]*>(.*?)</script>`)
+
+// CareerPageSource ingests jobs from employers' own career pages.
+// Each configured URL is one listing page and may be either an HTML page with schema.org
+// JobPosting JSON-LD or an RSS/Atom feed. Feed items that link to a page with JobPosting
+// JSON-LD are enriched from that page when their detail is fetched.
+type CareerPageSource struct {
+	http   *http.Client
+	config ClientConfig
+	pacer  *pacer
+	urls   []string
+
+	mu     sync.Mutex                    // guards listed
+	listed map[string]*careerPagePosting // postings seen by ListJobs, by job ID
+}
+
+// careerPagePosting is a listed posting and where its details can be loaded from.
+type careerPagePosting struct {
+	job  models.JobDetail
+	link string // Item page to enrich from (feeds only)
+}
+
+// NewCareerPageSource creates a source for the given career page or feed URLs.
+func NewCareerPageSource(urls []string, cfg ClientConfig) *CareerPageSource {
+	return &CareerPageSource{
+		http: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		config: cfg,
+		pacer:  newPacer(cfg),
+		urls:   urls,
+		listed: make(map[string]*careerPagePosting),
+	}
+}
+
+// Name returns the jobs.source value for career pages.
+func (s *CareerPageSource) Name() models.JobSource {
+	return models.SourceCareerPage
+}
+
+// ListJobs returns the postings found at the page-th configured URL.
+// Search filters do not apply to career pages and are ignored.
+func (s *CareerPageSource) ListJobs(ctx context.Context, req *models.ScrapeRequest, page int) ([]models.JobDetail, error) {
+	if page < 0 || page >= len(s.urls) {
+		return nil, nil
+	}
+	pageURL := s.urls[page]
+
+	body, contentType, err := s.get(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var postings []*careerPagePosting
+	if isFeed(contentType, body) {
+		postings, err = parseFeed(pageURL, body)
+	} else {
+		postings, err = parseJobPostingPage(pageURL, body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pageURL, err)
+	}
+
+	scraped := time.Now()
+	jobs := make([]models.JobDetail, 0, len(postings))
+	s.mu.Lock()
+	for _, posting := range postings {
+		fillMissingDates(&posting.job, scraped)
+		s.listed[posting.job.ID] = posting
+		jobs = append(jobs, posting.job)
+	}
+	s.mu.Unlock()
+
+	slog.Debug("fetched career page", "url", pageURL, "count", len(jobs))
+	return jobs, nil
+}
+
+// FetchJobDetail returns a posting listed earlier in this run.
+// Feed items are enriched from their linked page when it carries JobPosting JSON-LD.
+func (s *CareerPageSource) FetchJobDetail(ctx context.Context, id string) (*models.JobDetail, error) {
+	s.mu.Lock()
+	posting, ok := s.listed[id]
+	s.mu.Unlock()
+	if !ok {
+		// Career pages have no per-job endpoint; only postings listed in this run are known
+		return nil, fmt.Errorf("career page job %s was not listed in this run", id)
+	}
+
+	job := posting.job
+	if posting.link == "" {
+		return &job, nil
+	}
+
+	body, _, err := s.get(ctx, posting.link)
+	if err != nil {
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone) {
+			return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		// The feed item alone is still a usable posting
+		slog.Warn("failed to fetch career page item, using feed data", "id", id, "url", posting.link, "error", err)
+		return &job, nil
+	}
+
+	enriched, err := parseJobPostingPage(posting.link, body)
+	if err != nil || len(enriched) == 0 {
+		return &job, nil
+	}
+
+	detail := enriched[0].job
+	detail.ID = job.ID
+	if detail.CreatedTime == "" {
+		detail.CreatedTime = job.CreatedTime
+	}
+	if detail.UpdatedTime == "" {
+		detail.UpdatedTime = job.UpdatedTime
+	}
+	return &detail, nil
+}
+
+// get performs a paced GET with retries and returns the body and content type.
+func (s *CareerPageSource) get(ctx context.Context, pageURL string) ([]byte, string, error) {
+	body, header, err := doRequest(ctx, s.http, s.pacer, s.config, endpointCareerPage, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("User-Agent", randomUserAgent())
+		req.Header.Set("Accept", "text/html,application/xhtml+xml,application/rss+xml,application/atom+xml,application/xml;q=0.9,*/*;q=0.8")
+		return req, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return body, header.Get("Content-Type"), nil
+}
+
+// isFeed reports whether a response is an RSS or Atom feed rather than an HTML page.
+func isFeed(contentType string, body []byte) bool {
+	contentType = strings.ToLower(contentType)
+	if strings.Contains(contentType, "rss") || strings.Contains(contentType, "atom") {
+		return true
+	}
+	if strings.Contains(contentType, "html") {
+		return false
+	}
+	head := strings.ToLower(string(body[:min(len(body), 512)]))
+	return strings.Contains(head, "<rss") || strings.Contains(head, "<feed")
+}
+
+// parseJobPostingPage extracts every schema.org JobPosting from the JSON-LD blocks of an HTML page.
+func parseJobPostingPage(pageURL string, body []byte) ([]*careerPagePosting, error) {
+	var postings []*careerPagePosting
+	for _, match := range jsonLDPattern.FindAllSubmatch(body, -1) {
+		var doc interface{}
+		if err := json.Unmarshal(match[1], &doc); err != nil {
+			slog.Debug("skipping invalid JSON-LD block", "url", pageURL, "error", err)
+			continue
+		}
+		for _, obj := range findJobPostings(doc) {
+			job := jobPostingToDetail(pageURL, obj)
+			postings = append(postings, &careerPagePosting{job: job})
+		}
+	}
+	return postings, nil
+}
+
+// findJobPostings walks a JSON-LD document, including arrays and @graph, for JobPosting objects.
+func findJobPostings(doc interface{}) []map[string]interface{} {
+	var found []map[string]interface{}
+	switch v := doc.(type) {
+	case []interface{}:
+		for _, item := range v {
+			found = append(found, findJobPostings(item)...)
+		}
+	case map[string]interface{}:
+		if hasType(v, "JobPosting") {
+			found = append(found, v)
+		}
+		if graph, ok := v["@graph"]; ok {
+			found = append(found, findJobPostings(graph)...)
+		}
+		if items, ok := v["itemListElement"]; ok {
+			for _, item := range ldObjects(items) {
+				if posting, ok := item["item"]; ok {
+					found = append(found, findJobPostings(posting)...)
+				} else {
+					found = append(found, findJobPostings(item)...)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// jobPostingToDetail maps a schema.org JobPosting onto the job-room shaped JobDetail.
+func jobPostingToDetail(pageURL string, posting map[string]interface{}) models.JobDetail {
+	postingURL := resolveURL(pageURL, ldString(posting["url"]))
+	if postingURL == "" {
+		postingURL = pageURL
+	}
+
+	identifier := ldString(posting["identifier"])
+	if obj := ldObject(posting["identifier"]); obj != nil {
+		identifier = ldString(obj["value"])
+	}
+
+	title := ldString(posting["title"])
+	org := ldObject(posting["hiringOrganization"])
+	orgName := ldString(posting["hiringOrganization"])
+	if org != nil {
+		orgName = ldString(org["name"])
+	}
+
+	created := ldString(posting["datePosted"])
+	updated := ldString(posting["dateModified"])
+	if updated == "" {
+		updated = created
+	}
+
+	job := models.JobDetail{
+		ID:                careerPageJobID(hostOf(pageURL), identifier, postingURL, orgName, title),
+		CreatedTime:       created,
+		UpdatedTime:       updated,
+		Status:            "PUBLISHED",
+		SourceSystem:      hostOf(postingURL),
+		ExternalReference: identifier,
+		Source:            models.SourceCareerPage,
+	}
+
+	job.JobContent.ExternalURL = postingURL
+	job.JobContent.JobDescriptions = []models.JobDescription{{
+		LanguageIsoCode: languageCode(ldString(posting["inLanguage"])),
+		Title:           title,
+		Description:     ldString(posting["description"]),
+	}}
+
+	job.JobContent.Company.Name = orgName
+	if org != nil {
+		if website := ldString(org["sameAs"]); website != "" {
+			job.JobContent.Company.Website = &website
+		} else if website := ldString(org["url"]); website != "" {
+			job.JobContent.Company.Website = &website
+		}
+	}
+
+	if places := ldObjects(posting["jobLocation"]); len(places) > 0 {
+		place := places[0]
+		address := ldObject(place["address"])
+		if address != nil {
+			job.JobContent.Location.City = ldString(address["addressLocality"])
+			job.JobContent.Location.PostalCode = ldString(address["postalCode"])
+			job.JobContent.Location.CountryIsoCode = countryCode(address["addressCountry"])
+			if region := strings.ToUpper(ldString(address["addressRegion"])); models.ValidateCanton(region) {
+				job.JobContent.Location.CantonCode = region
+			}
+
+			job.JobContent.Company.Street = ldString(address["streetAddress"])
+			job.JobContent.Company.PostalCode = job.JobContent.Location.PostalCode
+			job.JobContent.Company.City = job.JobContent.Location.City
+			job.JobContent.Company.CountryIsoCode = job.JobContent.Location.CountryIsoCode
+		}
+		if geo := ldObject(place["geo"]); geo != nil {
+			job.JobContent.Location.Coordinates.Lat = ldString(geo["latitude"])
+			job.JobContent.Location.Coordinates.Lon = ldString(geo["longitude"])
+		}
+	}
+
+	employmentTypes := strings.ToUpper(strings.Join(ldStrings(posting["employmentType"]), ","))
+	job.JobContent.Employment.Permanent = employmentTypes != "" &&
+		!strings.Contains(employmentTypes, "TEMPORARY") &&
+		!strings.Contains(employmentTypes, "CONTRACTOR") &&
+		!strings.Contains(employmentTypes, "INTERN")
+	if strings.Contains(employmentTypes, "FULL_TIME") && !strings.Contains(employmentTypes, "PART_TIME") {
+		job.JobContent.Employment.WorkloadPercentageMin = "100"
+		job.JobContent.Employment.WorkloadPercentageMax = "100"
+	}
+
+	job.Publication.StartDate = created
+	job.Publication.EndDate = ldString(posting["validThrough"])
+	job.Publication.PublicDisplay = true
+
+	job.JobContent.ApplyChannel.FormURL = &postingURL
+
+	return job
+}
+
+// rssFeed and atomFeed are the parts of RSS 2.0 and Atom documents we read.
+type rssFeed struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+			GUID        string `xml:"guid"`
+			PubDate     string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atomFeed struct {
+	Title   string `xml:"title"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		ID        string `xml:"id"`
+		Updated   string `xml:"updated"`
+		Published string `xml:"published"`
+	} `xml:"entry"`
+}
+
+// feedItem is a format-independent feed entry.
+type feedItem struct {
+	id, title, link, description, published, updated string
+}
+
+// parseFeed maps the items of an RSS or Atom feed to postings. The feed title is used as company name.
+func parseFeed(feedURL string, body []byte) ([]*careerPagePosting, error) {
+	var (
+		feedTitle string
+		items     []feedItem
+	)
+
+	var rss rssFeed
+	if err := xml.Unmarshal(body, &rss); err == nil && len(rss.Channel.Items) > 0 {
+		feedTitle = rss.Channel.Title
+		for _, item := range rss.Channel.Items {
+			published := normalizeFeedTime(item.PubDate)
+			items = append(items, feedItem{
+				id: item.GUID, title: item.Title, link: item.Link, description: item.Description,
+				published: published, updated: published,
+			})
+		}
+	} else {
+		var atom atomFeed
+		if err := xml.Unmarshal(body, &atom); err != nil {
+			return nil, fmt.Errorf("failed to parse feed: %w", err)
+		}
+		feedTitle = atom.Title
+		for _, entry := range atom.Entries {
+			link := ""
+			for _, l := range entry.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			description := entry.Content
+			if description == "" {
+				description = entry.Summary
+			}
+			items = append(items, feedItem{
+				id: entry.ID, title: entry.Title, link: link, description: description,
+				published: normalizeFeedTime(entry.Published), updated: normalizeFeedTime(entry.Updated),
+			})
+		}
+	}
+
+	postings := make([]*careerPagePosting, 0, len(items))
+	for _, item := range items {
+		link := resolveURL(feedURL, strings.TrimSpace(item.link))
+		if item.updated == "" {
+			item.updated = item.published
+		}
+
+		job := models.JobDetail{
+			ID:                careerPageJobID(hostOf(feedURL), strings.TrimSpace(item.id), link, feedTitle, item.title),
+			CreatedTime:       item.published,
+			UpdatedTime:       item.updated,
+			Status:            "PUBLISHED",
+			SourceSystem:      hostOf(feedURL),
+			ExternalReference: strings.TrimSpace(item.id),
+			Source:            models.SourceCareerPage,
+		}
+		job.JobContent.ExternalURL = link
+		job.JobContent.JobDescriptions = []models.JobDescription{{
+			Title:       strings.TrimSpace(item.title),
+			Description: strings.TrimSpace(item.description),
+		}}
+		job.JobContent.Company.Name = strings.TrimSpace(feedTitle)
+		job.Publication.StartDate = item.published
+		job.Publication.PublicDisplay = true
+		if link != "" {
+			job.JobContent.ApplyChannel.FormURL = &link
+		}
+
+		postings = append(postings, &careerPagePosting{job: job, link: link})
+	}
+	return postings, nil
+}
+
+// careerPageJobID derives a stable job ID from the host of the career page and the first
+// available identifying value. Employers pick identifiers such as "1" themselves, so they
+// are only unique per host.
+func careerPageJobID(host string, parts ...string) string {
+	key := ""
+	for _, part := range parts {
+		if part != "" {
+			key = part
+			break
+		}
+	}
+	if key == "" {
+		key = strings.Join(parts, "|")
+	}
+	sum := sha256.Sum256([]byte(host + "|" + key))
+	return string(models.SourceCareerPage) + "-" + hex.EncodeToString(sum[:12])
+}
+
+// fillMissingDates sets the updated time of a posting without dates to the time it was scraped,
+// since change detection needs one; such postings count as updated on every scrape. A missing
+// created time stays empty: the first insert stores the first-seen time and later upserts keep
+// it, so it never shows up as a changed field.
+func fillMissingDates(job *models.JobDetail, scraped time.Time) {
+	if job.UpdatedTime == "" {
+		job.UpdatedTime = job.CreatedTime
+	}
+	if job.UpdatedTime == "" {
+		job.UpdatedTime = scraped.UTC().Format(time.RFC3339)
+	}
+}
+
+// normalizeFeedTime converts RSS (RFC 1123) and Atom (RFC 3339) timestamps to RFC 3339.
+func normalizeFeedTime(s string) string {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return s
+}
+
+// resolveURL resolves ref against base, returning ref unchanged if either cannot be parsed.
+func resolveURL(base, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
+
+// hostOf returns the host name of a URL, used as source_system for career page jobs.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// languageCode reduces a BCP 47 tag such as "de-CH" to its lowercase primary language.
+func languageCode(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i > 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+// countryCode returns an ISO country code from a schema.org addressCountry (text or Country object).
+func countryCode(v interface{}) string {
+	if obj := ldObject(v); obj != nil {
+		return strings.ToUpper(ldString(obj["name"]))
+	}
+	return strings.ToUpper(ldString(v))
+}
+
+// hasType reports whether a JSON-LD object has the given @type.
+func hasType(obj map[string]interface{}, typ string) bool {
+	for _, t := range ldStrings(obj["@type"]) {
+		if t == typ || strings.HasSuffix(t, "/"+typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// ldString returns a JSON-LD value as text, taking the first element of arrays.
+func ldString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return strings.TrimSpace(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case []interface{}:
+		if len(val) > 0 {
+			return ldString(val[0])
+		}
+	case map[string]interface{}:
+		if value, ok := val["@value"]; ok {
+			return ldString(value)
+		}
+	}
+	return ""
+}
+
+// ldStrings returns a JSON-LD value that may be a single string or an array of strings.
+func ldStrings(v interface{}) []string {
+	if list, ok := v.([]interface{}); ok {
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			if s := ldString(item); s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	if s := ldString(v); s != "" {
+		return []string{s}
+	}
+	return nil
+}
+
+// ldObject returns a JSON-LD value as an object, taking the first element of arrays.
+func ldObject(v interface{}) map[string]interface{} {
+	if objects := ldObjects(v); len(objects) > 0 {
+		return objects[0]
+	}
+	return nil
+}
+
+// ldObjects returns a JSON-LD value that may be a single object or an array of objects.
+func ldObjects(v interface{}) []map[string]interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{val}
+	case []interface{}:
+		var objects []map[string]interface{}
+		for _, item := range val {
+			if obj, ok := item.(map[string]interface{}); ok {
+				objects = append(objects, obj)
+			}
+		}
+		return objects
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"scrapper/internal/models"
+)
+
+const undatedPostingPage = `<html><head><script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "JobPosting", "title": "Backend Engineer",
+ "identifier": {"@type": "PropertyValue", "value": "123"}, "hiringOrganization": {"name": "Acme"}}
+</script></head></html>`
+
+const undatedFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Acme Jobs</title>
+<item><title>Backend Engineer</title><guid>1</guid><description>Go and Postgres</description></item>
+</channel></rss>`
+
+func TestCareerPageJobIDScopedByHost(t *testing.T) {
+	a := jobPostingToDetail("https://jobs.acme.example/careers", map[string]interface{}{"identifier": "123"})
+	b := jobPostingToDetail("https://careers.globex.example/", map[string]interface{}{"identifier": "123"})
+	if a.ID == b.ID {
+		t.Errorf("identifier 123 of two employers gives the same ID %s", a.ID)
+	}
+
+	again := jobPostingToDetail("https://jobs.acme.example/careers?page=2", map[string]interface{}{"identifier": "123"})
+	if again.ID != a.ID {
+		t.Errorf("identifier 123 on the same host gives %s and %s, want a stable ID", a.ID, again.ID)
+	}
+
+	acme, err := parseFeed("https://jobs.acme.example/feed.xml", []byte(undatedFeed))
+	if err != nil {
+		t.Fatalf("parseFeed: %v", err)
+	}
+	globex, err := parseFeed("https://careers.globex.example/rss", []byte(undatedFeed))
+	if err != nil {
+		t.Fatalf("parseFeed: %v", err)
+	}
+	if acme[0].job.ID == globex[0].job.ID {
+		t.Errorf("guid 1 of two feeds gives the same ID %s", acme[0].job.ID)
+	}
+}
+
+func TestCareerPageFillsMissingDates(t *testing.T) {
+	for _, tt := range []struct {
+		name, contentType, body string
+	}{
+		{"json-ld", "text/html", undatedPostingPage},
+		{"feed", "application/rss+xml", undatedFeed},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			source := NewCareerPageSource([]string{server.URL}, ClientConfig{Timeout: 5 * time.Second})
+			before := time.Now().Add(-time.Second)
+			jobs, err := source.ListJobs(context.Background(), nil, 0)
+			if err != nil {
+				t.Fatalf("ListJobs: %v", err)
+			}
+			if len(jobs) != 1 {
+				t.Fatalf("got %d jobs, want 1", len(jobs))
+			}
+
+			job := jobs[0]
+			at, err := time.Parse(time.RFC3339, job.UpdatedTime)
+			if err != nil {
+				t.Errorf("updated time %q is not RFC 3339: %v", job.UpdatedTime, err)
+			} else if at.Before(before) {
+				t.Errorf("updated time %s is before the scrape", job.UpdatedTime)
+			}
+			// Left to the first insert, so re-scrapes do not change it
+			if job.CreatedTime != "" {
+				t.Errorf("created time = %q, want empty", job.CreatedTime)
+			}
+		})
+	}
+}
+
+func TestCareerPageUndatedRescrapeRecordsNoRevision(t *testing.T) {
+	scrape := func(at time.Time) *models.JobDetail {
+		postings, err := parseJobPostingPage("https://jobs.acme.example/careers", []byte(undatedPostingPage))
+		if err != nil || len(postings) != 1 {
+			t.Fatalf("parseJobPostingPage: %d postings, %v", len(postings), err)
+		}
+		job := postings[0].job
+		fillMissingDates(&job, at)
+		return &job
+	}
+
+	// The first upsert stores the job as raw_data; the second one compares against it
+	first := scrape(time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC))
+	raw, err := json.Marshal(first)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var stored models.JobDetail
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	second := scrape(time.Date(2024, 5, 7, 8, 0, 0, 0, time.UTC))
+	if second.UpdatedTime == stored.UpdatedTime {
+		t.Fatalf("updated time %s did not move with the scrape", second.UpdatedTime)
+	}
+	changes, err := models.RevisionChanges(&stored, second)
+	if err != nil {
+		t.Fatalf("RevisionChanges: %v", err)
+	}
+	if len(changes) > 0 {
+		t.Errorf("re-scraping an unchanged undated posting records a revision: %+v", changes)
+	}
+}
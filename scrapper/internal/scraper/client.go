@@ -2,9 +2,10 @@ package scraper
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"math/rand"
 	"net/http"
@@ -25,6 +26,16 @@ var userAgents = []string{
 	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
 }
 
+// Endpoint labels of requests in the HTTP metrics.
+const (
+	endpointJobRoomSearch = "jobroom_search"
+	endpointJobRoomDetail = "jobroom_detail"
+	endpointCareerPage    = "careerpage"
+)
+
+// ErrJobNotFound is returned by FetchJobDetail when job-room.ch no longer has the job.
+var ErrJobNotFound = errors.New("job not found")
+
 // ClientConfig holds configuration for the scraper client.
 type ClientConfig struct {
 	Polite       bool
@@ -33,6 +44,11 @@ type ClientConfig struct {
 	Timeout      time.Duration
 	MaxRetries   int
 	RetryDelayMs int
+
+	// FixtureMode records API exchanges to FixtureDir (FixtureRecord) or serves them from it
+	// instead of the network (FixtureReplay). Empty talks to the live API.
+	FixtureMode string
+	FixtureDir  string
 }
 
 // DefaultClientConfig returns sensible defaults for the scraper client.
@@ -47,44 +63,41 @@ func DefaultClientConfig() ClientConfig {
 	}
 }
 
-// Client handles HTTP communication with the job-room.ch API.
+// Client handles HTTP communication with the job-room.ch API and implements Source.
+// It is safe for concurrent use; polite delays are enforced across all callers.
 type Client struct {
 	http    *http.Client
 	config  ClientConfig
+	pacer   *pacer
 	BaseURL string
 }
 
 // NewClient creates a new scraper client.
-func NewClient(cfg ClientConfig) *Client {
+// Replayed requests never touch the network, so requests are not paced in replay mode.
+func NewClient(cfg ClientConfig) (*Client, error) {
+	transport, err := newFixtureTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p := newPacer(cfg)
+	if cfg.FixtureMode == FixtureReplay {
+		p = &pacer{} // never waits
+	}
+
 	return &Client{
 		http: &http.Client{
-			Timeout: cfg.Timeout,
+			Timeout:   cfg.Timeout,
+			Transport: transport,
 		},
 		config:  cfg,
+		pacer:   p,
 		BaseURL: "https://www.job-room.ch/jobadservice/api/jobAdvertisements",
-	}
+	}, nil
 }
 
-// sleep pauses execution for a random duration if Polite mode is enabled.
-func (c *Client) sleep() {
-	if !c.config.Polite {
-		return
-	}
-
-	minMs := c.config.DelayMinMs
-	maxMs := c.config.DelayMaxMs
-	if minMs <= 0 {
-		minMs = 2000
-	}
-	if maxMs <= minMs {
-		maxMs = minMs + 3000
-	}
-
-	delayMs := minMs + rand.Intn(maxMs-minMs+1)
-	delay := time.Duration(delayMs) * time.Millisecond
-
-	slog.Debug("polite mode: sleeping before request", "delay_ms", delayMs)
-	time.Sleep(delay)
+// Name returns the jobs.source value for job-room.ch.
+func (c *Client) Name() models.JobSource {
+	return models.SourceJobRoom
 }
 
 // randomUserAgent returns a random User-Agent string from the pool.
@@ -102,11 +115,9 @@ func setHeaders(req *http.Request) {
 	req.Header.Set("Referer", "https://www.job-room.ch/home/job-seeker")
 }
 
-// FetchJobsWithRequest retrieves a page of job listings using a ScrapeRequest for filtering.
+// ListJobs retrieves a page of job listings using a ScrapeRequest for filtering.
 // Uses POST request with JSON body as required by the job-room.ch API.
-func (c *Client) FetchJobsWithRequest(req *models.ScrapeRequest, page int) ([]models.JobDetail, error) {
-	c.sleep()
-
+func (c *Client) ListJobs(ctx context.Context, req *models.ScrapeRequest, page int) ([]models.JobDetail, error) {
 	// Build URL with query parameters for pagination only
 	u, err := url.Parse(c.BaseURL + "/_search")
 	if err != nil {
@@ -134,140 +145,78 @@ func (c *Client) FetchJobsWithRequest(req *models.ScrapeRequest, page int) ([]mo
 		"cantons", req.Cantons,
 	)
 
-	return c.fetchJobsWithPOST(u.String(), jsonBody)
+	return c.fetchJobsWithPOST(ctx, u.String(), jsonBody)
 }
 
 // fetchJobsWithPOST performs a POST request and parses the response.
-func (c *Client) fetchJobsWithPOST(urlStr string, jsonBody []byte) ([]models.JobDetail, error) {
-	var lastErr error
-
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			delay := time.Duration(c.config.RetryDelayMs*attempt) * time.Millisecond
-			slog.Debug("retrying request", "attempt", attempt, "delay_ms", delay.Milliseconds())
-			time.Sleep(delay)
-		}
-
-		req, err := http.NewRequest(http.MethodPost, urlStr, bytes.NewReader(jsonBody))
+func (c *Client) fetchJobsWithPOST(ctx context.Context, urlStr string, jsonBody []byte) ([]models.JobDetail, error) {
+	body, _, err := doRequest(ctx, c.http, c.pacer, c.config, endpointJobRoomSearch, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, urlStr, bytes.NewReader(jsonBody))
 		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+			return nil, err
 		}
 		setHeaders(req)
 		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		resp, err := c.http.Do(req)
-		if err != nil {
-			lastErr = fmt.Errorf("request failed: %w", err)
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			lastErr = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
-
-			// Don't retry on 4xx errors (except 429)
-			if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != 429 {
-				return nil, lastErr
-			}
-			continue
-		}
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to read response body: %w", err)
-			continue
-		}
-
-		// The API returns: [ { "jobAdvertisement": { ... } }, ... ]
-		type listResponse []struct {
-			JobAdvertisement models.JobDetail `json:"jobAdvertisement"`
-		}
-
-		var wrapper listResponse
-		if err := json.Unmarshal(body, &wrapper); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-		}
+	// The API returns: [ { "jobAdvertisement": { ... } }, ... ]
+	type listResponse []struct {
+		JobAdvertisement models.JobDetail `json:"jobAdvertisement"`
+	}
 
-		// Extract JobDetail objects from wrappers
-		jobs := make([]models.JobDetail, len(wrapper))
-		for i, item := range wrapper {
-			jobs[i] = item.JobAdvertisement
-			// Store the raw JSON for each job
-			rawBytes, _ := json.Marshal(item.JobAdvertisement)
-			jobs[i].RawData = string(rawBytes)
-		}
+	var wrapper listResponse
+	if err := json.Unmarshal(body, &wrapper); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
 
-		slog.Debug("fetched jobs", "count", len(jobs))
-		return jobs, nil
+	// Extract JobDetail objects from wrappers
+	jobs := make([]models.JobDetail, len(wrapper))
+	for i, item := range wrapper {
+		jobs[i] = item.JobAdvertisement
+		// Store the raw JSON for each job
+		rawBytes, _ := json.Marshal(item.JobAdvertisement)
+		jobs[i].RawData = string(rawBytes)
 	}
 
-	return nil, lastErr
+	slog.Debug("fetched jobs", "count", len(jobs))
+	return jobs, nil
 }
 
 // FetchJobDetail retrieves a single job's full details by ID.
-func (c *Client) FetchJobDetail(id string) (*models.JobDetail, error) {
-	c.sleep()
-
+func (c *Client) FetchJobDetail(ctx context.Context, id string) (*models.JobDetail, error) {
 	// Build URL
 	u := fmt.Sprintf("%s/%s?_ng=ZW4=", c.BaseURL, url.PathEscape(id))
 
 	slog.Debug("fetching job detail", "id", id, "url", u)
 
-	var lastErr error
-
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			delay := time.Duration(c.config.RetryDelayMs*attempt) * time.Millisecond
-			slog.Debug("retrying request", "attempt", attempt, "delay_ms", delay.Milliseconds())
-			time.Sleep(delay)
-		}
-
-		req, err := http.NewRequest(http.MethodGet, u, nil)
+	body, _, err := doRequest(ctx, c.http, c.pacer, c.config, endpointJobRoomDetail, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+			return nil, err
 		}
 		setHeaders(req)
+		return req, nil
+	})
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		resp, err := c.http.Do(req)
-		if err != nil {
-			lastErr = fmt.Errorf("request failed: %w", err)
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, fmt.Errorf("job not found: %s", id)
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			lastErr = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
-
-			// Don't retry on 4xx errors (except 429)
-			if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != 429 {
-				return nil, lastErr
-			}
-			continue
-		}
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to read response body: %w", err)
-			continue
-		}
-
-		var job models.JobDetail
-		if err := json.Unmarshal(body, &job); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal job detail: %w", err)
-		}
-
-		// Store the raw JSON
-		job.RawData = strings.TrimSpace(string(body))
-
-		slog.Debug("fetched job detail", "id", id, "status", job.Status)
-		return &job, nil
+	var job models.JobDetail
+	if err := json.Unmarshal(body, &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job detail: %w", err)
 	}
 
-	return nil, lastErr
+	// Store the raw JSON
+	job.RawData = strings.TrimSpace(string(body))
+
+	slog.Debug("fetched job detail", "id", id, "status", job.Status)
+	return &job, nil
 }
@@ -0,0 +1,198 @@
+package scraper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// Fixture modes for ClientConfig.FixtureMode.
+const (
+	FixtureRecord = "record" // Call the API and store every exchange in FixtureDir
+	FixtureReplay = "replay" // Serve responses from FixtureDir without network access
+)
+
+// ErrNoFixture is returned in replay mode for a request that was never recorded.
+var ErrNoFixture = errors.New("no recorded fixture")
+
+// fixtureNamePattern matches characters not allowed in fixture file names.
+var fixtureNamePattern = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// fixture is one recorded request/response pair as stored on disk.
+type fixture struct {
+	Request struct {
+		Method string          `json:"method"`
+		URL    string          `json:"url"`
+		Body   json.RawMessage `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		Status      int             `json:"status"`
+		ContentType string          `json:"content_type,omitempty"`
+		Body        json.RawMessage `json:"body"`
+	} `json:"response"`
+}
+
+// newFixtureTransport returns the round tripper for cfg's fixture mode, or nil for live requests.
+func newFixtureTransport(cfg ClientConfig) (http.RoundTripper, error) {
+	switch cfg.FixtureMode {
+	case "":
+		return nil, nil
+	case FixtureRecord, FixtureReplay:
+		if cfg.FixtureDir == "" {
+			return nil, fmt.Errorf("fixture mode %s requires a fixture directory", cfg.FixtureMode)
+		}
+		if cfg.FixtureMode == FixtureReplay {
+			return &replayTransport{dir: cfg.FixtureDir}, nil
+		}
+		if err := os.MkdirAll(cfg.FixtureDir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create fixture directory: %w", err)
+		}
+		return &recordingTransport{dir: cfg.FixtureDir, next: http.DefaultTransport}, nil
+	default:
+		return nil, fmt.Errorf("unknown fixture mode: %s", cfg.FixtureMode)
+	}
+}
+
+// recordingTransport performs requests with next and writes each exchange to dir.
+type recordingTransport struct {
+	dir  string
+	next http.RoundTripper
+	mu   sync.Mutex // serializes fixture writes
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	var f fixture
+	f.Request.Method = req.Method
+	f.Request.URL = req.URL.RequestURI()
+	if len(reqBody) > 0 {
+		f.Request.Body = rawBody(reqBody)
+	}
+	f.Response.Status = resp.StatusCode
+	f.Response.ContentType = resp.Header.Get("Content-Type")
+	f.Response.Body = rawBody(respBody)
+
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return nil, fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := os.WriteFile(filepath.Join(t.dir, fixtureName(req, reqBody)), data.Bytes(), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return resp, nil
+}
+
+// replayTransport answers requests from fixtures in dir.
+type replayTransport struct {
+	dir string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fixtureName(req, reqBody)
+	data, err := os.ReadFile(filepath.Join(t.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s %s (%s)", ErrNoFixture, req.Method, req.URL.RequestURI(), name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture %s: %w", name, err)
+	}
+
+	body := []byte(f.Response.Body)
+	var text string
+	if json.Unmarshal(body, &text) == nil {
+		body = []byte(text)
+	}
+
+	header := make(http.Header)
+	if f.Response.ContentType != "" {
+		header.Set("Content-Type", f.Response.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Response.Status, http.StatusText(f.Response.Status)),
+		StatusCode:    f.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// fixtureName derives the file name of a request's fixture from its method, path, query and body.
+// The host is ignored, so fixtures recorded against job-room.ch replay against any BaseURL.
+func fixtureName(req *http.Request, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	sum.Write(body)
+
+	base := fixtureNamePattern.ReplaceAllString(path.Base(req.URL.Path), "")
+	return req.Method + "_" + base + "_" + hex.EncodeToString(sum.Sum(nil))[:12] + ".json"
+}
+
+// readRequestBody reads and restores the request body.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// rawBody embeds JSON bodies as-is for readable fixtures and stores anything else as a JSON string.
+func rawBody(body []byte) json.RawMessage {
+	if json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	quoted, _ := json.Marshal(string(body))
+	return quoted
+}
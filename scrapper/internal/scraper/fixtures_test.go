@@ -0,0 +1,227 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"scrapper/internal/models"
+)
+
+var updateFixtures = flag.Bool("update", false, "re-record testdata/jobroom from the stub job-room server")
+
+// fixtureDir holds the recorded job-room exchanges replayed by the runner tests.
+const fixtureDir = "testdata/jobroom"
+
+// stubPageSize is the number of jobs per search page served by the stub.
+const stubPageSize = 3
+
+// stubCappedPage is the first page the stub refuses with a 412, like job-room.ch beyond its result cap.
+const stubCappedPage = 2
+
+// stubJobs are the listings served by the stub job-room server, newest first.
+var stubJobs = []struct {
+	id      string
+	updated string
+	title   string
+}{
+	{"7c1e5a2e-0001-4a8e-9d3b-5f0c2b7a1e01", "2024-05-06T08:15:00.000Z", "Pflegefachperson HF 80-100%"},
+	{"7c1e5a2e-0002-4a8e-9d3b-5f0c2b7a1e02", "2024-05-06T07:40:00.000Z", "Software Engineer Backend (m/w/d)"},
+	{"7c1e5a2e-0003-4a8e-9d3b-5f0c2b7a1e03", "2024-05-05T16:05:00.000Z", "Kaufmann/Kauffrau EFZ"},
+	{"7c1e5a2e-0004-4a8e-9d3b-5f0c2b7a1e04", "2024-05-05T10:30:00.000Z", "Logistiker EFZ 100%"},
+	{"7c1e5a2e-0005-4a8e-9d3b-5f0c2b7a1e05", "2024-05-04T13:20:00.000Z", "Elektroinstallateur EFZ"},
+}
+
+// fixtureRequest is the scrape request the fixtures were recorded for.
+func fixtureRequest(strategy string) models.ScrapeRequest {
+	return models.ScrapeRequest{
+		Strategy:    strategy,
+		Cantons:     []string{"ZH"},
+		DaysBack:    30,
+		WorkloadMin: 10,
+		WorkloadMax: 100,
+	}
+}
+
+// stubJob builds a job-room job advertisement for the stub listing at index i.
+func stubJob(i int) models.JobDetail {
+	s := stubJobs[i]
+	return models.JobDetail{
+		ID:           s.id,
+		CreatedTime:  s.updated,
+		UpdatedTime:  s.updated,
+		Status:       "PUBLISHED_PUBLIC",
+		SourceSystem: "API",
+		JobContent: models.JobContent{
+			NumberOfJobs: "1",
+			JobDescriptions: []models.JobDescription{{
+				LanguageIsoCode: "de",
+				Title:           s.title,
+				Description:     "Wir suchen per sofort eine motivierte Persönlichkeit als " + s.title + ".",
+			}},
+			Company: models.Company{
+				Name:           "Muster AG",
+				Street:         "Bahnhofstrasse",
+				PostalCode:     "8001",
+				City:           "Zürich",
+				CountryIsoCode: "CH",
+			},
+			Employment: models.Employment{
+				Immediately:           true,
+				Permanent:             true,
+				WorkloadPercentageMin: "80",
+				WorkloadPercentageMax: "100",
+			},
+			Location: models.Location{
+				City:           "Zürich",
+				PostalCode:     "8001",
+				CantonCode:     "ZH",
+				CountryIsoCode: "CH",
+			},
+		},
+		Publication: models.Publication{
+			StartDate:     s.updated[:10],
+			PublicDisplay: true,
+		},
+	}
+}
+
+// newStubJobRoom serves stubJobs through the job-room search and detail endpoints.
+func newStubJobRoom(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobadservice/api/jobAdvertisements/_search", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page >= stubCappedPage {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			w.Write([]byte(`{"title":"Precondition Failed","status":412,"detail":"exceed max result limit"}`))
+			return
+		}
+
+		type item struct {
+			JobAdvertisement models.JobDetail `json:"jobAdvertisement"`
+		}
+		items := []item{}
+		for i := page * stubPageSize; i < min((page+1)*stubPageSize, len(stubJobs)); i++ {
+			items = append(items, item{JobAdvertisement: stubJob(i)})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(items)
+	})
+	mux.HandleFunc("GET /jobadservice/api/jobAdvertisements/{id}", func(w http.ResponseWriter, r *http.Request) {
+		for i, s := range stubJobs {
+			if s.id == r.PathValue("id") {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(stubJob(i))
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newFixtureClient creates a job-room client in the given fixture mode with retries disabled.
+func newFixtureClient(t *testing.T, mode, dir string) *Client {
+	t.Helper()
+
+	client, err := NewClient(ClientConfig{
+		Timeout:     5 * time.Second,
+		FixtureMode: mode,
+		FixtureDir:  dir,
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+// TestUpdateFixtures re-records testdata/jobroom when run with -update.
+func TestUpdateFixtures(t *testing.T) {
+	if !*updateFixtures {
+		t.Skip("run with -update to re-record fixtures")
+	}
+
+	server := newStubJobRoom(t)
+	client := newFixtureClient(t, FixtureRecord, fixtureDir)
+	client.BaseURL = server.URL + "/jobadservice/api/jobAdvertisements"
+
+	req := fixtureRequest(models.StrategyFull)
+	for page := 0; page <= stubCappedPage; page++ {
+		client.ListJobs(context.Background(), &req, page)
+	}
+	for _, s := range stubJobs {
+		if _, err := client.FetchJobDetail(context.Background(), s.id); err != nil {
+			t.Fatalf("FetchJobDetail(%s): %v", s.id, err)
+		}
+	}
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	server := newStubJobRoom(t)
+	dir := t.TempDir()
+	req := fixtureRequest(models.StrategyFull)
+
+	recorder := newFixtureClient(t, FixtureRecord, dir)
+	recorder.BaseURL = server.URL + "/jobadservice/api/jobAdvertisements"
+
+	recorded, err := recorder.ListJobs(context.Background(), &req, 0)
+	if err != nil {
+		t.Fatalf("record ListJobs: %v", err)
+	}
+	if _, err := recorder.FetchJobDetail(context.Background(), stubJobs[0].id); err != nil {
+		t.Fatalf("record FetchJobDetail: %v", err)
+	}
+	if _, err := recorder.FetchJobDetail(context.Background(), "gone"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("record FetchJobDetail(gone) = %v, want ErrJobNotFound", err)
+	}
+	server.Close()
+
+	// Replay ignores the host, so the default job-room BaseURL serves the recording
+	replayer := newFixtureClient(t, FixtureReplay, dir)
+
+	replayed, err := replayer.ListJobs(context.Background(), &req, 0)
+	if err != nil {
+		t.Fatalf("replay ListJobs: %v", err)
+	}
+	if len(replayed) != len(recorded) {
+		t.Fatalf("replayed %d jobs, recorded %d", len(replayed), len(recorded))
+	}
+	for i := range recorded {
+		if replayed[i].ID != recorded[i].ID || replayed[i].UpdatedTime != recorded[i].UpdatedTime {
+			t.Errorf("job %d: replayed %s@%s, recorded %s@%s",
+				i, replayed[i].ID, replayed[i].UpdatedTime, recorded[i].ID, recorded[i].UpdatedTime)
+		}
+	}
+
+	detail, err := replayer.FetchJobDetail(context.Background(), stubJobs[0].id)
+	if err != nil {
+		t.Fatalf("replay FetchJobDetail: %v", err)
+	}
+	if got := detail.JobContent.JobDescriptions[0].Title; got != stubJobs[0].title {
+		t.Errorf("replayed title = %q, want %q", got, stubJobs[0].title)
+	}
+	if _, err := replayer.FetchJobDetail(context.Background(), "gone"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("replay FetchJobDetail(gone) = %v, want ErrJobNotFound", err)
+	}
+	if _, err := replayer.FetchJobDetail(context.Background(), stubJobs[1].id); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("replay of unrecorded request = %v, want ErrNoFixture", err)
+	}
+
+	// A different search body is a different fixture
+	other := fixtureRequest(models.StrategyFull)
+	other.Cantons = []string{"BE"}
+	if _, err := replayer.ListJobs(context.Background(), &other, 0); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("replay with other filters = %v, want no fixture", err)
+	}
+}
@@ -0,0 +1,142 @@
+package scraper
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// maxSlowdown is the factor polite delays grow to when every recent request failed
+	maxSlowdown = 8
+
+	// errorRateWeight is the weight of the latest outcome in the moving error rate
+	errorRateWeight = 0.2
+
+	// slowdownThreshold is the error rate above which delays are stretched
+	slowdownThreshold = 0.1
+)
+
+// pacer is an adaptive rate controller that spaces out requests.
+// It is shared by every goroutine using a source, so it bounds the combined rate:
+//   - polite mode adds a random delay between requests
+//   - a rising error rate stretches that delay up to maxSlowdown times (and adds one in non-polite mode)
+//   - a server's Retry-After pauses all callers until it has passed
+type pacer struct {
+	polite bool
+	minMs  int
+	maxMs  int
+
+	mu           sync.Mutex // guards the fields below
+	nextRequest  time.Time  // earliest time the next polite request may start
+	blockedUntil time.Time  // no request may start before this (Retry-After)
+	errorRate    float64    // exponential moving average of failed requests, 0..1
+}
+
+// newPacer creates a pacer from the client's delay settings.
+func newPacer(cfg ClientConfig) *pacer {
+	minMs := cfg.DelayMinMs
+	maxMs := cfg.DelayMaxMs
+	if minMs <= 0 {
+		minMs = 2000
+	}
+	if maxMs <= minMs {
+		maxMs = minMs + 3000
+	}
+	return &pacer{polite: cfg.Polite, minMs: minMs, maxMs: maxMs}
+}
+
+// wait blocks until the caller may send a request, or returns ctx's error if it is cancelled first.
+// Each call reserves the next slot and pushes the following one back by the current delay,
+// so concurrent callers share one global rate instead of each sleeping independently.
+func (p *pacer) wait(ctx context.Context) error {
+	p.mu.Lock()
+	delay := p.delay()
+	now := time.Now()
+	slot := now
+	if delay > 0 {
+		slot = p.nextRequest
+		if slot.Before(now) {
+			// Idle source: wait before the request like a single sequential caller would
+			slot = now.Add(delay)
+		}
+	}
+	if slot.Before(p.blockedUntil) {
+		// Line up behind the Retry-After pause instead of all resuming when it ends
+		slot = p.blockedUntil
+	}
+	if delay > 0 {
+		p.nextRequest = slot.Add(delay)
+	}
+	p.mu.Unlock()
+
+	wait := time.Until(slot)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	slog.Debug("pacer: sleeping before request", "delay_ms", wait.Milliseconds(), "polite", p.polite)
+	return sleepContext(ctx, wait)
+}
+
+// delay returns the spacing for the next request. Callers must hold p.mu.
+func (p *pacer) delay() time.Duration {
+	slowdown := 1.0
+	if p.errorRate > slowdownThreshold {
+		slowdown += p.errorRate * (maxSlowdown - 1)
+	}
+
+	if p.polite {
+		ms := p.minMs + rand.Intn(p.maxMs-p.minMs+1)
+		return time.Duration(float64(ms)*slowdown) * time.Millisecond
+	}
+	if slowdown > 1 {
+		// Not polite, but the server is struggling: back off proportionally to the error rate
+		return time.Duration(p.errorRate*float64(p.maxMs)) * time.Millisecond
+	}
+	return 0
+}
+
+// observe feeds the outcome of a request into the error rate. Throttling (429), server errors
+// and transport failures count as failures; any other response counts as success.
+func (p *pacer) observe(failed bool) {
+	outcome := 0.0
+	if failed {
+		outcome = 1
+	}
+
+	p.mu.Lock()
+	before := p.errorRate
+	p.errorRate = (1-errorRateWeight)*p.errorRate + errorRateWeight*outcome
+	after := p.errorRate
+	p.mu.Unlock()
+
+	if before <= slowdownThreshold && after > slowdownThreshold {
+		slog.Warn("error rate rising, slowing down requests", "error_rate", after)
+	} else if before > slowdownThreshold && after <= slowdownThreshold {
+		slog.Info("error rate recovered, restoring request rate", "error_rate", after)
+	}
+}
+
+// pause holds back every caller for d, e.g. when the server sent Retry-After.
+func (p *pacer) pause(d time.Duration) {
+	until := time.Now().Add(d)
+	p.mu.Lock()
+	if until.After(p.blockedUntil) {
+		p.blockedUntil = until
+	}
+	p.mu.Unlock()
+}
+
+// sleepContext sleeps for d or until ctx is cancelled, returning ctx's error in that case.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
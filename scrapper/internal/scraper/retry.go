@@ -0,0 +1,148 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"scrapper/internal/metrics"
+)
+
+const (
+	// maxBackoff caps the exponential retry delay
+	maxBackoff = 60 * time.Second
+
+	// maxRetryAfter caps how long a server's Retry-After is honoured
+	maxRetryAfter = 5 * time.Minute
+)
+
+// statusError is returned for a response that is not 200 OK.
+type statusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *statusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// retryable reports whether a status is worth retrying: throttling or a server error.
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// doRequest sends the request built by newRequest through the pacer and returns the body and
+// headers of a 200 response. Transport errors, 429 and 5xx are retried up to cfg.MaxRetries
+// times with exponential backoff and jitter, or after the server's Retry-After, which also
+// pauses every other caller of the pacer. Other statuses fail immediately with a *statusError.
+// Cancelling ctx aborts the request and any wait at once. Every attempt is counted in the
+// HTTP metrics under endpoint.
+func doRequest(ctx context.Context, client *http.Client, p *pacer, cfg ClientConfig, endpoint string, newRequest func(context.Context) (*http.Request, error)) ([]byte, http.Header, error) {
+	var (
+		lastErr    error
+		retryAfter time.Duration
+	)
+
+	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := max(backoff(cfg.RetryDelayMs, attempt), retryAfter)
+			slog.Debug("retrying request", "endpoint", endpoint, "attempt", attempt, "delay_ms", delay.Milliseconds(), "error", lastErr)
+			metrics.HTTPRetries.WithLabelValues(endpoint).Inc()
+			if err := sleepContext(ctx, delay); err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := p.wait(ctx); err != nil {
+			return nil, nil, err
+		}
+
+		req, err := newRequest(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		metrics.HTTPRequestDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			metrics.HTTPRequests.WithLabelValues(endpoint, "error").Inc()
+			p.observe(true)
+			lastErr = fmt.Errorf("request failed: %w", err)
+			retryAfter = 0
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		metrics.HTTPRequests.WithLabelValues(endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+
+		if retryable(resp.StatusCode) {
+			p.observe(true)
+			lastErr = &statusError{StatusCode: resp.StatusCode, Body: string(body)}
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			if retryAfter > 0 {
+				slog.Warn("server asked to retry later", "status", resp.StatusCode, "retry_after", retryAfter)
+				p.pause(retryAfter)
+			}
+			continue
+		}
+
+		// Anything else is a regular answer from a healthy server
+		p.observe(false)
+		retryAfter = 0
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			lastErr = fmt.Errorf("failed to read response body: %w", err)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, nil, &statusError{StatusCode: resp.StatusCode, Body: string(body)}
+		}
+		return body, resp.Header, nil
+	}
+
+	return nil, nil, lastErr
+}
+
+// backoff returns the delay before retry attempt n (1-based): baseMs doubled per attempt,
+// capped at maxBackoff, with jitter between half and the full delay.
+func backoff(baseMs, attempt int) time.Duration {
+	if baseMs <= 0 {
+		baseMs = 1000
+	}
+	d := time.Duration(baseMs) * time.Millisecond << min(attempt-1, 16)
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter decodes a Retry-After header given in seconds or as an HTTP date.
+// It returns 0 if the header is absent or invalid and caps the result at maxRetryAfter.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(header); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(header); err == nil {
+		d = at.Sub(now)
+	}
+	return min(max(d, 0), maxRetryAfter)
+}
@@ -0,0 +1,132 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"scrapper/internal/models"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"7", 7 * time.Second},
+		{"-3", 0},
+		{"soon", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"86400", maxRetryAfter},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestBackoffGrowsWithJitter(t *testing.T) {
+	for attempt := 1; attempt <= 20; attempt++ {
+		full := min(time.Duration(100<<(attempt-1))*time.Millisecond, maxBackoff)
+		if attempt > 16 {
+			full = maxBackoff
+		}
+		if d := backoff(100, attempt); d < full/2 || d > full {
+			t.Errorf("backoff(100, %d) = %v, want within [%v, %v]", attempt, d, full/2, full)
+		}
+	}
+}
+
+func TestRetryHonoursRetryAfterAndContext(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{Timeout: 5 * time.Second, MaxRetries: 3, RetryDelayMs: 10})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	client.BaseURL = server.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	req := models.DefaultScrapeRequest()
+	start := time.Now()
+	_, err = client.ListJobs(ctx, &req, 0)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ListJobs error = %v, want context deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ListJobs returned after %v, want prompt return on cancellation", elapsed)
+	}
+	// The first 503 asks for 30s, so no retry may have been sent before the deadline
+	if n := calls.Load(); n != 1 {
+		t.Errorf("server saw %d requests, want 1", n)
+	}
+}
+
+func TestPacerSlowsDownOnErrors(t *testing.T) {
+	p := newPacer(ClientConfig{DelayMinMs: 100, DelayMaxMs: 100})
+	if d := p.delay(); d != 0 {
+		t.Fatalf("healthy non-polite delay = %v, want 0", d)
+	}
+	for i := 0; i < 10; i++ {
+		p.observe(true)
+	}
+	if d := p.delay(); d <= 0 {
+		t.Errorf("delay after failures = %v, want > 0", d)
+	}
+	for i := 0; i < 30; i++ {
+		p.observe(false)
+	}
+	if d := p.delay(); d != 0 {
+		t.Errorf("delay after recovery = %v, want 0", d)
+	}
+}
+
+func TestPacerSpacesCallersAfterPause(t *testing.T) {
+	p := newPacer(ClientConfig{Polite: true, DelayMinMs: 50, DelayMaxMs: 51})
+	p.pause(300 * time.Millisecond)
+
+	const callers = 5
+	var mu sync.Mutex
+	var started []time.Time
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.wait(context.Background()); err != nil {
+				t.Errorf("wait: %v", err)
+				return
+			}
+			mu.Lock()
+			started = append(started, time.Now())
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(started, func(i, j int) bool { return started[i].Before(started[j]) })
+	for i := 1; i < len(started); i++ {
+		// Half the delay leaves room for timer slack; without spacing the gaps are ~0
+		if gap := started[i].Sub(started[i-1]); gap < 25*time.Millisecond {
+			t.Errorf("caller %d started %v after the previous one, want about 50ms", i, gap)
+		}
+	}
+}
@@ -2,54 +2,109 @@ package scraper
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
-	"scrapper/internal/aiclient"
+	"scrapper/internal/metrics"
 	"scrapper/internal/models"
-	"scrapper/internal/store"
 )
 
 const (
 	// MaxConsecutiveErrors is the maximum number of consecutive page fetch errors before stopping
 	MaxConsecutiveErrors = 10
+
+	// MaxWorkers caps the number of concurrent detail fetches per run
+	MaxWorkers = 16
 )
 
+// RunStore is the persistence the Runner needs; *store.Store implements it.
+type RunStore interface {
+	GetJobLastUpdated(ctx context.Context, id string) (string, bool, error)
+	UpsertJob(ctx context.Context, job *models.JobDetail) error
+	EnqueueAIJobs(ctx context.Context, runID int64, mode string, ids []string) (int, error)
+	MarkJobsSeen(ctx context.Context, runID int64, ids []string) error
+	SaveCheckpoint(ctx context.Context, runID int64, page int, lastJobID, filtersHash string) error
+	SaveRunShards(ctx context.Context, runID int64, shards []models.ScrapeShard) error
+	UpdateRun(ctx context.Context, runID int64, status string, counters models.RunCounters, errLog string) error
+	RecordRunEvents(ctx context.Context, events []models.RunEvent) error
+
+	CountMissedJobs(ctx context.Context, runID int64, cantons []string, daysBack int) (int, error)
+	ExpireMissedJobs(ctx context.Context, minMissed int) (int, error)
+	ListSuspectJobs(ctx context.Context, limit int) ([]string, error)
+	ExpireJob(ctx context.Context, id string) error
+}
+
 // Runner handles background scraping with telemetry.
+// Progress is also counted in the Prometheus metrics of the metrics package.
 type Runner struct {
-	store    *store.Store
-	client   *Client
-	aiClient *aiclient.Client // Optional AI processing client
+	store   RunStore
+	source  Source
+	aiMode  string // AI processing mode stored jobs are queued with ("" = no AI handoff)
+	workers int    // Concurrent detail fetch workers (default 1)
+
+	expireAfterRuns int // Consecutive missed runs before a job is expired (0 = no reconciliation)
+	recheckLimit    int // Suspect jobs re-fetched individually per reconciliation
 }
 
-// NewRunner creates a new Runner instance.
-func NewRunner(s *store.Store, clientCfg ClientConfig) *Runner {
+// NewRunner creates a new Runner that scrapes src.
+func NewRunner(s RunStore, src Source) *Runner {
 	return &Runner{
-		store:  s,
-		client: NewClient(clientCfg),
+		store:   s,
+		source:  src,
+		workers: 1,
 	}
 }
 
-// SetAIClient sets the AI processing client (optional).
-func (r *Runner) SetAIClient(client *aiclient.Client) {
-	r.aiClient = client
+// SetAIHandoff queues every stored job for ai_job_processing with mode (process, normalize
+// or translate) after each page. Queuing never waits for the AI service.
+func (r *Runner) SetAIHandoff(mode string) {
+	r.aiMode = mode
+}
+
+// SetWorkers sets the number of jobs fetched and stored concurrently, clamped to [1, MaxWorkers].
+func (r *Runner) SetWorkers(n int) {
+	r.workers = min(max(n, 1), MaxWorkers)
+}
+
+// SetExpiry enables the reconciliation pass: after a run that listed its whole scope, active jobs
+// not seen for afterRuns consecutive runs are expired, and up to recheckLimit jobs that were
+// missed at least once are re-fetched individually and expired if job-room.ch returns 404.
+func (r *Runner) SetExpiry(afterRuns, recheckLimit int) {
+	r.expireAfterRuns = max(afterRuns, 0)
+	r.recheckLimit = max(recheckLimit, 0)
 }
 
 // RunResult contains the results of a scrape run.
 type RunResult struct {
-	RunID           int64
-	Status          string
-	JobsProcessed   int
-	JobsInserted    int
-	JobsUpdated     int
-	JobsSkipped     int
-	PagesScraped    int
-	AIJobsProcessed int // Jobs sent to AI service
-	AIJobsSkipped   int // Jobs skipped by AI service (already processed)
-	AIJobsFailed    int // Jobs that failed AI processing
-	Errors          []string
-	StopReason      string
+	RunID  int64
+	Status string
+	models.RunCounters
+	Errors     []string
+	StopReason string
+	Shards     []models.ScrapeShard // Per-shard telemetry (sharded strategy only)
+
+	events []models.RunEvent // Timeline events not yet written to the store
+}
+
+// addEvent queues a timeline event; it is written with the next flushEvents.
+// Callers running concurrently must hold the lock guarding the result.
+func (res *RunResult) addEvent(typ models.RunEventType, page int, jobID string, took time.Duration, message string) {
+	res.events = append(res.events, models.NewRunEvent(res.RunID, typ, page, jobID, took, message))
+}
+
+// flushEvents writes queued events to the store. Events are telemetry, so a failed write is only logged.
+func (r *Runner) flushEvents(ctx context.Context, result *RunResult) {
+	if len(result.events) == 0 {
+		return
+	}
+	if err := r.store.RecordRunEvents(context.WithoutCancel(ctx), result.events); err != nil {
+		slog.Warn("failed to record run events", "run_id", result.RunID, "events", len(result.events), "error", err)
+	}
+	result.events = result.events[:0]
 }
 
 // Run performs background scraping with run tracking and incremental logic.
@@ -58,11 +113,7 @@ func (r *Runner) Run(ctx context.Context, req models.ScrapeRequest, runID int64)
 		RunID:  runID,
 		Status: "completed",
 	}
-
-	var (
-		consecutiveErrors = 0
-		stopScraping      = false
-	)
+	started := time.Now()
 
 	// Ensure run is updated when function exits
 	defer func() {
@@ -71,9 +122,26 @@ func (r *Runner) Run(ctx context.Context, req models.ScrapeRequest, runID int64)
 			result.Status = "failed"
 		}
 
-		if err := r.store.UpdateRun(ctx, runID, result.Status, result.JobsProcessed, result.JobsInserted, result.JobsUpdated, result.JobsSkipped, result.PagesScraped, errLog); err != nil {
+		stopMessage := result.Status
+		if result.StopReason != "" {
+			stopMessage += ": " + result.StopReason
+		}
+		result.addEvent(models.EventStopped, -1, "", time.Since(started), stopMessage)
+		r.flushEvents(ctx, &result)
+
+		labels := []string{string(r.source.Name()), string(req.Strategy), result.Status}
+		metrics.RunDuration.WithLabelValues(labels...).Observe(time.Since(started).Seconds())
+		metrics.LastRun.WithLabelValues(labels...).SetToCurrentTime()
+
+		// Use a non-cancellable context so the final status is recorded even on shutdown
+		if err := r.store.UpdateRun(context.WithoutCancel(ctx), runID, result.Status, result.RunCounters, errLog); err != nil {
 			slog.Error("failed to update run", "run_id", runID, "error", err)
 		}
+		if len(result.Shards) > 0 {
+			if err := r.store.SaveRunShards(context.WithoutCancel(ctx), runID, result.Shards); err != nil {
+				slog.Error("failed to save run shards", "run_id", runID, "error", err)
+			}
+		}
 
 		slog.Info("scraper completed",
 			"run_id", runID,
@@ -84,7 +152,9 @@ func (r *Runner) Run(ctx context.Context, req models.ScrapeRequest, runID int64)
 			"jobs_updated", result.JobsUpdated,
 			"jobs_skipped", result.JobsSkipped,
 			"pages_scraped", result.PagesScraped,
-			"ai_jobs_processed", result.AIJobsProcessed,
+			"jobs_expired", result.JobsExpired,
+			"shards", len(result.Shards),
+			"ai_jobs_queued", result.AIJobsQueued,
 			"ai_jobs_skipped", result.AIJobsSkipped,
 			"ai_jobs_failed", result.AIJobsFailed,
 			"errors", len(result.Errors),
@@ -94,22 +164,218 @@ func (r *Runner) Run(ctx context.Context, req models.ScrapeRequest, runID int64)
 
 	slog.Info("scraper started",
 		"run_id", runID,
+		"source", r.source.Name(),
 		"strategy", req.Strategy,
 		"max_pages", req.MaxPages,
 		"polite", req.Polite,
 		"keywords", req.Keywords,
 		"cantons", req.Cantons,
-		"ai_enabled", r.aiClient != nil,
+		"start_page", req.StartPage,
+		"workers", r.workers,
+		"ai_mode", r.aiMode,
+	)
+
+	if req.Strategy == models.StrategySharded {
+		r.runSharded(ctx, req, runID, &result)
+	} else {
+		result.StopReason, _ = r.scrapePages(ctx, req, runID, &result, nil, nil)
+	}
+
+	// Only job-room.ch listings can be mapped back onto a known scope of stored jobs
+	if r.expireAfterRuns > 0 && r.source.Name() == models.SourceJobRoom && coversFullListing(req, &result) {
+		r.reconcile(ctx, req, runID, &result)
+	}
+
+	return result
+}
+
+// coversFullListing reports whether the run listed every job in its scope, which is required
+// before jobs it did not see can be counted as missing.
+func coversFullListing(req models.ScrapeRequest, result *RunResult) bool {
+	if result.Status != "completed" || result.PageErrors > 0 {
+		return false
+	}
+	if req.Strategy == models.StrategyIncremental || req.StartPage != 0 {
+		return false
+	}
+
+	// Only canton and publication age can be mapped back onto stored jobs
+	body := req.BuildSearchBody()
+	if req.Keywords != "" || req.Permanent != nil || body.WorkloadPercentageMin > 10 || body.WorkloadPercentageMax < 100 {
+		return false
+	}
+
+	if req.Strategy == models.StrategySharded {
+		for _, shard := range result.Shards {
+			if shard.Status != "completed" && shard.Status != "split" {
+				return false
+			}
+		}
+		return true
+	}
+
+	return result.StopReason == "no more jobs"
+}
+
+// reconcile counts a miss for in-scope jobs the run did not list, expires jobs missed too often
+// and re-checks the most suspicious ones individually.
+func (r *Runner) reconcile(ctx context.Context, req models.ScrapeRequest, runID int64, result *RunResult) {
+	daysBack := req.BuildSearchBody().OnlineSince
+
+	missed, err := r.store.CountMissedJobs(ctx, runID, req.Cantons, daysBack)
+	if err != nil {
+		slog.Error("reconciliation failed", "run_id", runID, "error", err)
+		return
+	}
+
+	expired, err := r.store.ExpireMissedJobs(ctx, r.expireAfterRuns)
+	if err != nil {
+		slog.Error("reconciliation failed", "run_id", runID, "error", err)
+		return
+	}
+	result.JobsExpired += expired
+
+	rechecked := 0
+	if r.recheckLimit > 0 {
+		ids, err := r.store.ListSuspectJobs(ctx, r.recheckLimit)
+		if err != nil {
+			slog.Error("failed to list suspect jobs", "run_id", runID, "error", err)
+		}
+		for _, id := range ids {
+			if ctx.Err() != nil {
+				break
+			}
+			rechecked++
+			if _, err := r.source.FetchJobDetail(ctx, id); !errors.Is(err, ErrJobNotFound) {
+				continue
+			}
+			if err := r.store.ExpireJob(ctx, id); err != nil {
+				slog.Error("failed to expire job", "run_id", runID, "id", id, "error", err)
+				continue
+			}
+			result.JobsExpired++
+			slog.Debug("expired removed job", "run_id", runID, "id", id)
+		}
+	}
+
+	slog.Info("reconciliation completed",
+		"run_id", runID,
+		"jobs_missed", missed,
+		"jobs_rechecked", rechecked,
+		"jobs_expired", result.JobsExpired,
+	)
+}
+
+// runSharded scrapes req as a set of shards, splitting every shard the API caps (412)
+// until it fits or cannot be narrowed further. Jobs are deduplicated by ID across shards.
+// MaxPages applies to each shard; StartPage is ignored.
+func (r *Runner) runSharded(ctx context.Context, req models.ScrapeRequest, runID int64, result *RunResult) {
+	type pendingShard struct {
+		req   models.ScrapeRequest
+		depth int
+	}
+
+	root := req
+	root.Strategy = models.StrategyFull
+	root.StartPage = 0
+
+	// A query spanning several cantons is almost certain to hit the cap, so start per canton
+	queue := []pendingShard{{req: root}}
+	if len(root.Cantons) != 1 {
+		queue = queue[:0]
+		for _, shard := range root.Split() {
+			queue = append(queue, pendingShard{req: shard, depth: 1})
+		}
+	}
+
+	seen := make(map[string]bool)
+	capped := 0
+
+	for len(queue) > 0 {
+		if ctx.Err() != nil {
+			result.Status = "cancelled"
+			result.StopReason = "context cancelled"
+			return
+		}
+
+		next := queue[0]
+		queue = queue[1:]
+
+		shard := models.ScrapeShard{Key: next.req.ShardKey(), Depth: next.depth}
+		before := *result
+
+		slog.Info("scraping shard", "run_id", runID, "shard", shard.Key, "depth", shard.Depth, "pending", len(queue))
+
+		stopReason, isCapped := r.scrapePages(ctx, next.req, runID, result, seen, &shard)
+		shard.StopReason = stopReason
+		shard.PagesScraped = result.PagesScraped - before.PagesScraped
+		shard.JobsInserted = result.JobsInserted - before.JobsInserted
+		shard.JobsUpdated = result.JobsUpdated - before.JobsUpdated
+
+		switch {
+		case isCapped:
+			if children := next.req.Split(); children != nil {
+				shard.Status = "split"
+				// Depth-first so narrower shards of the same canton run back to back
+				split := make([]pendingShard, 0, len(children)+len(queue))
+				for _, child := range children {
+					split = append(split, pendingShard{req: child, depth: next.depth + 1})
+				}
+				queue = append(split, queue...)
+			} else {
+				shard.Status = "capped"
+				capped++
+				slog.Warn("shard still capped and cannot be split further, some jobs are missed",
+					"run_id", runID, "shard", shard.Key)
+			}
+		case result.Status == "cancelled" || result.Status == "failed":
+			shard.Status = result.Status
+		default:
+			shard.Status = "completed"
+		}
+
+		result.Shards = append(result.Shards, shard)
+
+		slog.Info("shard finished",
+			"run_id", runID,
+			"shard", shard.Key,
+			"status", shard.Status,
+			"pages_scraped", shard.PagesScraped,
+			"jobs_listed", shard.JobsListed,
+			"jobs_inserted", shard.JobsInserted,
+			"jobs_updated", shard.JobsUpdated,
+			"duplicates", shard.Duplicates,
+		)
+
+		if result.Status == "cancelled" || result.Status == "failed" {
+			return
+		}
+	}
+
+	if capped > 0 {
+		result.StopReason = "sharded: " + strconv.Itoa(capped) + " shard(s) still capped"
+	} else {
+		result.StopReason = "sharded: all shards completed"
+	}
+}
+
+// scrapePages scrapes the pages of a single query into result and returns why it stopped
+// and whether the API refused deeper pages (412).
+// For sharded runs, shard receives per-shard counts and seen holds the job IDs already handled
+// by earlier shards; plain runs pass nil for both and checkpoint every completed page instead.
+func (r *Runner) scrapePages(ctx context.Context, req models.ScrapeRequest, runID int64, result *RunResult, seen map[string]bool, shard *models.ScrapeShard) (stopReason string, capped bool) {
+	var (
+		consecutiveErrors = 0
+		stopScraping      = false
+		filtersHash       = req.FiltersHash()
 	)
 
-pageLoop:
 	for page := req.StartPage; req.MaxPages == 0 || page < req.StartPage+req.MaxPages; page++ {
 		// Check context cancellation
 		select {
 		case <-ctx.Done():
 			result.Status = "cancelled"
-			result.StopReason = "context cancelled"
-			break pageLoop
+			return "context cancelled", false
 		default:
 		}
 
@@ -119,19 +385,31 @@ pageLoop:
 
 		slog.Info("fetching page", "run_id", runID, "page", page)
 
-		jobs, err := r.client.FetchJobsWithRequest(&req, page)
+		fetchStart := time.Now()
+		jobs, err := r.source.ListJobs(ctx, &req, page)
+		if err != nil && ctx.Err() != nil {
+			result.Status = "cancelled"
+			return "context cancelled", false
+		}
 		if err != nil {
-			errMsg := "page " + strconv.Itoa(page) + ": " + err.Error()
-			result.Errors = append(result.Errors, errMsg)
-			slog.Error("failed to fetch jobs page", "run_id", runID, "page", page, "error", err)
-
 			// Check for 412 error (API limit reached)
 			if strings.Contains(err.Error(), "status 412") || strings.Contains(err.Error(), "exceed max result limit") {
+				// A capped shard is split by the caller, so it is not an error there
+				if shard == nil {
+					result.Errors = append(result.Errors, "page "+strconv.Itoa(page)+": "+err.Error())
+					result.addEvent(models.EventPageFailed, page, "", time.Since(fetchStart), err.Error())
+				}
 				slog.Warn("API limit reached (412), stopping scrape", "run_id", runID, "page", page)
-				result.StopReason = "API limit reached (412)"
-				break pageLoop
+				return "API limit reached (412)", true
 			}
 
+			errMsg := "page " + strconv.Itoa(page) + ": " + err.Error()
+			result.Errors = append(result.Errors, errMsg)
+			result.PageErrors++
+			metrics.PageErrors.WithLabelValues(string(r.source.Name())).Inc()
+			result.addEvent(models.EventPageFailed, page, "", time.Since(fetchStart), err.Error())
+			slog.Error("failed to fetch jobs page", "run_id", runID, "page", page, "error", err)
+
 			// Track consecutive errors
 			consecutiveErrors++
 			if consecutiveErrors >= MaxConsecutiveErrors {
@@ -139,9 +417,8 @@ pageLoop:
 					"run_id", runID,
 					"consecutive_errors", consecutiveErrors,
 				)
-				result.StopReason = "too many consecutive errors"
 				result.Status = "failed"
-				break pageLoop
+				return "too many consecutive errors", false
 			}
 			continue
 		}
@@ -149,17 +426,29 @@ pageLoop:
 		// Reset consecutive error counter on success
 		consecutiveErrors = 0
 		result.PagesScraped++
+		metrics.PagesFetched.WithLabelValues(string(r.source.Name())).Inc()
+		result.addEvent(models.EventPageFetched, page, "", time.Since(fetchStart), strconv.Itoa(len(jobs))+" jobs")
 
 		// If no jobs returned, we've reached the end
 		if len(jobs) == 0 {
 			slog.Info("no more jobs found, stopping", "run_id", runID, "page", page)
-			result.StopReason = "no more jobs"
-			break
+			return "no more jobs", false
 		}
 
 		slog.Info("fetched jobs from page", "run_id", runID, "page", page, "count", len(jobs))
 
+		// Decide sequentially which jobs need work so the incremental stop point stays deterministic
+		tasks := make([]jobTask, 0, len(jobs))
 		for _, job := range jobs {
+			if shard != nil {
+				shard.JobsListed++
+				if seen[job.ID] {
+					shard.Duplicates++
+					continue
+				}
+				seen[job.ID] = true
+			}
+
 			result.JobsProcessed++
 
 			// Check if job exists and get its last updated time
@@ -167,12 +456,13 @@ pageLoop:
 			if err != nil {
 				errMsg := "check " + job.ID + ": " + err.Error()
 				result.Errors = append(result.Errors, errMsg)
+				result.addEvent(models.EventStoreFailed, page, job.ID, 0, err.Error())
 				slog.Error("failed to check job", "run_id", runID, "id", job.ID, "error", err)
 				continue
 			}
 
 			// Incremental strategy: stop when we find an unchanged job
-			if req.Strategy == "incremental" && found {
+			if req.Strategy == models.StrategyIncremental && found {
 				if dbUpdatedTime == job.UpdatedTime {
 					slog.Info("up to date point reached, stopping incremental scrape",
 						"run_id", runID,
@@ -181,67 +471,167 @@ pageLoop:
 						"api_updated", job.UpdatedTime,
 					)
 					result.JobsSkipped++
+					metrics.Jobs.WithLabelValues(string(r.source.Name()), "skipped").Inc()
 					stopScraping = true
-					result.StopReason = "incremental: up to date"
-					break pageLoop
+					stopReason = "incremental: up to date"
+					break
 				}
 			}
 
-			// Determine if this is an insert or update
-			isUpdate := found
+			tasks = append(tasks, jobTask{id: job.ID, page: page, isUpdate: found})
+		}
 
-			// Fetch full details for each job
-			detail, err := r.client.FetchJobDetail(job.ID)
-			if err != nil {
-				errMsg := "job " + job.ID + ": " + err.Error()
-				result.Errors = append(result.Errors, errMsg)
-				slog.Error("failed to fetch job detail", "run_id", runID, "id", job.ID, "error", err)
-				continue
-			}
+		// Fetch details and persist with the worker pool, then hand the stored jobs to the AI queue
+		stored := r.processJobs(ctx, runID, tasks, result)
+		if r.aiMode != "" {
+			r.enqueueAI(ctx, runID, page, stored, result)
+		}
 
-			// Store in database
-			if err := r.store.UpsertJob(ctx, detail); err != nil {
-				errMsg := "store " + job.ID + ": " + err.Error()
-				result.Errors = append(result.Errors, errMsg)
-				slog.Error("failed to store job", "run_id", runID, "id", job.ID, "error", err)
-				continue
-			}
+		// Stamp every listed job, including any skipped above, as still present on job-room.ch
+		ids := make([]string, len(jobs))
+		for i, job := range jobs {
+			ids[i] = job.ID
+		}
+		if err := r.store.MarkJobsSeen(context.WithoutCancel(ctx), runID, ids); err != nil {
+			slog.Warn("failed to mark jobs seen", "run_id", runID, "page", page, "error", err)
+		}
+		r.flushEvents(ctx, result)
 
-			if isUpdate {
-				result.JobsUpdated++
-				slog.Debug("updated job", "run_id", runID, "id", job.ID)
-			} else {
-				result.JobsInserted++
-				slog.Debug("inserted job", "run_id", runID, "id", job.ID)
-			}
+		if stopScraping {
+			return stopReason, false
+		}
 
-			// Send to AI service for processing (if configured)
-			if r.aiClient != nil {
-				r.processJobWithAI(ctx, job.ID, &result)
+		// Don't checkpoint a page that was interrupted part-way
+		if ctx.Err() != nil {
+			result.Status = "cancelled"
+			return "context cancelled", false
+		}
+
+		// Every job on the page has been handled; a resume can continue after it
+		if shard == nil {
+			lastJobID := jobs[len(jobs)-1].ID
+			if err := r.store.SaveCheckpoint(ctx, runID, page, lastJobID, filtersHash); err != nil {
+				slog.Warn("failed to save checkpoint", "run_id", runID, "page", page, "error", err)
 			}
 		}
 	}
 
-	return result
+	return stopReason, false
+}
+
+// jobTask is a listed job queued for detail fetching and persistence.
+type jobTask struct {
+	id       string
+	page     int // Listing page the job was found on
+	isUpdate bool
+}
+
+// processJobs runs tasks on up to r.workers goroutines, waits for them to finish and returns
+// the IDs of the jobs that were stored.
+// Requests still go through the shared source, so polite mode limits the combined rate.
+// No new tasks are started once ctx is cancelled.
+func (r *Runner) processJobs(ctx context.Context, runID int64, tasks []jobTask, result *RunResult) []string {
+	var (
+		mu     sync.Mutex // guards result and stored
+		wg     sync.WaitGroup
+		queue  = make(chan jobTask)
+		stored []string
+	)
+
+	workers := min(max(r.workers, 1), len(tasks))
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range queue {
+				if r.processJob(ctx, runID, task, result, &mu) {
+					mu.Lock()
+					stored = append(stored, task.id)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, task := range tasks {
+		select {
+		case queue <- task:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return stored
 }
 
-// processJobWithAI sends a job to the AI service for processing.
-func (r *Runner) processJobWithAI(ctx context.Context, jobID string, result *RunResult) {
-	resp, err := r.aiClient.ProcessJob(ctx, jobID)
+// processJob fetches and stores a single job, recording the outcome under mu.
+// It reports whether the job was stored.
+func (r *Runner) processJob(ctx context.Context, runID int64, task jobTask, result *RunResult, mu *sync.Mutex) bool {
+	start := time.Now()
+	addError := func(typ models.RunEventType, errMsg string, err error) {
+		mu.Lock()
+		result.Errors = append(result.Errors, errMsg)
+		result.addEvent(typ, task.page, task.id, time.Since(start), err.Error())
+		mu.Unlock()
+	}
+
+	// Fetch full details for the job
+	detail, err := r.source.FetchJobDetail(ctx, task.id)
+	if err != nil && ctx.Err() != nil {
+		// Interrupted by shutdown; the job is picked up again by the next run
+		return false
+	}
 	if err != nil {
-		slog.Warn("AI processing failed",
-			"job_id", jobID,
-			"error", err,
-		)
-		result.AIJobsFailed++
-		return
+		addError(models.EventDetailFailed, "job "+task.id+": "+err.Error(), err)
+		slog.Error("failed to fetch job detail", "run_id", runID, "id", task.id, "error", err)
+		return false
 	}
 
-	if resp.Skipped {
-		result.AIJobsSkipped++
-		slog.Debug("AI processing skipped", "job_id", jobID, "reason", resp.SkipReason)
+	// Store in database
+	if err := r.store.UpsertJob(ctx, detail); err != nil {
+		addError(models.EventStoreFailed, "store "+task.id+": "+err.Error(), err)
+		slog.Error("failed to store job", "run_id", runID, "id", task.id, "error", err)
+		return false
+	}
+
+	mu.Lock()
+	if task.isUpdate {
+		result.JobsUpdated++
+		metrics.Jobs.WithLabelValues(string(r.source.Name()), "updated").Inc()
+		result.addEvent(models.EventJobUpdated, task.page, task.id, time.Since(start), "")
 	} else {
-		result.AIJobsProcessed++
-		slog.Debug("AI processing completed", "job_id", jobID, "saved_to_db", resp.SavedToDB)
+		result.JobsInserted++
+		metrics.Jobs.WithLabelValues(string(r.source.Name()), "inserted").Inc()
+		result.addEvent(models.EventJobInserted, task.page, task.id, time.Since(start), "")
+	}
+	mu.Unlock()
+	slog.Debug("stored job", "run_id", runID, "id", task.id, "update", task.isUpdate)
+
+	return true
+}
+
+// enqueueAI hands the jobs stored from a page to the AI queue. The jobs are already stored,
+// so they are queued even if the run is being cancelled; a failure is recorded but not retried.
+func (r *Runner) enqueueAI(ctx context.Context, runID int64, page int, ids []string, result *RunResult) {
+	if len(ids) == 0 {
+		return
 	}
+	start := time.Now()
+	queued, err := r.store.EnqueueAIJobs(context.WithoutCancel(ctx), runID, r.aiMode, ids)
+	if err != nil {
+		result.AIJobsFailed += len(ids)
+		metrics.AIHandoffs.WithLabelValues("failed").Add(float64(len(ids)))
+		result.Errors = append(result.Errors, "page "+strconv.Itoa(page)+" AI queue: "+err.Error())
+		result.addEvent(models.EventAIFailed, page, "", time.Since(start), err.Error())
+		slog.Error("failed to queue jobs for AI processing", "run_id", runID, "page", page, "jobs", len(ids), "error", err)
+		return
+	}
+
+	result.AIJobsQueued += queued
+	result.AIJobsSkipped += len(ids) - queued
+	metrics.AIHandoffs.WithLabelValues("queued").Add(float64(queued))
+	metrics.AIHandoffs.WithLabelValues("skipped").Add(float64(len(ids) - queued))
+	slog.Debug("queued jobs for AI processing", "run_id", runID, "page", page, "queued", queued, "already_queued", len(ids)-queued)
 }
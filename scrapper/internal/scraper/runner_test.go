@@ -0,0 +1,272 @@
+package scraper
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"scrapper/internal/metrics"
+	"scrapper/internal/models"
+)
+
+// memoryStore is an in-memory RunStore for running the Runner without PostgreSQL.
+type memoryStore struct {
+	mu sync.Mutex
+
+	jobs       map[string]string // job ID -> stored updatedTime
+	upserted   []string
+	seen       map[string]bool
+	queued     map[string]bool
+	checkpoint struct {
+		page      int
+		lastJobID string
+	}
+	status       string
+	events       []models.RunEvent
+	reconciled   bool
+	shardsSaved  []models.ScrapeShard
+	pagesScraped int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		jobs:   make(map[string]string),
+		seen:   make(map[string]bool),
+		queued: make(map[string]bool),
+	}
+}
+
+func (m *memoryStore) GetJobLastUpdated(ctx context.Context, id string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	updated, ok := m.jobs[id]
+	return updated, ok, nil
+}
+
+func (m *memoryStore) UpsertJob(ctx context.Context, job *models.JobDetail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID] = job.UpdatedTime
+	m.upserted = append(m.upserted, job.ID)
+	return nil
+}
+
+func (m *memoryStore) EnqueueAIJobs(ctx context.Context, runID int64, mode string, ids []string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	queued := 0
+	for _, id := range ids {
+		if !m.queued[id] {
+			m.queued[id] = true
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+func (m *memoryStore) MarkJobsSeen(ctx context.Context, runID int64, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		m.seen[id] = true
+	}
+	return nil
+}
+
+func (m *memoryStore) SaveCheckpoint(ctx context.Context, runID int64, page int, lastJobID, filtersHash string) error {
+	m.checkpoint.page = page
+	m.checkpoint.lastJobID = lastJobID
+	return nil
+}
+
+func (m *memoryStore) SaveRunShards(ctx context.Context, runID int64, shards []models.ScrapeShard) error {
+	m.shardsSaved = shards
+	return nil
+}
+
+func (m *memoryStore) UpdateRun(ctx context.Context, runID int64, status string, counters models.RunCounters, errLog string) error {
+	m.status = status
+	m.pagesScraped = counters.PagesScraped
+	return nil
+}
+
+func (m *memoryStore) RecordRunEvents(ctx context.Context, events []models.RunEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *memoryStore) CountMissedJobs(ctx context.Context, runID int64, cantons []string, daysBack int) (int, error) {
+	m.reconciled = true
+	return 0, nil
+}
+
+func (m *memoryStore) ExpireMissedJobs(ctx context.Context, minMissed int) (int, error) {
+	return 0, nil
+}
+
+func (m *memoryStore) ListSuspectJobs(ctx context.Context, limit int) ([]string, error) {
+	return nil, nil
+}
+
+func (m *memoryStore) ExpireJob(ctx context.Context, id string) error {
+	return nil
+}
+
+// runFixtures runs req against the recorded job-room fixtures.
+func runFixtures(t *testing.T, st *memoryStore, req models.ScrapeRequest, workers int) RunResult {
+	t.Helper()
+
+	runner := NewRunner(st, newFixtureClient(t, FixtureReplay, fixtureDir))
+	runner.SetWorkers(workers)
+	runner.SetExpiry(3, 0)
+	return runner.Run(context.Background(), req, 1)
+}
+
+func TestRunnerIncrementalStopsAtUnchangedJob(t *testing.T) {
+	st := newMemoryStore()
+	st.jobs[stubJobs[0].id] = "2024-05-01T00:00:00.000Z" // changed since the last scrape
+	st.jobs[stubJobs[1].id] = stubJobs[1].updated        // unchanged: the stop point
+
+	result := runFixtures(t, st, fixtureRequest(models.StrategyIncremental), 1)
+
+	if result.StopReason != "incremental: up to date" {
+		t.Errorf("StopReason = %q, want incremental: up to date", result.StopReason)
+	}
+	if result.Status != "completed" || st.status != "completed" {
+		t.Errorf("status = %q (stored %q), want completed", result.Status, st.status)
+	}
+	if result.PagesScraped != 1 || result.JobsUpdated != 1 || result.JobsInserted != 0 || result.JobsSkipped != 1 {
+		t.Errorf("pages=%d updated=%d inserted=%d skipped=%d, want 1/1/0/1",
+			result.PagesScraped, result.JobsUpdated, result.JobsInserted, result.JobsSkipped)
+	}
+	if len(st.upserted) != 1 || st.upserted[0] != stubJobs[0].id {
+		t.Errorf("upserted %v, want only %s", st.upserted, stubJobs[0].id)
+	}
+
+	// Jobs after the stop point on the same page are still listed, so they count as seen
+	for _, s := range stubJobs[:stubPageSize] {
+		if !st.seen[s.id] {
+			t.Errorf("job %s on the first page not marked seen", s.id)
+		}
+	}
+	if st.reconciled {
+		t.Error("incremental run must not reconcile")
+	}
+}
+
+func TestRunnerIncrementalContinuesAcrossPages(t *testing.T) {
+	st := newMemoryStore()
+	st.jobs[stubJobs[1].id] = "2024-05-01T00:00:00.000Z"
+	st.jobs[stubJobs[4].id] = stubJobs[4].updated
+
+	result := runFixtures(t, st, fixtureRequest(models.StrategyIncremental), 4)
+
+	if result.StopReason != "incremental: up to date" {
+		t.Errorf("StopReason = %q, want incremental: up to date", result.StopReason)
+	}
+	if result.PagesScraped != 2 || result.JobsInserted != 3 || result.JobsUpdated != 1 || result.JobsSkipped != 1 {
+		t.Errorf("pages=%d inserted=%d updated=%d skipped=%d, want 2/3/1/1",
+			result.PagesScraped, result.JobsInserted, result.JobsUpdated, result.JobsSkipped)
+	}
+	if st.checkpoint.page != 0 || st.checkpoint.lastJobID != stubJobs[stubPageSize-1].id {
+		t.Errorf("checkpoint = page %d / %s, want page 0 / %s",
+			st.checkpoint.page, st.checkpoint.lastJobID, stubJobs[stubPageSize-1].id)
+	}
+}
+
+func TestRunnerStopsAtResultCap(t *testing.T) {
+	st := newMemoryStore()
+
+	// Metrics are process-wide, so compare against their values before the run
+	source := string(models.SourceJobRoom)
+	pagesBefore := testutil.ToFloat64(metrics.PagesFetched.WithLabelValues(source))
+	insertedBefore := testutil.ToFloat64(metrics.Jobs.WithLabelValues(source, "inserted"))
+	cappedBefore := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(endpointJobRoomSearch, "412"))
+
+	result := runFixtures(t, st, fixtureRequest(models.StrategyFull), 2)
+
+	if got := testutil.ToFloat64(metrics.PagesFetched.WithLabelValues(source)) - pagesBefore; got != stubCappedPage {
+		t.Errorf("pages_fetched_total grew by %v, want %d", got, stubCappedPage)
+	}
+	if got := testutil.ToFloat64(metrics.Jobs.WithLabelValues(source, "inserted")) - insertedBefore; got != float64(len(stubJobs)) {
+		t.Errorf("jobs_total{outcome=inserted} grew by %v, want %d", got, len(stubJobs))
+	}
+	if got := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(endpointJobRoomSearch, "412")) - cappedBefore; got != 1 {
+		t.Errorf("http_requests_total{code=412} grew by %v, want 1", got)
+	}
+
+	if result.StopReason != "API limit reached (412)" {
+		t.Errorf("StopReason = %q, want API limit reached (412)", result.StopReason)
+	}
+	if result.Status != "completed" {
+		t.Errorf("Status = %q, want completed (jobs were stored before the cap)", result.Status)
+	}
+	if result.PagesScraped != stubCappedPage || result.JobsInserted != len(stubJobs) {
+		t.Errorf("pages=%d inserted=%d, want %d/%d", result.PagesScraped, result.JobsInserted, stubCappedPage, len(stubJobs))
+	}
+	if len(result.Errors) != 1 || result.PageErrors != 0 {
+		t.Errorf("errors=%v pageErrors=%d, want one 412 error and no page errors", result.Errors, result.PageErrors)
+	}
+
+	// The last complete page is checkpointed so the run can be resumed
+	if st.checkpoint.page != stubCappedPage-1 || st.checkpoint.lastJobID != stubJobs[len(stubJobs)-1].id {
+		t.Errorf("checkpoint = page %d / %s, want page %d / %s",
+			st.checkpoint.page, st.checkpoint.lastJobID, stubCappedPage-1, stubJobs[len(stubJobs)-1].id)
+	}
+
+	// A capped run did not see its whole scope, so nothing may be counted as missing
+	if st.reconciled {
+		t.Error("capped run must not reconcile")
+	}
+
+	counts := make(map[models.RunEventType]int)
+	for _, e := range st.events {
+		counts[e.Type]++
+	}
+	if counts[models.EventPageFetched] != stubCappedPage || counts[models.EventJobInserted] != len(stubJobs) ||
+		counts[models.EventPageFailed] != 1 || counts[models.EventStopped] != 1 {
+		t.Errorf("event counts = %v", counts)
+	}
+	if last := st.events[len(st.events)-1]; last.Type != models.EventStopped || last.Message == nil ||
+		*last.Message != "completed: API limit reached (412)" {
+		t.Errorf("last event = %+v, want stopped with the stop reason", last)
+	}
+}
+
+func TestRunnerMaxPagesBeforeCap(t *testing.T) {
+	st := newMemoryStore()
+	req := fixtureRequest(models.StrategyFull)
+	req.MaxPages = 1
+
+	result := runFixtures(t, st, req, 1)
+
+	if result.PagesScraped != 1 || result.JobsInserted != stubPageSize || len(result.Errors) != 0 {
+		t.Errorf("pages=%d inserted=%d errors=%v, want 1/%d/none",
+			result.PagesScraped, result.JobsInserted, result.Errors, stubPageSize)
+	}
+}
+
+func TestRunnerQueuesStoredJobsForAI(t *testing.T) {
+	st := newMemoryStore()
+	st.queued[stubJobs[0].id] = true // still waiting from an earlier run
+	req := fixtureRequest(models.StrategyFull)
+	req.MaxPages = 1
+
+	runner := NewRunner(st, newFixtureClient(t, FixtureReplay, fixtureDir))
+	runner.SetAIHandoff("process")
+	result := runner.Run(context.Background(), req, 1)
+
+	if result.AIJobsQueued != stubPageSize-1 || result.AIJobsSkipped != 1 || result.AIJobsFailed != 0 {
+		t.Errorf("queued=%d skipped=%d failed=%d, want %d/1/0",
+			result.AIJobsQueued, result.AIJobsSkipped, result.AIJobsFailed, stubPageSize-1)
+	}
+	for _, s := range stubJobs[:stubPageSize] {
+		if !st.queued[s.id] {
+			t.Errorf("job %s not queued", s.id)
+		}
+	}
+}
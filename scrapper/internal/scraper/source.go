@@ -0,0 +1,44 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"scrapper/internal/models"
+)
+
+// Source is a job board the Runner can scrape.
+// Implementations map their own format to models.JobDetail, must be safe for concurrent use
+// and must return promptly once ctx is cancelled.
+type Source interface {
+	// Name returns the value stored in jobs.source for jobs from this source.
+	Name() models.JobSource
+
+	// ListJobs returns one page of listings for req. An empty page ends the scrape.
+	// Listed jobs need an ID and UpdatedTime; details are loaded with FetchJobDetail.
+	ListJobs(ctx context.Context, req *models.ScrapeRequest, page int) ([]models.JobDetail, error)
+
+	// FetchJobDetail returns the full job. It returns an error wrapping ErrJobNotFound
+	// if the source no longer has the job.
+	FetchJobDetail(ctx context.Context, id string) (*models.JobDetail, error)
+}
+
+// NewSource creates the Source selected by req.Source (job-room.ch when empty).
+func NewSource(req *models.ScrapeRequest, cfg ClientConfig) (Source, error) {
+	switch models.JobSource(strings.ToLower(req.Source)) {
+	case "", models.SourceJobRoom:
+		client, err := NewClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case models.SourceCareerPage:
+		if len(req.URLs) == 0 {
+			return nil, fmt.Errorf("source %s requires at least one URL", models.SourceCareerPage)
+		}
+		return NewCareerPageSource(req.URLs, cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown source: %s", req.Source)
+	}
+}
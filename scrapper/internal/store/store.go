@@ -29,13 +29,15 @@ func (s *Store) DB() *sqlx.DB {
 
 // UpsertJob inserts or updates a job and all its related child records.
 // Uses a transaction to ensure ACID compliance.
-// Companies and locations are deduplicated by unique key lookup.
+// Companies and locations are deduplicated by unique key lookup,
+// and the job is clustered with near-duplicates by its computed fingerprint.
 func (s *Store) UpsertJob(ctx context.Context, job *models.JobDetail) error {
 	// Step 1: Marshal job to JSON for raw_data storage
 	rawData, err := json.Marshal(job)
 	if err != nil {
 		return fmt.Errorf("failed to marshal job to JSON: %w", err)
 	}
+	fingerprint := job.ComputeFingerprint()
 
 	// Step 2: Begin transaction
 	tx, err := s.db.BeginTxx(ctx, nil)
@@ -56,13 +58,24 @@ func (s *Store) UpsertJob(ctx context.Context, job *models.JobDetail) error {
 		return fmt.Errorf("failed to get/create location: %w", err)
 	}
 
-	// Step 5: Upsert parent job record
+	// Step 5: Record what changed if the job was updated since it was last stored
+	if err := recordRevision(ctx, tx, job); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	// Step 6: Upsert parent job record
+	source := job.Source
+	if source == "" {
+		source = models.SourceJobRoom
+	}
 	_, err = tx.ExecContext(ctx, `
 		INSERT INTO jobs (
 			id, source, created_time, updated_time, status, source_system,
 			external_ref, stellennummer_egov, fingerprint, reporting_obligation,
 			raw_data, company_id, location_id, created_at, updated_at
-		) VALUES ($1, 'jobroom', $2, $3, 'active', $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+		) VALUES (
+			$1, $12, COALESCE(NULLIF($2, '')::timestamptz, NOW()), COALESCE(NULLIF($3, '')::timestamptz, NOW()),
+			'active', $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
 		ON CONFLICT (id) DO UPDATE SET
 			updated_time = EXCLUDED.updated_time,
 			status = EXCLUDED.status,
@@ -81,17 +94,18 @@ func (s *Store) UpsertJob(ctx context.Context, job *models.JobDetail) error {
 		job.SourceSystem,
 		job.ExternalReference,
 		job.StellennummerEgov,
-		job.Fingerprint,
+		fingerprint.String(),
 		job.ReportingObligation,
 		string(rawData),
 		companyID,
 		locationID,
+		source,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to upsert job: %w", err)
 	}
 
-	// Step 6: Clean child tables
+	// Step 7: Clean child tables
 	childTables := []string{
 		"employments",
 		"publications",
@@ -106,9 +120,9 @@ func (s *Store) UpsertJob(ctx context.Context, job *models.JobDetail) error {
 		}
 	}
 
-	// Step 7: Insert child records
+	// Step 8: Insert child records
 
-	// 7a: Insert employment
+	// 8a: Insert employment
 	_, err = tx.ExecContext(ctx, `
 		INSERT INTO employments (
 			job_id, start_date, end_date, short_employment,
@@ -127,7 +141,7 @@ func (s *Store) UpsertJob(ctx context.Context, job *models.JobDetail) error {
 		return fmt.Errorf("failed to insert employment: %w", err)
 	}
 
-	// 7b: Insert publication
+	// 8b: Insert publication
 	_, err = tx.ExecContext(ctx, `
 		INSERT INTO publications (
 			job_id, start_date, end_date, eures_display,
@@ -145,7 +159,7 @@ func (s *Store) UpsertJob(ctx context.Context, job *models.JobDetail) error {
 		return fmt.Errorf("failed to insert publication: %w", err)
 	}
 
-	// 7c: Insert apply channel
+	// 8c: Insert apply channel
 	_, err = tx.ExecContext(ctx, `
 		INSERT INTO apply_channels (
 			job_id, raw_post_address, post_address, email_address,
@@ -163,7 +177,7 @@ func (s *Store) UpsertJob(ctx context.Context, job *models.JobDetail) error {
 		return fmt.Errorf("failed to insert apply_channel: %w", err)
 	}
 
-	// 7d: Insert job descriptions (one-to-many)
+	// 8d: Insert job descriptions (one-to-many)
 	for _, desc := range job.JobContent.JobDescriptions {
 		_, err = tx.ExecContext(ctx, `
 			INSERT INTO job_descriptions (
@@ -183,7 +197,7 @@ func (s *Store) UpsertJob(ctx context.Context, job *models.JobDetail) error {
 		}
 	}
 
-	// 7e: Insert occupations (one-to-many)
+	// 8e: Insert occupations (one-to-many)
 	for _, occ := range job.JobContent.Occupations {
 		_, err = tx.ExecContext(ctx, `
 			INSERT INTO occupations (
@@ -201,7 +215,12 @@ func (s *Store) UpsertJob(ctx context.Context, job *models.JobDetail) error {
 		}
 	}
 
-	// Step 8: Commit transaction
+	// Step 9: Place the job in its duplicate cluster
+	if err := assignCluster(ctx, tx, job.ID, fingerprint); err != nil {
+		return fmt.Errorf("failed to assign cluster: %w", err)
+	}
+
+	// Step 10: Commit transaction
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -209,6 +228,245 @@ func (s *Store) UpsertJob(ctx context.Context, job *models.JobDetail) error {
 	return nil
 }
 
+// assignCluster moves the job into the cluster of its nearest duplicate, or into a new cluster
+// of its own when no stored job is near. A job stays in its cluster while its fingerprint key
+// is unchanged. Expects the job row to exist with the fingerprint already stored.
+func assignCluster(ctx context.Context, tx *sqlx.Tx, jobID string, fp models.Fingerprint) error {
+	// Serialize clustering per key so concurrent workers don't open two clusters for one vacancy
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", fp.Key); err != nil {
+		return fmt.Errorf("failed to lock fingerprint key: %w", err)
+	}
+
+	var (
+		currentID  sql.NullInt64
+		currentKey sql.NullString
+	)
+	err := tx.QueryRowxContext(ctx, `
+		SELECT j.cluster_id, c.fingerprint_key
+		FROM jobs j LEFT JOIN job_clusters c ON c.id = j.cluster_id
+		WHERE j.id = $1`,
+		jobID,
+	).Scan(&currentID, &currentKey)
+	if err != nil {
+		return fmt.Errorf("failed to load current cluster: %w", err)
+	}
+	if currentID.Valid && currentKey.String == fp.Key {
+		return refreshClusters(ctx, tx, []int64{currentID.Int64})
+	}
+
+	clusterID, err := findNearCluster(ctx, tx, jobID, fp)
+	if err != nil {
+		return err
+	}
+	if clusterID == 0 {
+		err = tx.QueryRowxContext(ctx, `
+			INSERT INTO job_clusters (fingerprint_key, created_at, updated_at)
+			VALUES ($1, NOW(), NOW())
+			RETURNING id`,
+			fp.Key,
+		).Scan(&clusterID)
+		if err != nil {
+			return fmt.Errorf("failed to insert cluster: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE jobs SET cluster_id = $2 WHERE id = $1", jobID, clusterID); err != nil {
+		return fmt.Errorf("failed to set job cluster: %w", err)
+	}
+
+	affected := []int64{clusterID}
+	if currentID.Valid {
+		affected = append(affected, currentID.Int64)
+	}
+	return refreshClusters(ctx, tx, affected)
+}
+
+// findNearCluster returns the cluster of the stored job nearest to the fingerprint, or 0 if none is near.
+func findNearCluster(ctx context.Context, tx *sqlx.Tx, jobID string, fp models.Fingerprint) (int64, error) {
+	rows, err := tx.QueryxContext(ctx, `
+		SELECT j.fingerprint, j.cluster_id
+		FROM jobs j JOIN job_clusters c ON c.id = j.cluster_id
+		WHERE c.fingerprint_key = $1 AND j.id <> $2`,
+		fp.Key, jobID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find cluster candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		best         int64
+		bestDistance = models.MaxSimhashDistance + 1
+	)
+	for rows.Next() {
+		var (
+			raw       string
+			clusterID int64
+		)
+		if err := rows.Scan(&raw, &clusterID); err != nil {
+			return 0, fmt.Errorf("failed to scan cluster candidate: %w", err)
+		}
+		candidate, err := models.ParseFingerprint(raw)
+		if err != nil {
+			continue
+		}
+		if d := fp.Distance(candidate); fp.Near(candidate) && d < bestDistance {
+			best, bestDistance = clusterID, d
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read cluster candidates: %w", err)
+	}
+	return best, nil
+}
+
+// refreshClusters recomputes size and canonical job of the given clusters and drops empty ones.
+// The canonical job is the active member, preferring job-room, then the most recently updated.
+func refreshClusters(ctx context.Context, db sqlx.ExecerContext, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, `
+		UPDATE job_clusters c SET
+			size = (SELECT COUNT(*) FROM jobs j WHERE j.cluster_id = c.id),
+			canonical_job_id = (
+				SELECT j.id FROM jobs j WHERE j.cluster_id = c.id
+				ORDER BY (j.status = 'active') DESC, (j.source = 'jobroom') DESC, j.updated_time DESC, j.id
+				LIMIT 1),
+			updated_at = NOW()
+		WHERE c.id = ANY($1)`,
+		ids,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to refresh clusters: %w", err)
+	}
+	_, err = db.ExecContext(ctx, "DELETE FROM job_clusters WHERE id = ANY($1) AND size = 0", ids)
+	if err != nil {
+		return fmt.Errorf("failed to delete empty clusters: %w", err)
+	}
+	return nil
+}
+
+// BackfillClusters fingerprints and clusters stored jobs that have no cluster yet, such as jobs
+// stored before duplicate detection existed. Processes up to batchSize jobs per query and
+// returns the number of jobs clustered.
+func (s *Store) BackfillClusters(ctx context.Context, batchSize int) (int, error) {
+	total := 0
+	for {
+		var rows []struct {
+			ID      string `db:"id"`
+			RawData string `db:"raw_data"`
+		}
+		err := s.db.SelectContext(ctx, &rows,
+			"SELECT id, raw_data FROM jobs WHERE cluster_id IS NULL ORDER BY id LIMIT $1", batchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to list unclustered jobs: %w", err)
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		for _, row := range rows {
+			var job models.JobDetail
+			if err := json.Unmarshal([]byte(row.RawData), &job); err != nil {
+				return total, fmt.Errorf("failed to decode job %s: %w", row.ID, err)
+			}
+			if err := s.clusterStoredJob(ctx, row.ID, job.ComputeFingerprint()); err != nil {
+				return total, fmt.Errorf("failed to cluster job %s: %w", row.ID, err)
+			}
+			total++
+		}
+	}
+}
+
+// clusterStoredJob stores a fingerprint for an existing job and assigns its cluster.
+func (s *Store) clusterStoredJob(ctx context.Context, jobID string, fp models.Fingerprint) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE jobs SET fingerprint = $2 WHERE id = $1", jobID, fp.String()); err != nil {
+		return fmt.Errorf("failed to store fingerprint: %w", err)
+	}
+	if err := assignCluster(ctx, tx, jobID, fp); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetJobCluster returns the cluster a job belongs to and the IDs of all its members.
+// Returns nil if the job is not clustered.
+func (s *Store) GetJobCluster(ctx context.Context, jobID string) (*models.JobCluster, []string, error) {
+	var cluster models.JobCluster
+	err := s.db.GetContext(ctx, &cluster, `
+		SELECT c.id, c.fingerprint_key, c.canonical_job_id, c.size, c.created_at, c.updated_at
+		FROM job_clusters c JOIN jobs j ON j.cluster_id = c.id
+		WHERE j.id = $1`,
+		jobID,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get job cluster: %w", err)
+	}
+
+	var members []string
+	err = s.db.SelectContext(ctx, &members,
+		"SELECT id FROM jobs WHERE cluster_id = $1 ORDER BY updated_time DESC, id", cluster.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list cluster members: %w", err)
+	}
+	return &cluster, members, nil
+}
+
+// recordRevision stores a structured diff against the currently stored version of the job
+// when its updatedTime changed. New jobs and updates without field changes are not recorded.
+func recordRevision(ctx context.Context, tx *sqlx.Tx, job *models.JobDetail) error {
+	var (
+		prevRaw     string
+		prevUpdated time.Time
+	)
+	err := tx.QueryRowxContext(ctx,
+		"SELECT raw_data, updated_time FROM jobs WHERE id = $1 FOR UPDATE", job.ID,
+	).Scan(&prevRaw, &prevUpdated)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load previous job: %w", err)
+	}
+
+	var prev models.JobDetail
+	if err := json.Unmarshal([]byte(prevRaw), &prev); err != nil {
+		return fmt.Errorf("failed to decode previous job: %w", err)
+	}
+	changes, err := models.RevisionChanges(&prev, job)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal changes: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO job_revisions (job_id, previous_updated_time, updated_time, changes, created_at)
+		VALUES ($1, $2, $3, $4, NOW())`,
+		job.ID, prevUpdated, job.UpdatedTime, string(changesJSON),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert revision: %w", err)
+	}
+	return nil
+}
+
 // getOrCreateCompany finds an existing company by unique key or creates a new one.
 func getOrCreateCompany(ctx context.Context, tx *sqlx.Tx, company *models.Company) (int64, error) {
 	// Try to find existing company by unique key (name + postal_code + city)
@@ -303,23 +561,158 @@ func (s *Store) GetJobLastUpdated(ctx context.Context, id string) (string, bool,
 	return updatedTime, true, nil
 }
 
+// MarkJobsSeen stamps the given jobs as listed by a run and clears their missed-run counter.
+// IDs that are not stored yet are ignored.
+func (s *Store) MarkJobsSeen(ctx context.Context, runID int64, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET last_seen_at = NOW(), last_seen_run_id = $1, missed_runs = 0
+		WHERE id = ANY($2)`,
+		runID, ids,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark jobs seen: %w", err)
+	}
+	return nil
+}
+
+// EnqueueAIJobs hands stored jobs to ai_job_processing through ai_job_queue and returns how many
+// were queued. Jobs already pending or being processed for the same mode are not queued twice.
+func (s *Store) EnqueueAIJobs(ctx context.Context, runID int64, mode string, ids []string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO ai_job_queue (job_id, mode, run_id)
+		SELECT id, $2, $1 FROM unnest($3::text[]) AS id
+		ON CONFLICT (job_id, mode) WHERE status IN ('pending', 'processing') DO NOTHING`,
+		runID, mode, ids,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue AI jobs: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// CountMissedJobs increments missed_runs for active job-room jobs in scope that the run did not list.
+// The scope is limited to the given cantons (all if empty) and to jobs published within daysBack,
+// since older postings drop out of the listing without being removed.
+func (s *Store) CountMissedJobs(ctx context.Context, runID int64, cantons []string, daysBack int) (int, error) {
+	if cantons == nil {
+		cantons = []string{}
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE jobs j
+		SET missed_runs = j.missed_runs + 1
+		WHERE j.source = 'jobroom' AND j.status = 'active'
+		  AND j.last_seen_run_id IS DISTINCT FROM $1
+		  AND (cardinality($2::text[]) = 0 OR EXISTS (
+		      SELECT 1 FROM locations l WHERE l.id = j.location_id AND l.canton_code = ANY($2)))
+		  AND EXISTS (
+		      SELECT 1 FROM publications p
+		      WHERE p.job_id = j.id AND p.start_date > CURRENT_DATE - $3::int)`,
+		runID, cantons, daysBack,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count missed jobs: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// ExpireMissedJobs marks active jobs missed by at least minMissed consecutive runs as expired.
+// Clusters of expired jobs get a new canonical job if another member is still active.
+func (s *Store) ExpireMissedJobs(ctx context.Context, minMissed int) (int, error) {
+	var clusterIDs []sql.NullInt64
+	err := s.db.SelectContext(ctx, &clusterIDs, `
+		UPDATE jobs SET status = 'expired', updated_at = NOW()
+		WHERE source = 'jobroom' AND status = 'active' AND missed_runs >= $1
+		RETURNING cluster_id`,
+		minMissed,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire missed jobs: %w", err)
+	}
+	if err := refreshClusters(ctx, s.db, validIDs(clusterIDs)); err != nil {
+		return 0, err
+	}
+	return len(clusterIDs), nil
+}
+
+// ListSuspectJobs returns IDs of active jobs that were missed by at least one run, most missed first.
+func (s *Store) ListSuspectJobs(ctx context.Context, limit int) ([]string, error) {
+	var ids []string
+	err := s.db.SelectContext(ctx, &ids, `
+		SELECT id FROM jobs
+		WHERE source = 'jobroom' AND status = 'active' AND missed_runs > 0
+		ORDER BY missed_runs DESC, last_seen_at NULLS FIRST
+		LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suspect jobs: %w", err)
+	}
+	return ids, nil
+}
+
+// ExpireJob marks a single job as expired.
+func (s *Store) ExpireJob(ctx context.Context, id string) error {
+	var clusterIDs []sql.NullInt64
+	err := s.db.SelectContext(ctx, &clusterIDs, `
+		UPDATE jobs SET status = 'expired', updated_at = NOW()
+		WHERE id = $1 AND status = 'active'
+		RETURNING cluster_id`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to expire job: %w", err)
+	}
+	return refreshClusters(ctx, s.db, validIDs(clusterIDs))
+}
+
 // ListJobs returns a paginated list of job summaries.
-func (s *Store) ListJobs(ctx context.Context, limit, offset int) ([]models.JobSummary, error) {
+// With collapse set, only the canonical job of each duplicate cluster is listed.
+func (s *Store) ListJobs(ctx context.Context, limit, offset int, collapse bool) ([]models.JobSummary, error) {
 	var jobs []models.JobSummary
 	err := s.db.SelectContext(ctx, &jobs,
-		`SELECT id, status, source, created_time, updated_time 
-		 FROM jobs ORDER BY updated_time DESC LIMIT $1 OFFSET $2`,
-		limit, offset)
+		`SELECT j.id, j.status, j.source, j.created_time, j.updated_time,
+		        GREATEST(COALESCE(c.size, 1) - 1, 0) AS duplicates
+		 FROM jobs j LEFT JOIN job_clusters c ON c.id = j.cluster_id
+		 WHERE NOT $3 OR c.id IS NULL OR c.canonical_job_id = j.id
+		 ORDER BY j.updated_time DESC LIMIT $1 OFFSET $2`,
+		limit, offset, collapse)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list jobs: %w", err)
 	}
 	return jobs, nil
 }
 
+// ListJobRevisions returns the recorded revisions of a job, newest first.
+func (s *Store) ListJobRevisions(ctx context.Context, jobID string) ([]models.JobRevision, error) {
+	var revisions []models.JobRevision
+	err := s.db.SelectContext(ctx, &revisions,
+		`SELECT id, job_id, previous_updated_time, updated_time, changes, created_at
+		 FROM job_revisions WHERE job_id = $1 ORDER BY updated_time DESC, id DESC`,
+		jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job revisions: %w", err)
+	}
+	return revisions, nil
+}
+
 // CountJobs returns the total count of jobs in the database.
-func (s *Store) CountJobs(ctx context.Context) (int, error) {
+// With collapse set, each duplicate cluster counts once.
+func (s *Store) CountJobs(ctx context.Context, collapse bool) (int, error) {
+	table := "jobs"
+	if collapse {
+		table = "canonical_jobs"
+	}
 	var count int
-	err := s.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM jobs")
+	err := s.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM "+table)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count jobs: %w", err)
 	}
@@ -341,8 +734,57 @@ func (s *Store) CreateRun(ctx context.Context, strategy string, filters string)
 	return id, nil
 }
 
+// CreateProfileRun creates a new scrape run triggered by a scrape profile and returns its ID.
+func (s *Store) CreateProfileRun(ctx context.Context, profileID int64, strategy string, filters string) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO scrape_runs (profile_id, strategy, start_time, status, filters, created_at, updated_at)
+		VALUES ($1, $2, NOW(), 'running', $3, NOW(), NOW())
+		RETURNING id`,
+		profileID, strategy, nullableStringPtr(filters),
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create profile run: %w", err)
+	}
+	return id, nil
+}
+
+// CreateResumedRun creates a new scrape run that continues from parent and returns its ID.
+// The profile linkage and strategy are inherited from the parent run.
+func (s *Store) CreateResumedRun(ctx context.Context, parent *models.ScrapeRun, filters string) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO scrape_runs (profile_id, resumed_from, strategy, start_time, status, filters, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), 'running', $4, NOW(), NOW())
+		RETURNING id`,
+		parent.ProfileID, parent.ID, parent.Strategy, nullableStringPtr(filters),
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create resumed run: %w", err)
+	}
+	return id, nil
+}
+
+// HasActiveProfileRun reports whether a run for the profile is still marked as running.
+// Runs started more than staleAfter ago are ignored so a crashed process cannot block a profile forever.
+func (s *Store) HasActiveProfileRun(ctx context.Context, profileID int64, staleAfter time.Duration) (bool, error) {
+	var active bool
+	err := s.db.GetContext(ctx, &active, `
+		SELECT EXISTS (
+			SELECT 1 FROM scrape_runs
+			WHERE profile_id = $1 AND status = 'running'
+			  AND start_time > NOW() - make_interval(secs => $2)
+		)`,
+		profileID, staleAfter.Seconds(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to check active runs: %w", err)
+	}
+	return active, nil
+}
+
 // UpdateRun updates a scrape run with final status and metrics.
-func (s *Store) UpdateRun(ctx context.Context, runID int64, status string, processed, inserted, updated, skipped, pagesScraped int, errLog string) error {
+func (s *Store) UpdateRun(ctx context.Context, runID int64, status string, counters models.RunCounters, errLog string) error {
 	var errLogPtr *string
 	if errLog != "" {
 		errLogPtr = &errLog
@@ -352,9 +794,14 @@ func (s *Store) UpdateRun(ctx context.Context, runID int64, status string, proce
 		UPDATE scrape_runs
 		SET status = $1, end_time = NOW(),
 		    jobs_processed = $2, jobs_inserted = $3, jobs_updated = $4, jobs_skipped = $5,
-		    pages_scraped = $6, error_log = $7, updated_at = NOW()
-		WHERE id = $8`,
-		status, processed, inserted, updated, skipped, pagesScraped, errLogPtr, runID,
+		    pages_scraped = $6, page_errors = $7, jobs_expired = $8,
+		    ai_jobs_queued = $9, ai_jobs_skipped = $10, ai_jobs_failed = $11,
+		    error_log = $12, updated_at = NOW()
+		WHERE id = $13`,
+		status, counters.JobsProcessed, counters.JobsInserted, counters.JobsUpdated, counters.JobsSkipped,
+		counters.PagesScraped, counters.PageErrors, counters.JobsExpired,
+		counters.AIJobsQueued, counters.AIJobsSkipped, counters.AIJobsFailed,
+		errLogPtr, runID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update run: %w", err)
@@ -362,12 +809,90 @@ func (s *Store) UpdateRun(ctx context.Context, runID int64, status string, proce
 	return nil
 }
 
+// RecordRunEvents appends events to their runs' timelines in a single insert.
+func (s *Store) RecordRunEvents(ctx context.Context, events []models.RunEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	_, err := s.db.NamedExecContext(ctx, `
+		INSERT INTO scrape_run_events (run_id, type, page, job_id, duration_ms, message, created_at)
+		VALUES (:run_id, :type, :page, :job_id, :duration_ms, :message, :created_at)`,
+		events,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record run events: %w", err)
+	}
+	return nil
+}
+
+// ListRunEvents returns up to limit events of a run in chronological order (0 = all).
+func (s *Store) ListRunEvents(ctx context.Context, runID int64, limit int) ([]models.RunEvent, error) {
+	var events []models.RunEvent
+	err := s.db.SelectContext(ctx, &events,
+		`SELECT id, run_id, type, page, job_id, duration_ms, message, created_at
+		 FROM scrape_run_events WHERE run_id = $1
+		 ORDER BY created_at, id
+		 LIMIT NULLIF($2, 0)`,
+		runID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run events: %w", err)
+	}
+	return events, nil
+}
+
+// CountRunEvents returns the number of events per type for a run, most frequent first.
+func (s *Store) CountRunEvents(ctx context.Context, runID int64) ([]models.RunEventCount, error) {
+	var counts []models.RunEventCount
+	err := s.db.SelectContext(ctx, &counts,
+		`SELECT type, COUNT(*) AS count
+		 FROM scrape_run_events WHERE run_id = $1
+		 GROUP BY type ORDER BY count DESC, type`,
+		runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count run events: %w", err)
+	}
+	return counts, nil
+}
+
+// SaveCheckpoint records the last fully processed page of a run so it can be resumed.
+func (s *Store) SaveCheckpoint(ctx context.Context, runID int64, page int, lastJobID, filtersHash string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE scrape_runs
+		SET checkpoint_page = $1, checkpoint_job_id = $2, filters_hash = $3, updated_at = NOW()
+		WHERE id = $4`,
+		page, nullableStringPtr(lastJobID), filtersHash, runID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// SaveRunShards stores the per-shard telemetry of a sharded run.
+func (s *Store) SaveRunShards(ctx context.Context, runID int64, shards []models.ScrapeShard) error {
+	data, err := json.Marshal(shards)
+	if err != nil {
+		return fmt.Errorf("failed to marshal shards: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE scrape_runs SET shards = $1, updated_at = NOW() WHERE id = $2`,
+		string(data), runID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save run shards: %w", err)
+	}
+	return nil
+}
+
 // ListRuns returns the most recent scrape runs.
 func (s *Store) ListRuns(ctx context.Context, limit int) ([]models.ScrapeRun, error) {
 	var runs []models.ScrapeRun
 	err := s.db.SelectContext(ctx, &runs,
-		`SELECT id, strategy, start_time, end_time, status, jobs_processed, 
-		        jobs_inserted, jobs_updated, jobs_skipped, pages_scraped, filters, error_log 
+		`SELECT id, profile_id, strategy, start_time, end_time, status, jobs_processed,
+		        jobs_inserted, jobs_updated, jobs_skipped, pages_scraped, page_errors, jobs_expired,
+		        ai_jobs_queued, ai_jobs_skipped, ai_jobs_failed, filters, error_log,
+		        checkpoint_page, checkpoint_job_id, filters_hash, resumed_from, shards
 		 FROM scrape_runs ORDER BY start_time DESC LIMIT $1`,
 		limit)
 	if err != nil {
@@ -380,8 +905,10 @@ func (s *Store) ListRuns(ctx context.Context, limit int) ([]models.ScrapeRun, er
 func (s *Store) GetRun(ctx context.Context, id int64) (*models.ScrapeRun, error) {
 	var run models.ScrapeRun
 	err := s.db.GetContext(ctx, &run,
-		`SELECT id, strategy, start_time, end_time, status, jobs_processed, 
-		        jobs_inserted, jobs_updated, jobs_skipped, pages_scraped, filters, error_log 
+		`SELECT id, profile_id, strategy, start_time, end_time, status, jobs_processed,
+		        jobs_inserted, jobs_updated, jobs_skipped, pages_scraped, page_errors, jobs_expired,
+		        ai_jobs_queued, ai_jobs_skipped, ai_jobs_failed, filters, error_log,
+		        checkpoint_page, checkpoint_job_id, filters_hash, resumed_from, shards
 		 FROM scrape_runs WHERE id = $1`,
 		id)
 	if err != nil {
@@ -393,8 +920,64 @@ func (s *Store) GetRun(ctx context.Context, id int64) (*models.ScrapeRun, error)
 	return &run, nil
 }
 
+// UpsertProfile creates a scrape profile or replaces the schedule and request of an existing one by name.
+func (s *Store) UpsertProfile(ctx context.Context, name, schedule, request string, enabled bool) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO scrape_profiles (name, schedule, request, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		ON CONFLICT (name) DO UPDATE SET
+			schedule = EXCLUDED.schedule,
+			request = EXCLUDED.request,
+			enabled = EXCLUDED.enabled,
+			updated_at = NOW()
+		RETURNING id`,
+		name, schedule, request, enabled,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upsert profile: %w", err)
+	}
+	return id, nil
+}
+
+// ListProfiles returns scrape profiles ordered by name, optionally only the enabled ones.
+func (s *Store) ListProfiles(ctx context.Context, enabledOnly bool) ([]models.ScrapeProfile, error) {
+	var profiles []models.ScrapeProfile
+	err := s.db.SelectContext(ctx, &profiles,
+		`SELECT id, name, schedule, request, enabled, created_at, updated_at
+		 FROM scrape_profiles WHERE enabled OR NOT $1 ORDER BY name`,
+		enabledOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// DeleteProfile removes a scrape profile by name.
+func (s *Store) DeleteProfile(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM scrape_profiles WHERE name = $1", name)
+	if err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("profile not found: %s", name)
+	}
+	return nil
+}
+
 // Helper functions
 
+// validIDs drops NULLs from a list of nullable IDs.
+func validIDs(ids []sql.NullInt64) []int64 {
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id.Valid {
+			out = append(out, id.Int64)
+		}
+	}
+	return out
+}
+
 func nullableString(s string) string {
 	return s
 }
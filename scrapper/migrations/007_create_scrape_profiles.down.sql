@@ -0,0 +1,4 @@
+-- Rollback: Drop scrape_profiles table and run linkage
+DROP INDEX IF EXISTS idx_scrape_runs_profile_status;
+ALTER TABLE scrape_runs DROP COLUMN IF EXISTS profile_id;
+DROP TABLE IF EXISTS scrape_profiles CASCADE;
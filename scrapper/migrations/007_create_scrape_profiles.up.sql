@@ -0,0 +1,25 @@
+-- Migration: Create scrape_profiles table for scheduled scraping
+-- Each profile is a named ScrapeRequest executed on a cron schedule by `scrapper serve`
+
+CREATE TABLE IF NOT EXISTS scrape_profiles (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    schedule TEXT NOT NULL,
+    request JSONB NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_profiles_enabled ON scrape_profiles(enabled);
+
+-- Link runs to the profile that triggered them (NULL for ad-hoc CLI runs)
+ALTER TABLE scrape_runs
+ADD COLUMN IF NOT EXISTS profile_id BIGINT REFERENCES scrape_profiles(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_scrape_runs_profile_status ON scrape_runs(profile_id, status);
+
+COMMENT ON TABLE scrape_profiles IS 'Named scrape configurations run on a cron schedule';
+COMMENT ON COLUMN scrape_profiles.schedule IS 'Cron expression (5 fields) or descriptor such as @every 30m';
+COMMENT ON COLUMN scrape_profiles.request IS 'JSON-encoded ScrapeRequest used for each execution';
+COMMENT ON COLUMN scrape_runs.profile_id IS 'Scrape profile that triggered this run (NULL for manual runs)';
@@ -0,0 +1,7 @@
+-- Rollback: Drop checkpoint columns from scrape_runs
+DROP INDEX IF EXISTS idx_scrape_runs_resumed_from;
+ALTER TABLE scrape_runs
+DROP COLUMN IF EXISTS resumed_from,
+DROP COLUMN IF EXISTS filters_hash,
+DROP COLUMN IF EXISTS checkpoint_job_id,
+DROP COLUMN IF EXISTS checkpoint_page;
@@ -0,0 +1,16 @@
+-- Migration: Add checkpoint columns to scrape_runs for resumable runs
+-- The runner records the last fully processed page after each page so that
+-- `scrapper resume <run_id>` can continue an interrupted run instead of starting over
+
+ALTER TABLE scrape_runs
+ADD COLUMN IF NOT EXISTS checkpoint_page INTEGER,
+ADD COLUMN IF NOT EXISTS checkpoint_job_id TEXT,
+ADD COLUMN IF NOT EXISTS filters_hash TEXT,
+ADD COLUMN IF NOT EXISTS resumed_from BIGINT REFERENCES scrape_runs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_scrape_runs_resumed_from ON scrape_runs(resumed_from) WHERE resumed_from IS NOT NULL;
+
+COMMENT ON COLUMN scrape_runs.checkpoint_page IS 'Last page whose jobs were fully processed (NULL if none)';
+COMMENT ON COLUMN scrape_runs.checkpoint_job_id IS 'Last job ID processed on checkpoint_page';
+COMMENT ON COLUMN scrape_runs.filters_hash IS 'SHA-256 of the search filters, used to verify a resume targets the same query';
+COMMENT ON COLUMN scrape_runs.resumed_from IS 'Run this run continues from (NULL for fresh runs)';
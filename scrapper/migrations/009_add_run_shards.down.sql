@@ -0,0 +1,2 @@
+-- Rollback: Drop per-shard telemetry from scrape_runs
+ALTER TABLE scrape_runs DROP COLUMN IF EXISTS shards;
@@ -0,0 +1,7 @@
+-- Migration: Add per-shard telemetry to scrape_runs
+-- Sharded runs split one request into sub-queries that fit under the job-room.ch result cap
+
+ALTER TABLE scrape_runs
+ADD COLUMN IF NOT EXISTS shards JSONB;
+
+COMMENT ON COLUMN scrape_runs.shards IS 'JSON array of per-shard metrics for sharded runs (NULL otherwise)';
@@ -0,0 +1,7 @@
+-- Rollback: Drop last-seen tracking
+ALTER TABLE scrape_runs DROP COLUMN IF EXISTS jobs_expired;
+DROP INDEX IF EXISTS idx_jobs_missed_runs;
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS missed_runs,
+DROP COLUMN IF EXISTS last_seen_run_id,
+DROP COLUMN IF EXISTS last_seen_at;
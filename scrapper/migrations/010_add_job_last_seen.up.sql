@@ -0,0 +1,18 @@
+-- Migration: Track when jobs were last listed so removed postings can be expired
+-- Every listed job is stamped with the run that saw it; complete full/sharded runs count
+-- a miss for in-scope active jobs they did not see and expire jobs after N consecutive misses
+
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS last_seen_run_id BIGINT,
+ADD COLUMN IF NOT EXISTS missed_runs INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_missed_runs ON jobs(missed_runs) WHERE status = 'active' AND missed_runs > 0;
+
+ALTER TABLE scrape_runs
+ADD COLUMN IF NOT EXISTS jobs_expired INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN jobs.last_seen_at IS 'Last time the job appeared in a job-room.ch listing';
+COMMENT ON COLUMN jobs.last_seen_run_id IS 'Scrape run that last listed the job';
+COMMENT ON COLUMN jobs.missed_runs IS 'Consecutive complete runs that did not list the job';
+COMMENT ON COLUMN scrape_runs.jobs_expired IS 'Jobs marked expired by the reconciliation pass of this run';
@@ -0,0 +1,2 @@
+-- Rollback: Drop job_revisions table
+DROP TABLE IF EXISTS job_revisions CASCADE;
@@ -0,0 +1,20 @@
+-- Migration: Create job_revisions table
+-- One row per stored update of a job whose updatedTime changed, holding a structured field diff
+
+CREATE TABLE IF NOT EXISTS job_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    previous_updated_time TIMESTAMPTZ NOT NULL,
+    updated_time TIMESTAMPTZ NOT NULL,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_revisions_job ON job_revisions(job_id, updated_time DESC);
+CREATE INDEX IF NOT EXISTS idx_job_revisions_created_at ON job_revisions(created_at DESC);
+
+-- GIN index to find revisions touching a given field
+CREATE INDEX IF NOT EXISTS idx_job_revisions_changes ON job_revisions USING GIN(changes);
+
+COMMENT ON TABLE job_revisions IS 'History of changes to scraped jobs';
+COMMENT ON COLUMN job_revisions.changes IS 'JSON array of {field, old, new} with dotted JSON paths into the job-room job';
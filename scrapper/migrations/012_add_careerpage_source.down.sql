@@ -0,0 +1,3 @@
+-- Rollback: PostgreSQL cannot drop enum values; careerpage stays valid
+-- (the up migration re-adds it idempotently), only the documentation is reverted
+COMMENT ON COLUMN jobs.source IS 'Origin of the job: jobroom (scraped) or platform (registered)';
@@ -0,0 +1,6 @@
+-- Migration: Add careerpage job source
+-- Jobs ingested from employers' schema.org JobPosting pages and RSS/Atom feeds
+
+ALTER TYPE job_source ADD VALUE IF NOT EXISTS 'careerpage';
+
+COMMENT ON COLUMN jobs.source IS 'Origin of the job: jobroom (scraped), careerpage (employer pages/feeds) or platform (registered)';
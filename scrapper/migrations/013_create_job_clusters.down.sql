@@ -0,0 +1,5 @@
+-- Rollback: Drop job_clusters table and cluster membership
+DROP VIEW IF EXISTS canonical_jobs;
+DROP INDEX IF EXISTS idx_jobs_cluster;
+ALTER TABLE jobs DROP COLUMN IF EXISTS cluster_id;
+DROP TABLE IF EXISTS job_clusters CASCADE;
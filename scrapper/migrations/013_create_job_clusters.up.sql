@@ -0,0 +1,38 @@
+-- Migration: Create job_clusters table
+-- Groups near-duplicate jobs (re-posts and the same vacancy on several sources) by fingerprint
+
+CREATE TABLE IF NOT EXISTS job_clusters (
+    id BIGSERIAL PRIMARY KEY,
+    fingerprint_key TEXT NOT NULL,
+    canonical_job_id TEXT REFERENCES jobs(id) ON DELETE SET NULL,
+    size INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_clusters_fingerprint_key ON job_clusters(fingerprint_key);
+CREATE INDEX IF NOT EXISTS idx_job_clusters_canonical ON job_clusters(canonical_job_id);
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS cluster_id BIGINT REFERENCES job_clusters(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_cluster ON jobs(cluster_id);
+
+-- One row per vacancy: the canonical job of each cluster plus jobs not clustered yet.
+-- The columns are listed because Postgres expands j.* only once, when the view is created:
+-- a migration adding columns to jobs must recreate this view with them (appended at the end,
+-- so CREATE OR REPLACE VIEW accepts it).
+CREATE OR REPLACE VIEW canonical_jobs AS
+SELECT j.id, j.source, j.created_time, j.updated_time, j.status,
+       j.source_system, j.external_ref, j.stellennummer_egov, j.fingerprint, j.reporting_obligation,
+       j.company_id, j.location_id, j.raw_data, j.external_url, j.number_of_positions,
+       j.created_at, j.updated_at, j.last_seen_at, j.last_seen_run_id, j.missed_runs, j.cluster_id
+FROM jobs j
+LEFT JOIN job_clusters c ON c.id = j.cluster_id
+WHERE c.id IS NULL OR c.canonical_job_id = j.id;
+
+COMMENT ON TABLE job_clusters IS 'Near-duplicate jobs sharing a fingerprint key and a similar description';
+COMMENT ON COLUMN job_clusters.fingerprint_key IS 'Hash of normalized company, title and location shared by all members';
+COMMENT ON COLUMN job_clusters.canonical_job_id IS 'Member shown when collapsing duplicates: active first, then job-room, then most recently updated';
+COMMENT ON COLUMN jobs.fingerprint IS 'Computed fingerprint <key>:<description simhash> used for duplicate detection';
+COMMENT ON COLUMN jobs.cluster_id IS 'Cluster of near-duplicate jobs this job belongs to';
+COMMENT ON VIEW canonical_jobs IS 'Jobs collapsed to one row per duplicate cluster, for search and matching';
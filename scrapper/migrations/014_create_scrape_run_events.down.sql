@@ -0,0 +1,7 @@
+-- Rollback: Drop scrape_run_events table and the extra run counters
+ALTER TABLE scrape_runs
+DROP COLUMN IF EXISTS ai_jobs_failed,
+DROP COLUMN IF EXISTS ai_jobs_skipped,
+DROP COLUMN IF EXISTS ai_jobs_processed,
+DROP COLUMN IF EXISTS page_errors;
+DROP TABLE IF EXISTS scrape_run_events CASCADE;
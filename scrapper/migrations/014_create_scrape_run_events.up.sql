@@ -0,0 +1,27 @@
+-- Migration: Create scrape_run_events table and persist all run counters
+-- One row per page fetch, stored job, failure and stop, replacing the error_log blob for analysis
+
+CREATE TABLE IF NOT EXISTS scrape_run_events (
+    id BIGSERIAL PRIMARY KEY,
+    run_id BIGINT NOT NULL REFERENCES scrape_runs(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    page INTEGER,
+    job_id TEXT,
+    duration_ms BIGINT,
+    message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_run_events_run ON scrape_run_events(run_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_scrape_run_events_type ON scrape_run_events(type, created_at DESC);
+
+ALTER TABLE scrape_runs
+ADD COLUMN IF NOT EXISTS page_errors INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS ai_jobs_processed INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS ai_jobs_skipped INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS ai_jobs_failed INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON TABLE scrape_run_events IS 'Timeline of scrape runs: pages, stored jobs, failures and stop reason';
+COMMENT ON COLUMN scrape_run_events.type IS 'page_fetched, page_failed, job_inserted, job_updated, detail_failed, store_failed, ai_failed or stopped';
+COMMENT ON COLUMN scrape_run_events.duration_ms IS 'Time spent on the page fetch, detail fetch and store, or the whole run for stopped';
+COMMENT ON COLUMN scrape_runs.page_errors IS 'Listing pages that could not be fetched';
@@ -0,0 +1,3 @@
+-- Rollback: Drop ai_job_queue table
+ALTER TABLE scrape_runs RENAME COLUMN ai_jobs_queued TO ai_jobs_processed;
+DROP TABLE IF EXISTS ai_job_queue CASCADE;
@@ -0,0 +1,35 @@
+-- Migration: Create ai_job_queue table
+-- Durable hand-off of stored jobs to ai_job_processing, drained by its worker with SKIP LOCKED
+
+CREATE TABLE IF NOT EXISTS ai_job_queue (
+    id BIGSERIAL PRIMARY KEY,
+    job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    mode TEXT NOT NULL CHECK (mode IN ('process', 'normalize', 'translate')),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'done', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    available_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_by TEXT,
+    last_error TEXT,
+    run_id BIGINT REFERENCES scrape_runs(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+-- A job is queued at most once per mode until it is done or dead-lettered
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ai_job_queue_active ON ai_job_queue(job_id, mode) WHERE status IN ('pending', 'processing');
+CREATE INDEX IF NOT EXISTS idx_ai_job_queue_available ON ai_job_queue(available_at, id) WHERE status IN ('pending', 'processing');
+CREATE INDEX IF NOT EXISTS idx_ai_job_queue_status ON ai_job_queue(status, updated_at);
+
+-- Scrape runs now count jobs handed to the queue instead of jobs processed inline
+ALTER TABLE scrape_runs RENAME COLUMN ai_jobs_processed TO ai_jobs_queued;
+
+COMMENT ON TABLE ai_job_queue IS 'Jobs waiting for AI normalization/translation by the ai_job_processing worker';
+COMMENT ON COLUMN ai_job_queue.mode IS 'process (normalize + translate), normalize or translate';
+COMMENT ON COLUMN ai_job_queue.status IS 'pending, processing (claimed until available_at), done or dead (dead letter after max_attempts)';
+COMMENT ON COLUMN ai_job_queue.available_at IS 'Earliest time the item may be claimed: retry backoff for pending, visibility timeout for processing';
+COMMENT ON COLUMN ai_job_queue.locked_by IS 'Worker that claimed the item last';
+COMMENT ON COLUMN scrape_runs.ai_jobs_queued IS 'Stored jobs handed to ai_job_queue';
+COMMENT ON COLUMN scrape_runs.ai_jobs_skipped IS 'Stored jobs already waiting in ai_job_queue';
+COMMENT ON COLUMN scrape_runs.ai_jobs_failed IS 'Stored jobs that could not be queued';